@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// Thresholds checked after every watch-triggered re-analysis
+var (
+	maxDirectDeps int
+	maxDepth      int
+	noNewCopyleft bool
+	webhookURLs   []string
+)
+
+type alert struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// alerter remembers which thresholds are already crossed so that a webhook
+// fires once when a threshold is crossed, not on every re-analysis.
+type alerter struct {
+	active   map[string]bool
+	copyleft map[string]bool // copyleft modules present at startup
+}
+
+func newAlerter(initial *depgraph.Graph) *alerter {
+	a := &alerter{copyleft: make(map[string]bool)}
+	for _, node := range initial.Nodes {
+		if isCopyleft(node.License) {
+			a.copyleft[node.ID] = true
+		}
+	}
+	a.active = a.violations(initial)
+	return a
+}
+
+// violations evaluates the thresholds, keyed so the same violation is
+// recognized across analyses.
+func (a *alerter) violations(graph *depgraph.Graph) map[string]bool {
+	v := make(map[string]bool)
+	if maxDirectDeps > 0 && computeMetrics(graph).DirectDeps > maxDirectDeps {
+		v["max-direct-deps"] = true
+	}
+	if maxDepth > 0 && graph.LongestChain() > maxDepth {
+		v["max-depth"] = true
+	}
+	if noNewCopyleft {
+		for _, node := range graph.Nodes {
+			if isCopyleft(node.License) && !a.copyleft[node.ID] {
+				v["no-new-copyleft:"+node.ID] = true
+			}
+		}
+	}
+	for _, node := range graph.Nodes {
+		if node.Type == alertType {
+			v["checksum-mismatch:"+node.FullPath] = true
+		}
+	}
+	return v
+}
+
+// check posts an alert for every threshold newly crossed by graph.
+func (a *alerter) check(graph *depgraph.Graph) {
+	current := a.violations(graph)
+	var alerts []alert
+	for key := range current {
+		if a.active[key] {
+			continue
+		}
+		rule, module, _ := strings.Cut(key, ":")
+		switch rule {
+		case "max-direct-deps":
+			alerts = append(alerts, alert{rule, fmt.Sprintf("%d direct dependencies exceed the limit of %d", computeMetrics(graph).DirectDeps, maxDirectDeps)})
+		case "max-depth":
+			alerts = append(alerts, alert{rule, fmt.Sprintf("longest import chain of %d exceeds the limit of %d", graph.LongestChain(), maxDepth)})
+		case "no-new-copyleft":
+			alerts = append(alerts, alert{rule, fmt.Sprintf("new copyleft dependency %s (%s)", module, graph.Node(module).License)})
+		case "checksum-mismatch":
+			alerts = append(alerts, alert{rule, fmt.Sprintf("%s in the module cache does not match go.sum", module)})
+		}
+	}
+	a.active = current
+	if len(alerts) == 0 {
+		return
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Message < alerts[j].Message })
+
+	for _, al := range alerts {
+		log.Printf("🚨 %s", al.Message)
+	}
+	for _, url := range webhookURLs {
+		if err := postAlerts(url, targetPath, alerts); err != nil {
+			log.Printf("⚠️ webhook %s: %v", url, err)
+		}
+	}
+}
+
+// postAlerts sends alerts to a webhook. The text field makes the same payload
+// work for Slack incoming webhooks and generic receivers.
+func postAlerts(url, project string, alerts []alert) error {
+	lines := []string{"🚨 go-raph thresholds crossed in " + project}
+	for _, al := range alerts {
+		lines = append(lines, "• "+al.Message)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"text":    strings.Join(lines, "\n"),
+		"project": project,
+		"alerts":  alerts,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
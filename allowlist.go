@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v3"
+
+	"go-raph/depgraph"
+)
+
+// approval is an entry of deps-allowlist.yaml, a YAML list that approve
+// only ever appends to, so its history doubles as a record of dependency
+// intake.
+type approval struct {
+	Module     string `yaml:"module"` // module path, or a prefix ending in /... such as github.com/acme/...
+	ApprovedBy string `yaml:"approved-by,omitempty"`
+	Date       string `yaml:"date,omitempty"`
+	Reason     string `yaml:"reason,omitempty"`
+}
+
+// allowlistPath returns the allowlist file, by default deps-allowlist.yaml
+// at the root of the target.
+func allowlistPath(name string) string {
+	if name == "" {
+		return filepath.Join(targetPath, "deps-allowlist.yaml")
+	}
+	return name
+}
+
+// readAllowlist reads an allowlist, which may not exist yet.
+func readAllowlist(name string) ([]approval, error) {
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var list []approval
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return list, nil
+}
+
+// approved reports whether an allowlist covers a module.
+func approved(list []approval, module string) bool {
+	for _, a := range list {
+		if prefix, ok := strings.CutSuffix(a.Module, "/..."); ok && strings.HasPrefix(module, prefix+"/") {
+			return true
+		}
+		if a.Module == module {
+			return true
+		}
+	}
+	return false
+}
+
+// unapprovedModules returns the external modules of a graph the allowlist
+// does not cover.
+func unapprovedModules(graph *depgraph.Graph, list []approval) []*depgraph.Node {
+	var unapproved []*depgraph.Node
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if isExternal(node) && !strings.HasPrefix(node.ID, "import:") && !approved(list, node.ID) {
+			unapproved = append(unapproved, node)
+		}
+	}
+	return unapproved
+}
+
+// checkCommand implements `go-raph check`, failing when the graph holds an
+// external module the allowlist does not approve.
+func checkCommand(args []string) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	allowlist := fs.String("allowlist", "", "Allowlist file (default deps-allowlist.yaml in the analyzed directory)")
+	addAnalysisFlags(fs)
+	parseFlags(fs, args)
+
+	goOffline()
+	loadPlugins()
+	resolveTarget(fs)
+	loadSavedGraph()
+
+	list, err := readAllowlist(allowlistPath(*allowlist))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		fmt.Printf(tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+
+	unapproved := unapprovedModules(graph, list)
+	if len(unapproved) == 0 {
+		fmt.Printf(tr("✅ All external modules are on %s\n"), allowlistPath(*allowlist))
+		return
+	}
+	fmt.Printf(tr("🚫 %d external modules are not on %s:\n"), len(unapproved), allowlistPath(*allowlist))
+	for _, node := range unapproved {
+		fmt.Printf("  %s %s\n", node.ID, node.Version)
+	}
+	fmt.Println(tr("\nrun `go-raph approve <module>` once a module has been reviewed"))
+	os.Exit(1)
+}
+
+// approveCommand implements `go-raph approve <module>...`, appending
+// modules to the allowlist along with who approved them and when.
+func approveCommand(args []string) {
+	fs := flag.NewFlagSet("approve", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path of the project")
+	allowlist := fs.String("allowlist", "", "Allowlist file (default deps-allowlist.yaml in the project directory)")
+	by := fs.String("by", "", "Approver (default the git user.email, or $USER)")
+	reason := fs.String("reason", "", "Why the modules are approved, e.g. a review ticket")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: go-raph approve [flags] <module>...")
+		fs.PrintDefaults()
+	}
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := allowlistPath(*allowlist)
+	list, err := readAllowlist(name)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if *by == "" {
+		out, _ := gitOutput(targetPath, "config", "user.email")
+		if *by = strings.TrimSpace(out); *by == "" {
+			*by = os.Getenv("USER")
+		}
+	}
+
+	for _, path := range fs.Args() {
+		if err := module.CheckPath(strings.TrimSuffix(path, "/...")); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var entries strings.Builder
+	for _, path := range fs.Args() {
+		if approved(list, path) {
+			fmt.Printf(tr("ℹ️ %s is already approved\n"), path)
+			continue
+		}
+		a := approval{Module: path, ApprovedBy: *by, Date: time.Now().Format(time.DateOnly), Reason: *reason}
+		list = append(list, a)
+		// Written by hand rather than re-encoding the file, so comments
+		// and earlier entries stay exactly as they were
+		fmt.Fprintf(&entries, "- module: %s\n", a.Module)
+		for _, field := range [][2]string{{"approved-by", a.ApprovedBy}, {"date", a.Date}, {"reason", a.Reason}} {
+			if field[1] != "" {
+				fmt.Fprintf(&entries, "  %s: %s\n", field[0], strconv.Quote(field[1]))
+			}
+		}
+		fmt.Printf(tr("✅ Approved %s\n"), path)
+	}
+	if entries.Len() == 0 {
+		return
+	}
+
+	head := ""
+	switch data, _ := os.ReadFile(name); {
+	case len(data) == 0:
+		head = "# External modules approved for use, appended to by `go-raph approve`\n"
+	case data[len(data)-1] != '\n':
+		head = "\n"
+	}
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(head + entries.String()); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
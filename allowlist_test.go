@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+func TestApproved(t *testing.T) {
+	list := []approval{
+		{Module: "github.com/pkg/errors"},
+		{Module: "github.com/acme/..."},
+		{Module: "golang.org/x/..."},
+	}
+	tests := []struct {
+		module string
+		want   bool
+	}{
+		{"github.com/pkg/errors", true},
+		{"github.com/pkg/errors/v2", false},
+		{"github.com/pkg/errorsx", false},
+		{"github.com/acme/lib", true},
+		{"github.com/acme/lib/v3", true},
+		{"github.com/acme", false},
+		{"github.com/acmeevil/lib", false},
+		{"golang.org/x/mod", true},
+		{"golang.org/xy/mod", false},
+	}
+	for _, test := range tests {
+		if got := approved(list, test.module); got != test.want {
+			t.Errorf("approved(%s) = %v, want %v", test.module, got, test.want)
+		}
+	}
+	if approved(nil, "github.com/pkg/errors") {
+		t.Error("an empty allowlist approves modules")
+	}
+}
+
+func TestReadAllowlist(t *testing.T) {
+	dir := t.TempDir()
+	if list, err := readAllowlist(filepath.Join(dir, "missing.yaml")); err != nil || list != nil {
+		t.Errorf("missing allowlist: %v, %v", list, err)
+	}
+
+	name := filepath.Join(dir, "deps-allowlist.yaml")
+	data := "# approved\n- module: github.com/pkg/errors\n  approved-by: \"a@example.com\"\n  reason: \"SEC-12: reviewed # twice\"\n- module: github.com/acme/...\n"
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := readAllowlist(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Reason != "SEC-12: reviewed # twice" || list[1].Module != "github.com/acme/..." {
+		t.Errorf("readAllowlist = %+v", list)
+	}
+
+	if err := os.WriteFile(name, []byte("module: not a list\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAllowlist(name); err == nil {
+		t.Error("no error for a malformed allowlist")
+	}
+}
+
+func TestUnapprovedModules(t *testing.T) {
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{
+		{ID: "example.com/app", Type: "main"},
+		{ID: "pkg:internal/auth", Type: "package"},
+		{ID: "github.com/acme/lib", Type: "external"},
+		{ID: "import:github.com/evil/lib/x", Type: "external"},
+		{ID: "github.com/evil/lib", Type: "external"},
+		{ID: "git.corp.example/tool", Type: "tooling"},
+		{ID: "git.corp.example/private", Type: privateType},
+	}}
+	unapproved := unapprovedModules(graph, []approval{{Module: "github.com/acme/..."}})
+	var ids []string
+	for _, node := range unapproved {
+		ids = append(ids, node.ID)
+	}
+	want := []string{"github.com/evil/lib", "git.corp.example/tool", "git.corp.example/private"}
+	if len(ids) != len(want) {
+		t.Fatalf("unapprovedModules = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("unapprovedModules = %v, want %v", ids, want)
+		}
+	}
+}
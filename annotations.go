@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+// badges holds the annotations external systems attached to nodes through
+// /api/annotations, per project and node, persisted like layouts.
+//
+// Graphs carry them under badgePrefix, apart from the annotations of
+// analyzers: a badge can neither replace nor delete the license or doc link
+// go-raph computes.
+var badges = struct {
+	sync.Mutex
+	loaded   bool
+	projects map[string]map[string]map[string]string
+}{}
+
+const badgePrefix = "badge."
+
+// badgeKey matches the keys badges can have, which filter terms like
+// badge.pentest:failed must be able to name.
+var badgeKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func init() {
+	depgraph.Register(annotationsAnalyzer{})
+}
+
+// annotationsAnalyzer merges the annotations posted to /api/annotations into
+// the graph, each key prefixed with badgePrefix.
+type annotationsAnalyzer struct{}
+
+func (annotationsAnalyzer) Name() string { return "annotations" }
+
+func (annotationsAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	badges.Lock()
+	defer badges.Unlock()
+	loadBadges()
+	for id, annotations := range badges.projects[projectKey()] {
+		for key, value := range annotations {
+			graph.Annotate(id, badgePrefix+key, value)
+		}
+	}
+	return nil
+}
+
+// loadBadges reads the annotations file once; callers hold the lock.
+func loadBadges() {
+	if badges.loaded {
+		return
+	}
+	badges.loaded = true
+	if err := readState("annotations.json", &badges.projects); err != nil {
+		log.Printf(tr("⚠️ Reading %s failed: %v"), "annotations.json", err)
+	}
+	if badges.projects == nil {
+		badges.projects = make(map[string]map[string]map[string]string)
+	}
+}
+
+// annotationUpdate is the body of POST /api/annotations.
+type annotationUpdate struct {
+	Node        string            `json:"node"`        // node ID
+	Annotations map[string]string `json:"annotations"` // an empty value removes the key
+}
+
+// annotationsHandler serves /api/annotations:
+//
+//	GET  /api/annotations  the project's annotations by node ID
+//	POST /api/annotations  set annotations of a node, e.g.
+//	                       {"node": "github.com/foo/bar", "annotations": {"pentest": "failed"}}
+func annotationsHandler(w http.ResponseWriter, r *http.Request) {
+	badges.Lock()
+	defer badges.Unlock()
+	loadBadges()
+	key := projectKey()
+
+	switch r.Method {
+	case http.MethodGet:
+		annotations := badges.projects[key]
+		if annotations == nil {
+			annotations = map[string]map[string]string{}
+		}
+		respondJSON(w, annotations)
+
+	case http.MethodPost:
+		// Badges are persisted, so other sites must not post them with a
+		// form or a fetch, which can only send JSON after a preflight
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin request", http.StatusForbidden)
+			return
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			http.Error(w, "annotations must be sent as application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		var update annotationUpdate
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+			http.Error(w, "invalid annotations: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if update.Node == "" || len(update.Annotations) == 0 {
+			http.Error(w, "node and annotations are required", http.StatusBadRequest)
+			return
+		}
+		// Validate every key before changing anything, so a rejected update
+		// leaves no part of itself behind
+		for k := range update.Annotations {
+			if !badgeKey.MatchString(k) {
+				http.Error(w, fmt.Sprintf("invalid annotation key %q", k), http.StatusBadRequest)
+				return
+			}
+		}
+		if badges.projects[key] == nil {
+			badges.projects[key] = make(map[string]map[string]string)
+		}
+		node := badges.projects[key][update.Node]
+		if node == nil {
+			node = make(map[string]string)
+		}
+		for k, v := range update.Annotations {
+			if v == "" {
+				delete(node, k)
+			} else {
+				node[k] = v
+			}
+		}
+		if len(node) == 0 {
+			delete(badges.projects[key], update.Node)
+		} else {
+			badges.projects[key][update.Node] = node
+		}
+		if err := writeState("annotations.json", badges.projects); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		prefixed := make(map[string]string, len(update.Annotations))
+		for k, v := range update.Annotations {
+			prefixed[badgePrefix+k] = v
+		}
+		if graph := reannotateSnapshot(update.Node, prefixed); graph != nil {
+			broadcastGraph(graph)
+		}
+		respondJSON(w, node)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
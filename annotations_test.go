@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+func TestAnnotationsHandler(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	badges.Lock()
+	badges.loaded, badges.projects = false, nil
+	badges.Unlock()
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{{ID: "github.com/foo/bar", Type: "external"}}}
+	graph.Annotate("github.com/foo/bar", "owner", "analyzer")
+	setSnapshot(graph)
+	t.Cleanup(func() { setSnapshot(nil) })
+
+	post := func(body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/api/annotations", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		annotationsHandler(w, r)
+		return w.Code
+	}
+	tests := []struct {
+		body   string
+		status int
+		want   map[string]string // annotations of the snapshot's node afterwards
+	}{
+		{`{"node": "github.com/foo/bar", "annotations": {"pentest": "failed", "owner": "platform"}}`, 200,
+			map[string]string{"owner": "analyzer", "badge.pentest": "failed", "badge.owner": "platform"}},
+		// nothing of a rejected update is applied
+		{`{"node": "github.com/foo/bar", "annotations": {"pentest": "passed", "": "x"}}`, 400,
+			map[string]string{"owner": "analyzer", "badge.pentest": "failed", "badge.owner": "platform"}},
+		{`{"node": "github.com/foo/bar", "annotations": {"pentest": "passed", "a:b": "x"}}`, 400,
+			map[string]string{"owner": "analyzer", "badge.pentest": "failed", "badge.owner": "platform"}},
+		// removing a badge leaves the analyzer's annotation of the same name
+		{`{"node": "github.com/foo/bar", "annotations": {"owner": ""}}`, 200,
+			map[string]string{"owner": "analyzer", "badge.pentest": "failed"}},
+	}
+	for _, test := range tests {
+		if status := post(test.body); status != test.status {
+			t.Errorf("POST %s: status %d, want %d", test.body, status, test.status)
+		}
+		got := getSnapshot().Node("github.com/foo/bar").Annotations
+		if len(got) != len(test.want) {
+			t.Errorf("after POST %s: annotations %v, want %v", test.body, got, test.want)
+			continue
+		}
+		for k, v := range test.want {
+			if got[k] != v {
+				t.Errorf("after POST %s: annotations %v, want %v", test.body, got, test.want)
+				break
+			}
+		}
+	}
+
+	enriched := &depgraph.Graph{Nodes: []depgraph.Node{{ID: "github.com/foo/bar", Type: "external"}}}
+	enriched.Annotate("github.com/foo/bar", "owner", "analyzer")
+	annotationsAnalyzer{}.Enrich(t.Context(), enriched)
+	if got := enriched.Node("github.com/foo/bar").Annotations; got["owner"] != "analyzer" || got["badge.pentest"] != "failed" {
+		t.Errorf("Enrich: annotations %v", got)
+	}
+}
+
+func TestAnnotationsCrossSite(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	badges.Lock()
+	badges.loaded, badges.projects = false, nil
+	badges.Unlock()
+	body := `{"node": "github.com/foo/bar", "annotations": {"pentest": "passed"}}`
+	tests := []struct {
+		contentType string
+		origin      string
+		status      int
+	}{
+		{"application/json", "", 200},
+		{"application/json; charset=utf-8", "http://example.com", 200},
+		{"application/json", "https://evil.example", 403},
+		{"text/plain", "https://evil.example", 403},
+		{"text/plain", "", 415}, // what a form or simple fetch can send
+		{"application/x-www-form-urlencoded", "", 415},
+		{"", "", 415},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/api/annotations", strings.NewReader(body))
+		r.Header.Set("Content-Type", test.contentType)
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		w := httptest.NewRecorder()
+		annotationsHandler(w, r)
+		if w.Code != test.status {
+			t.Errorf("POST as %q from %q: status %d, want %d", test.contentType, test.origin, w.Code, test.status)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// graphHandler serves the analyzed graph, optionally narrowed by a saved
+// view (?view=name) and an ad hoc filter expression (?filter=), with leaf
+// import nodes folded into their modules (?collapse=imports, the default with
+// -collapse-imports, or none) but for the modules in ?expand=, and colored by
+// a dimension (?colorBy=, defaulting to the view's color mode).
+func graphHandler(w http.ResponseWriter, r *http.Request) {
+	if graph, ok := requestedGraph(w, r); ok {
+		respondJSON(w, graph)
+	}
+}
+
+// subgraphHandler serves the neighborhood of a node (?root=) within ?depth=
+// edges (unlimited by default) in ?direction= out, in or both (default out).
+// The /api/graph parameters apply as well.
+func subgraphHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	depth := -1
+	if s := q.Get("depth"); s != "" {
+		d, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid depth", http.StatusBadRequest)
+			return
+		}
+		depth = d
+	}
+	direction := q.Get("direction")
+	if direction == "" {
+		direction = "out"
+	}
+
+	graph, ok := requestedGraph(w, r)
+	if !ok {
+		return
+	}
+	sub, err := graph.Subgraph(q.Get("root"), depth, direction)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, sub)
+}
+
+// requestedGraph applies the view, collapse, filter and color parameters of
+// the graph endpoints. When it fails, it has already written the error response.
+func requestedGraph(w http.ResponseWriter, r *http.Request) (*depgraph.Graph, bool) {
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	dimension := r.URL.Query().Get("colorBy")
+	if name := r.URL.Query().Get("view"); name != "" {
+		v, ok := getView(name)
+		if !ok {
+			http.Error(w, "view not found", http.StatusNotFound)
+			return nil, false
+		}
+		graph = applyView(graph, v)
+		if dimension == "" {
+			dimension = v.ColorMode
+		}
+	}
+	var expanded []string
+	for _, modules := range r.URL.Query()["expand"] {
+		expanded = append(expanded, strings.Split(modules, ",")...)
+	}
+	switch r.URL.Query().Get("collapse") {
+	case "":
+		if collapseImports {
+			graph = collapseLeafImports(graph, expanded)
+		}
+	case "imports":
+		graph = collapseLeafImports(graph, expanded)
+	case "none":
+	default:
+		http.Error(w, "collapse must be imports or none", http.StatusBadRequest)
+		return nil, false
+	}
+	if dimension != "" {
+		if err := applyColors(graph, dimension); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return filterGraph(graph, r.URL.Query().Get("filter")), true
+}
+
+func respondJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
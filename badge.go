@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+)
+
+// badgeHandler serves shields-style SVG badges for the analyzed project:
+// /badge/deps.svg (external module count) and /badge/cycles.svg.
+func badgeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/badge/deps.svg" && r.URL.Path != "/badge/cycles.svg" {
+		http.NotFound(w, r)
+		return
+	}
+
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		writeBadge(w, "go-raph", "error", "#e05d44")
+		return
+	}
+
+	if r.URL.Path == "/badge/deps.svg" {
+		writeBadge(w, "dependencies", strconv.Itoa(computeMetrics(graph).Modules), "#007ec6")
+	} else {
+		cycles := len(graph.Cycles())
+		color := "#4c1"
+		if cycles > 0 {
+			color = "#e05d44"
+		}
+		writeBadge(w, "import cycles", strconv.Itoa(cycles), color)
+	}
+}
+
+func writeBadge(w http.ResponseWriter, label, value, color string) {
+	// Rough Verdana 11px metrics, good enough for short labels
+	lw := 6*len(label) + 10
+	vw := 7*len(value) + 10
+	w.Header().Set("Content-Type", "image/svg+xml")
+	// Badges are embedded through caching proxies (e.g. GitHub's camo)
+	w.Header().Set("Cache-Control", "no-cache, max-age=0")
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<rect width="%[2]d" height="20" fill="#555"/>
+<rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, lw+vw, lw, vw, html.EscapeString(label), html.EscapeString(value), color, lw/2, lw+vw/2)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// moduleCategories groups modules doing the same job. A module path also
+// covers its major versions, e.g. github.com/go-chi/chi covers
+// github.com/go-chi/chi/v5.
+var moduleCategories = map[string][]string{
+	"JSON": {
+		"github.com/json-iterator/go", "github.com/goccy/go-json", "github.com/bytedance/sonic",
+		"github.com/mailru/easyjson", "github.com/pquerna/ffjson", "github.com/segmentio/encoding",
+		"github.com/valyala/fastjson", "github.com/buger/jsonparser", "github.com/tidwall/gjson",
+		"github.com/go-json-experiment/json",
+	},
+	"YAML": {
+		"gopkg.in/yaml.v2", "gopkg.in/yaml.v3", "sigs.k8s.io/yaml", "github.com/goccy/go-yaml",
+		"github.com/ghodss/yaml",
+	},
+	"TOML":        {"github.com/BurntSushi/toml", "github.com/pelletier/go-toml"},
+	"MessagePack": {"github.com/vmihailenco/msgpack", "github.com/ugorji/go/codec", "github.com/tinylib/msgp", "github.com/shamaton/msgpack"},
+	"logging": {
+		"github.com/sirupsen/logrus", "go.uber.org/zap", "github.com/rs/zerolog", "github.com/go-kit/log",
+		"github.com/apex/log", "github.com/inconshreveable/log15", "github.com/golang/glog", "k8s.io/klog",
+		"github.com/hashicorp/go-hclog", "github.com/charmbracelet/log", "github.com/phuslu/log",
+	},
+	"HTTP router": {
+		"github.com/gorilla/mux", "github.com/go-chi/chi", "github.com/julienschmidt/httprouter",
+		"github.com/gin-gonic/gin", "github.com/labstack/echo", "github.com/gofiber/fiber",
+		"github.com/beego/beego", "github.com/go-martini/martini", "github.com/bmizerany/pat",
+		"github.com/dimfeld/httptreemux", "github.com/uptrace/bunrouter",
+	},
+	"WebSocket": {"github.com/gorilla/websocket", "nhooyr.io/websocket", "github.com/coder/websocket", "github.com/gobwas/ws"},
+	"UUID": {
+		"github.com/google/uuid", "github.com/gofrs/uuid", "github.com/satori/go.uuid", "github.com/pborman/uuid",
+		"github.com/rs/xid", "github.com/oklog/ulid", "github.com/segmentio/ksuid", "github.com/lithammer/shortuuid",
+	},
+	"errors": {
+		"github.com/pkg/errors", "github.com/cockroachdb/errors", "github.com/go-errors/errors", "emperror.dev/errors",
+		"github.com/hashicorp/go-multierror", "go.uber.org/multierr",
+	},
+	"CLI": {
+		"github.com/spf13/cobra", "github.com/urfave/cli", "github.com/alecthomas/kingpin", "github.com/alecthomas/kong",
+		"github.com/jessevdk/go-flags", "github.com/peterbourgon/ff",
+	},
+	"configuration": {
+		"github.com/spf13/viper", "github.com/kelseyhightower/envconfig", "github.com/caarlos0/env",
+		"github.com/joho/godotenv", "github.com/knadh/koanf", "github.com/ilyakaznacheev/cleanenv",
+	},
+	"assertions": {
+		"github.com/stretchr/testify", "github.com/onsi/gomega", "gotest.tools", "github.com/matryer/is",
+		"github.com/frankban/quicktest",
+	},
+	"mocking":         {"github.com/golang/mock", "go.uber.org/mock"},
+	"PostgreSQL":      {"github.com/lib/pq", "github.com/jackc/pgx"},
+	"Redis":           {"github.com/go-redis/redis", "github.com/redis/go-redis", "github.com/gomodule/redigo"},
+	"decimal numbers": {"github.com/shopspring/decimal", "github.com/ericlagergren/decimal", "github.com/cockroachdb/apd"},
+}
+
+// loadCategories adds the module categories of a JSON file, an object of
+// category names to module paths, to the built-in ones. -categories calls
+// it.
+func loadCategories(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var categories map[string][]string
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	for category, modules := range categories {
+		moduleCategories[category] = append(moduleCategories[category], modules...)
+	}
+	return nil
+}
+
+// moduleCategory returns the category of a module, or "".
+func moduleCategory(module string) string {
+	category, longest := "", 0
+	for c, modules := range moduleCategories {
+		for _, m := range modules {
+			if (module == m || strings.HasPrefix(module, m+"/")) && len(m) > longest {
+				category, longest = c, len(m)
+			}
+		}
+	}
+	return category
+}
+
+func init() {
+	depgraph.Register(categoriesAnalyzer{})
+}
+
+// categoriesAnalyzer annotates known external modules with their category
+// and, when the graph holds several modules of one category, with the
+// others as "duplicates".
+type categoriesAnalyzer struct{}
+
+func (categoriesAnalyzer) Name() string { return "categories" }
+
+func (categoriesAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	for _, dup := range duplicateCategories(graph) {
+		for _, m := range dup.Modules {
+			var others []string
+			for _, other := range dup.Modules {
+				if other.Module != m.Module {
+					others = append(others, other.Module)
+				}
+			}
+			graph.Annotate(m.Module, "duplicates", strings.Join(others, ", "))
+		}
+	}
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if category := moduleCategory(node.ID); category != "" && isRuntimeModule(node) {
+			graph.Annotate(node.ID, "category", category)
+		}
+	}
+	return nil
+}
+
+// isRuntimeModule reports whether a node is an external module the project
+// imports outside of tooling.
+func isRuntimeModule(node *depgraph.Node) bool {
+	return (node.Type == "external" || node.Type == privateType) && !strings.HasPrefix(node.ID, "import:")
+}
+
+// duplicateCategory is a category with several modules in the graph.
+type duplicateCategory struct {
+	Category string           `json:"category"`
+	Modules  []categoryModule `json:"modules"` // most imported first
+	Keep     string           `json:"keep"`    // suggested module to consolidate on
+}
+
+type categoryModule struct {
+	Module    string   `json:"module"`
+	Version   string   `json:"version,omitempty"`
+	Importers []string `json:"importers"` // packages importing it
+}
+
+// duplicateCategories lists the categories the graph has more than one
+// module of, by name.
+func duplicateCategories(graph *depgraph.Graph) []duplicateCategory {
+	importers, _ := moduleImporters(graph)
+	byCategory := make(map[string][]categoryModule)
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if category := moduleCategory(node.ID); category != "" && isRuntimeModule(node) {
+			users := importers[node.ID]
+			if users == nil {
+				users = []string{}
+			}
+			byCategory[category] = append(byCategory[category], categoryModule{node.ID, node.Version, users})
+		}
+	}
+	var dups []duplicateCategory
+	for _, category := range sortedSet(byCategory) {
+		modules := byCategory[category]
+		if len(modules) < 2 {
+			continue
+		}
+		sort.SliceStable(modules, func(i, j int) bool {
+			if len(modules[i].Importers) != len(modules[j].Importers) {
+				return len(modules[i].Importers) > len(modules[j].Importers)
+			}
+			return modules[i].Module < modules[j].Module
+		})
+		dups = append(dups, duplicateCategory{category, modules, modules[0].Module})
+	}
+	return dups
+}
+
+// writeDuplicatesMarkdown writes the consolidation report.
+func writeDuplicatesMarkdown(w io.Writer, graph *depgraph.Graph) error {
+	dups := duplicateCategories(graph)
+	fmt.Fprintf(w, "## go-raph duplicate dependencies\n\n")
+	if len(dups) == 0 {
+		fmt.Fprintf(w, "No two modules do the same job.\n")
+		return nil
+	}
+	fmt.Fprintf(w, "Modules doing the same job; consolidating on the most imported one shrinks the dependency tree.\n\n")
+	for _, dup := range dups {
+		fmt.Fprintf(w, "### %s\n\n", dup.Category)
+		for _, m := range dup.Modules {
+			fmt.Fprintf(w, "- `%s` %s imported by %d packages\n", m.Module, m.Version, len(m.Importers))
+		}
+		fmt.Fprintf(w, "\nconsolidate on `%s`\n\n", dup.Keep)
+	}
+	return nil
+}
+
+// writeDuplicatesJSON writes the consolidation report as JSON.
+func writeDuplicatesJSON(w io.Writer, graph *depgraph.Graph) error {
+	dups := duplicateCategories(graph)
+	if dups == nil {
+		dups = []duplicateCategory{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dups)
+}
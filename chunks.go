@@ -0,0 +1,66 @@
+package main
+
+import "go-raph/depgraph"
+
+// chunkSize is the number of nodes and edges sent per WebSocket frame once a
+// graph is too large to send in one message.
+var chunkSize = 10000
+
+// graphChunk carries part of a graph's nodes and edges. Chunks are numbered
+// from 1 and followed by a commit message with the remaining graph fields.
+type graphChunk struct {
+	Chunk int             `json:"chunk"`
+	Of    int             `json:"of"`
+	Nodes []depgraph.Node `json:"nodes,omitempty"`
+	Edges []depgraph.Edge `json:"edges,omitempty"`
+}
+
+// graphMessages returns the messages that transfer graph to a client: a
+// single {"graph": ...} for ordinary graphs, chunks and a commit otherwise.
+// With -collapse-imports leaf import nodes are folded into their modules;
+// viewers expand modules through /api/graph.
+func graphMessages(graph *depgraph.Graph) []interface{} {
+	if collapseImports {
+		graph = collapseLeafImports(graph, nil)
+	}
+	total := len(graph.Nodes) + len(graph.Edges)
+	if chunkSize <= 0 || total <= chunkSize {
+		return []interface{}{map[string]interface{}{"graph": graph}}
+	}
+
+	of := (total + chunkSize - 1) / chunkSize
+	var msgs []interface{}
+	nodes, edges := graph.Nodes, graph.Edges
+	for i := 1; i <= of; i++ {
+		chunk := graphChunk{Chunk: i, Of: of}
+		n := min(chunkSize, len(nodes))
+		chunk.Nodes, nodes = nodes[:n], nodes[n:]
+		e := min(chunkSize-n, len(edges))
+		chunk.Edges, edges = edges[:e], edges[e:]
+		msgs = append(msgs, chunk)
+	}
+	commit := *graph
+	commit.Nodes, commit.Edges = nil, nil
+	return append(msgs, map[string]interface{}{"commit": commit})
+}
+
+func sendGraph(c *client, graph *depgraph.Graph) {
+	c.sendAll(graphMessages(graph))
+}
+
+func broadcastGraph(graph *depgraph.Graph) {
+	msgs := graphMessages(graph)
+	var hot []depgraph.HotEdge // counted once the first subscriber needs them
+	hub.Lock()
+	defer hub.Unlock()
+	for c := range hub.clients {
+		c.mu.Lock()
+		msg, ok := c.hotPathMessage(&hot, graph)
+		c.mu.Unlock()
+		if ok {
+			c.sendAll(append(msgs[:len(msgs):len(msgs)], msg))
+		} else {
+			c.sendAll(msgs)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(clusterAnalyzer{})
+}
+
+// clusterAnalyzer annotates packages with the community they belong to in
+// the import graph, hinting at natural module boundaries.
+type clusterAnalyzer struct{}
+
+func (clusterAnalyzer) Name() string { return "clusters" }
+
+func (clusterAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	for id, community := range graph.Communities() {
+		graph.Annotate(id, "cluster", strconv.Itoa(community))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go-raph/depgraph"
+)
+
+// colorBy is the default dimension nodes are colored by.
+var colorBy = "type"
+
+// typeColors match the front-end's built-in palette; the theme may
+// override them.
+var typeColors = map[string]string{
+	"main":              "rgba(255, 100, 100, 1)",
+	"package":           "rgba(100, 150, 255, 1)",
+	"file":              "rgba(170, 200, 255, 1)",
+	"external":          "rgba(255, 200, 100, 1)",
+	"internal-external": "rgba(255, 170, 130, 1)",
+	"asset":             "rgba(190, 120, 255, 1)",
+	"proto":             "rgba(120, 220, 140, 1)",
+	"image":             "rgba(100, 220, 220, 1)",
+	"artifact":          "rgba(200, 200, 120, 1)",
+	"service":           "rgba(255, 140, 200, 1)",
+	"tool":              "rgba(160, 160, 255, 1)",
+	"tooling":           "rgba(180, 140, 100, 1)",
+	"security-alert":    "rgba(255, 40, 40, 1)",
+}
+
+// categoricalPalette colors distinct values in sorted order.
+var categoricalPalette = []string{
+	"rgba(100, 150, 255, 1)", "rgba(255, 200, 100, 1)", "rgba(120, 220, 140, 1)",
+	"rgba(255, 100, 100, 1)", "rgba(190, 120, 255, 1)", "rgba(100, 220, 220, 1)",
+	"rgba(255, 140, 200, 1)", "rgba(200, 200, 120, 1)", "rgba(160, 160, 255, 1)",
+	"rgba(255, 170, 130, 1)",
+}
+
+// noValueColor is used for nodes the dimension says nothing about.
+const noValueColor = "rgba(150, 150, 150, 1)"
+
+// colorDimensions lists the supported -color-by values. Categorical
+// dimensions return a label, continuous ones a number; ok is false for
+// nodes without a value.
+var colorDimensions = map[string]struct {
+	categorical func(node *depgraph.Node) (string, bool)
+	continuous  func(node *depgraph.Node) (float64, bool)
+	unit        func(v float64) string
+}{
+	"type": {categorical: func(n *depgraph.Node) (string, bool) { return n.Type, true }},
+	"owner": {categorical: func(n *depgraph.Node) (string, bool) {
+		owner := n.Annotations["owner"]
+		return owner, owner != ""
+	}},
+	"cluster": {categorical: func(n *depgraph.Node) (string, bool) {
+		cluster := n.Annotations["cluster"]
+		return "cluster " + cluster, cluster != ""
+	}},
+	"license": {categorical: func(n *depgraph.Node) (string, bool) { return n.License, n.License != "" }},
+	"sumdb": {categorical: func(n *depgraph.Node) (string, bool) {
+		status, _, _ := strings.Cut(n.Annotations["sumdb"], ":")
+		return status, status != ""
+	}},
+	"staleness": {
+		continuous: func(n *depgraph.Node) (float64, bool) {
+			if n.Version == "" {
+				return 0, false
+			}
+			published, ok := moduleTime(n.ID, n.Version)
+			return time.Since(published).Hours() / 24, ok
+		},
+		unit: func(days float64) string { return fmt.Sprintf("%.0f days old", days) },
+	},
+	"size": {
+		continuous: func(n *depgraph.Node) (float64, bool) { return float64(n.Size), n.Size > 0 },
+		unit:       func(b float64) string { return depgraph.FormatBytes(int64(b)) },
+	},
+}
+
+// applyColors sets the Color of every node for the given dimension and
+// describes the assignment in the graph's legend.
+func applyColors(graph *depgraph.Graph, dimension string) error {
+	dim, ok := colorDimensions[dimension]
+	if !ok {
+		return fmt.Errorf("unknown color dimension %q", dimension)
+	}
+	graph.ColorBy = dimension
+	graph.Legend = nil
+
+	if dim.categorical != nil {
+		values := make(map[string]bool)
+		for i := range graph.Nodes {
+			if v, ok := dim.categorical(&graph.Nodes[i]); ok {
+				values[v] = true
+			}
+		}
+		var sorted []string
+		for v := range values {
+			sorted = append(sorted, v)
+		}
+		sort.Strings(sorted)
+		colors := make(map[string]string)
+		var nodeColors map[string]string
+		if dimension == "type" {
+			nodeColors = currentTheme().NodeColors
+		}
+		for i, v := range sorted {
+			colors[v] = categoricalPalette[i%len(categoricalPalette)]
+			if nodeColors[v] != "" {
+				colors[v] = nodeColors[v]
+			}
+			graph.Legend = append(graph.Legend, depgraph.LegendEntry{Label: v, Color: colors[v]})
+		}
+		for i := range graph.Nodes {
+			graph.Nodes[i].Color = noValueColor
+			if v, ok := dim.categorical(&graph.Nodes[i]); ok {
+				graph.Nodes[i].Color = colors[v]
+			}
+		}
+		return nil
+	}
+
+	// Continuous dimensions are log-scaled from green (low) to red (high)
+	values := make(map[int]float64)
+	low, high := math.Inf(1), math.Inf(-1)
+	for i := range graph.Nodes {
+		if v, ok := dim.continuous(&graph.Nodes[i]); ok {
+			values[i] = v
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+	for i := range graph.Nodes {
+		graph.Nodes[i].Color = noValueColor
+		if v, ok := values[i]; ok {
+			graph.Nodes[i].Color = gradient(scale(v, low, high))
+		}
+	}
+	if len(values) > 0 {
+		graph.Legend = []depgraph.LegendEntry{
+			{Label: dim.unit(low), Color: gradient(0)},
+			{Label: dim.unit(high), Color: gradient(1)},
+		}
+	}
+	return nil
+}
+
+func scale(v, low, high float64) float64 {
+	if high <= low {
+		return 0
+	}
+	return math.Log1p(v-low) / math.Log1p(high-low)
+}
+
+// gradient maps t in [0, 1] from green through yellow to red.
+func gradient(t float64) string {
+	r, g := 255.0, 255.0
+	if t < 0.5 {
+		r = 100 + 155*t*2
+	} else {
+		g = 255 - 155*(t-0.5)*2
+	}
+	return fmt.Sprintf("rgba(%d, %d, 100, 1)", int(r), int(g))
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+
+	"go-raph/depgraph"
+)
+
+// neo4jURL is the bolt URL of a Neo4j server to push the graph to instead
+// of serving it.
+var neo4jURL string
+
+// cypherNode and cypherEdge are a graph in Neo4j's model. Nodes are keyed
+// so that graphs of many projects share their external modules while their
+// packages stay apart, and every relationship carries its project so a
+// project's graph can be replaced.
+type cypherNode struct {
+	Key   string
+	Label string // from the node type, e.g. InternalExternal
+	Props map[string]interface{}
+}
+
+type cypherEdge struct {
+	From, To string
+	Type     string // from the edge kind, IMPORTS for imports
+	Props    map[string]interface{}
+}
+
+func cypherGraph(graph *depgraph.Graph) (project string, nodes []cypherNode, edges []cypherEdge) {
+	project = graphProject(graph)
+	keys := make(map[string]string)
+	for i := range graph.Nodes {
+		n := &graph.Nodes[i]
+		key := n.ID
+		if !isExternal(n) && n.Type != "main" {
+			key = project + " " + n.ID
+		}
+		keys[n.ID] = key
+		props := map[string]interface{}{"key": key, "id": n.ID, "label": n.Label, "type": n.Type}
+		if !isExternal(n) {
+			props["project"] = project
+		}
+		for name, value := range map[string]string{"version": n.Version, "license": n.License} {
+			if value != "" {
+				props[name] = value
+			}
+		}
+		if n.Size > 0 {
+			props["size"] = n.Size
+		}
+		for name, value := range n.Annotations {
+			props["annotation."+name] = value
+		}
+		nodes = append(nodes, cypherNode{Key: key, Label: cypherName(n.Type, false), Props: props})
+	}
+	for _, e := range graph.Edges {
+		if keys[e.Source] == "" || keys[e.Target] == "" {
+			continue
+		}
+		kind := e.Kind
+		if kind == "" {
+			kind = "imports"
+		}
+		props := map[string]interface{}{"project": project}
+		if len(e.Symbols) > 0 {
+			symbols := make([]interface{}, len(e.Symbols))
+			for i, s := range e.Symbols {
+				symbols[i] = s
+			}
+			props["symbols"] = symbols
+		}
+		edges = append(edges, cypherEdge{From: keys[e.Source], To: keys[e.Target], Type: cypherName(kind, true), Props: props})
+	}
+	return project, nodes, edges
+}
+
+// cypherName turns a node type into a label (internal-external becomes
+// InternalExternal) or an edge kind into a relationship type (PROTO_IMPORT).
+func cypherName(s string, relationship bool) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' || r == ' ' })
+	for i, p := range parts {
+		if relationship {
+			parts[i] = strings.ToUpper(p)
+		} else {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	if relationship {
+		return strings.Join(parts, "_")
+	}
+	return strings.Join(parts, "")
+}
+
+const (
+	cypherConstraint  = "CREATE CONSTRAINT goraph_key IF NOT EXISTS FOR (n:GoNode) REQUIRE n.key IS UNIQUE"
+	cypherDeleteEdges = "MATCH (:GoNode)-[r {project: $project}]->(:GoNode) DELETE r"
+)
+
+// writeCypher writes Cypher statements merging the graph into a Neo4j
+// database, for cypher-shell. The project's previous relationships are
+// deleted first, so rerunning the statements replaces its graph.
+func writeCypher(w io.Writer, graph *depgraph.Graph) error {
+	project, nodes, edges := cypherGraph(graph)
+	var b strings.Builder
+	if p := graph.Provenance; p != nil {
+		fmt.Fprintf(&b, "// %s, analyzed %s", p.Tool, p.AnalyzedAt.Format(time.RFC3339))
+		if p.ModuleVersion != "" {
+			fmt.Fprintf(&b, " at %s", p.ModuleVersion)
+		}
+		if len(p.Flags) > 0 {
+			fmt.Fprintf(&b, " with %s", strings.Join(p.Flags, " "))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(cypherConstraint + ";\n")
+	b.WriteString(strings.Replace(cypherDeleteEdges, "$project", cypherLiteral(project), 1) + ";\n")
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "MERGE (n:GoNode {key: %s}) SET n += %s, n:`%s`;\n", cypherLiteral(n.Key), cypherLiteral(n.Props), n.Label)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "MATCH (a:GoNode {key: %s}), (b:GoNode {key: %s}) MERGE (a)-[r:`%s` {project: %s}]->(b) SET r += %s;\n",
+			cypherLiteral(e.From), cypherLiteral(e.To), e.Type, cypherLiteral(project), cypherLiteral(e.Props))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// cypherLiteral writes a string, number, list or map as a Cypher literal.
+func cypherLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`).Replace(v) + "'"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = cypherLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = "`" + strings.ReplaceAll(k, "`", "``") + "`: " + cypherLiteral(v[k])
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	panic(fmt.Sprintf("no Cypher literal for %T", v))
+}
+
+// uploadGraph analyzes the target once and merges it into the Neo4j server
+// at neo4jURL instead of starting the server.
+func uploadGraph() {
+	ctx := context.Background()
+	graph, err := currentGraph(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+	if structuredIDs {
+		graph = graph.StructuredIDs()
+	}
+	if err := pushNeo4j(ctx, graph); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", neo4jURL, err)
+		os.Exit(1)
+	}
+	fmt.Printf(tr("🕸️ Merged %d nodes and %d edges into %s\n"), len(graph.Nodes), len(graph.Edges), neo4jURL)
+}
+
+// pushNeo4j merges the graph into the Neo4j server at neo4jURL over bolt,
+// authenticating with NEO4J_USERNAME and NEO4J_PASSWORD when set.
+func pushNeo4j(ctx context.Context, graph *depgraph.Graph) error {
+	auth := neo4j.NoAuth()
+	if user := os.Getenv("NEO4J_USERNAME"); user != "" {
+		auth = neo4j.BasicAuth(user, os.Getenv("NEO4J_PASSWORD"), "")
+	}
+	driver, err := neo4j.NewDriverWithContext(neo4jURL, auth)
+	if err != nil {
+		return err
+	}
+	defer driver.Close(ctx)
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	// Schema changes cannot share a transaction with writes
+	if _, err := session.Run(ctx, cypherConstraint, nil); err != nil {
+		return err
+	}
+	project, nodes, edges := cypherGraph(graph)
+	nodeRows := make(map[string][]interface{})
+	for _, n := range nodes {
+		nodeRows[n.Label] = append(nodeRows[n.Label], map[string]interface{}{"key": n.Key, "props": n.Props})
+	}
+	edgeRows := make(map[string][]interface{})
+	for _, e := range edges {
+		edgeRows[e.Type] = append(edgeRows[e.Type], map[string]interface{}{"from": e.From, "to": e.To, "props": e.Props})
+	}
+
+	_, err = session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (interface{}, error) {
+		if _, err := tx.Run(ctx, cypherDeleteEdges, map[string]interface{}{"project": project}); err != nil {
+			return nil, err
+		}
+		// Labels and relationship types cannot be parameters
+		for label, rows := range nodeRows {
+			query := "UNWIND $rows AS row MERGE (n:GoNode {key: row.key}) SET n += row.props, n:`" + label + "`"
+			if _, err := tx.Run(ctx, query, map[string]interface{}{"rows": rows}); err != nil {
+				return nil, err
+			}
+		}
+		for typ, rows := range edgeRows {
+			query := "UNWIND $rows AS row MATCH (a:GoNode {key: row.from}), (b:GoNode {key: row.to}) " +
+				"MERGE (a)-[r:`" + typ + "` {project: $project}]->(b) SET r += row.props"
+			if _, err := tx.Run(ctx, query, map[string]interface{}{"rows": rows, "project": project}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
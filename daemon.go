@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// daemonRegistry is the state file listing the projects `go-raph daemon`
+// keeps fresh graphs of.
+const daemonRegistry = "daemon.json"
+
+// daemonProject is a registered project: the daemon runs a watch mode
+// server for it and serves it at /p/{name}/.
+type daemonProject struct {
+	Name  string    `json:"name"`
+	Path  string    `json:"path"`
+	Flags []string  `json:"flags,omitempty"` // analysis flags of its server, e.g. -generics
+	Added time.Time `json:"added"`
+}
+
+// projectName matches the names projects can be served under.
+var projectName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// readRegistry reads the registered projects. As the registry is a file
+// anyone can edit, it skips projects `daemon add` would have refused: their
+// names end up in socket paths and URLs, and their paths on command lines.
+func readRegistry() ([]daemonProject, error) {
+	var projects []daemonProject
+	if err := readState(daemonRegistry, &projects); err != nil {
+		return nil, err
+	}
+	valid := projects[:0]
+	for _, p := range projects {
+		if !projectName.MatchString(p.Name) || !filepath.IsAbs(p.Path) ||
+			slices.ContainsFunc(valid, func(v daemonProject) bool { return v.Name == p.Name }) {
+			log.Printf(tr("⚠️ Skipping invalid registered project '%s' (%s)"), p.Name, p.Path)
+			continue
+		}
+		valid = append(valid, p)
+	}
+	return valid, nil
+}
+
+// daemonCommand implements `go-raph daemon`: a long-running service with
+// always fresh graphs of every registered project, and the commands
+// managing its registry.
+func daemonCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("usage: go-raph daemon run|add|remove|list|unit [flags]")
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "run":
+		daemonRun(args[1:])
+	case "add":
+		daemonAdd(args[1:])
+	case "remove":
+		daemonRemove(args[1:])
+	case "list":
+		daemonList(args[1:])
+	case "unit":
+		daemonUnit(args[1:])
+	default:
+		fmt.Printf(tr("❌ Unknown daemon command '%s'\n"), args[0])
+		os.Exit(2)
+	}
+}
+
+// daemonAdd implements `go-raph daemon add [-name n] <path> [flags]`; the
+// flags after the path are analysis flags of the project's server.
+func daemonAdd(args []string) {
+	fs := flag.NewFlagSet("daemon add", flag.ExitOnError)
+	name := fs.String("name", "", "Name the project is served under (default the directory name)")
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	if fs.NArg() == 0 {
+		fmt.Println("usage: go-raph daemon add [-name name] <path> [analysis flags]")
+		os.Exit(2)
+	}
+	path, err := filepath.Abs(fs.Arg(0))
+	if err == nil {
+		_, err = os.Stat(path)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	projects, err := readRegistry()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	for _, p := range projects {
+		if p.Path == path {
+			fmt.Printf(tr("ℹ️ %s is already registered as %s\n"), path, p.Name)
+			return
+		}
+	}
+	if *name == "" {
+		*name = uniqueProjectName(projects, filepath.Base(path))
+	} else if !projectName.MatchString(*name) {
+		fmt.Printf(tr("❌ Invalid project name '%s'\n"), *name)
+		os.Exit(1)
+	} else if slices.ContainsFunc(projects, func(p daemonProject) bool { return p.Name == *name }) {
+		fmt.Printf(tr("❌ A project named '%s' is already registered\n"), *name)
+		os.Exit(1)
+	}
+	projects = append(projects, daemonProject{Name: *name, Path: path, Flags: fs.Args()[1:], Added: time.Now().UTC()})
+	if err := writeState(daemonRegistry, projects); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf(tr("✅ Registered %s as %s\n"), path, *name)
+}
+
+// uniqueProjectName derives a URL-safe project name from a directory name,
+// numbering it when another project has it.
+func uniqueProjectName(projects []daemonProject, base string) string {
+	base = strings.Trim(unsafePathChars.ReplaceAllString(base, "-"), "-.")
+	if base == "" {
+		base = "project"
+	}
+	name := base
+	for i := 2; slices.ContainsFunc(projects, func(p daemonProject) bool { return p.Name == name }); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
+
+// daemonRemove implements `go-raph daemon remove <name or path>`.
+func daemonRemove(args []string) {
+	fs := flag.NewFlagSet("daemon remove", flag.ExitOnError)
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fmt.Println("usage: go-raph daemon remove <name or path>")
+		os.Exit(2)
+	}
+	projects, err := readRegistry()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	path, _ := filepath.Abs(fs.Arg(0))
+	kept := slices.DeleteFunc(slices.Clone(projects), func(p daemonProject) bool {
+		return p.Name == fs.Arg(0) || p.Path == path
+	})
+	if len(kept) == len(projects) {
+		fmt.Printf(tr("❌ No registered project '%s'\n"), fs.Arg(0))
+		os.Exit(1)
+	}
+	if err := writeState(daemonRegistry, kept); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf(tr("✅ Removed %s\n"), fs.Arg(0))
+}
+
+// daemonList implements `go-raph daemon list`.
+func daemonList(args []string) {
+	fs := flag.NewFlagSet("daemon list", flag.ExitOnError)
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	projects, err := readRegistry()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(projects) == 0 {
+		fmt.Println(tr("ℹ️ No projects registered, add one with `go-raph daemon add <path>`"))
+		return
+	}
+	for _, p := range projects {
+		fmt.Printf("%-20s %s %s\n", p.Name, p.Path, strings.Join(p.Flags, " "))
+	}
+}
+
+// daemonUnit implements `go-raph daemon unit`, printing a systemd user unit
+// running the daemon.
+func daemonUnit(args []string) {
+	fs := flag.NewFlagSet("daemon unit", flag.ExitOnError)
+	port := fs.String("port", "8090", "Port the daemon serves on")
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf(`[Unit]
+Description=go-raph dependency graphs of local projects
+
+[Service]
+ExecStart=%s daemon run -port %s
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`, exe, *port)
+}
+
+// daemonChild is the watch mode server of a registered project, serving on
+// a unix socket the daemon proxies to.
+type daemonChild struct {
+	project daemonProject
+	socket  string
+	proxy   *httputil.ReverseProxy
+	stop    context.CancelFunc
+
+	mu     sync.Mutex
+	status string // starting, running or restarting
+	err    string // why it last exited
+}
+
+// daemon supervises the servers of the registered projects.
+type daemon struct {
+	mu       sync.Mutex
+	children map[string]*daemonChild
+	flags    []string // analysis flags of every project's server
+}
+
+// daemonRun implements `go-raph daemon run`, the service itself: it runs a
+// watch mode server per registered project, follows changes to the
+// registry and serves the projects under one port. As the projects' servers
+// edit go.mod files and open editors, it serves only localhost unless
+// -listen names another address.
+func daemonRun(args []string) {
+	fs := flag.NewFlagSet("daemon run", flag.ExitOnError)
+	port := fs.String("port", "8090", "Server port, on localhost")
+	fs.StringVar(&listenAddr, "listen", "", "Listen on this address instead of localhost:port: host:port, :port for every interface, or unix:/path/to/socket behind a reverse proxy")
+	fs.Func("base-path", "Serve every route under this path, e.g. /raph/ behind a reverse proxy (default /)", setBasePath)
+	poll := fs.Duration("poll", 2*time.Second, "How often to check the registry for added or removed projects")
+	addLimitFlags(fs)
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: go-raph daemon run [flags] [analysis flags of every project]")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	openBrowser = false
+	if listenAddr == "" {
+		listenAddr = net.JoinHostPort("localhost", *port)
+	}
+
+	d := &daemon{children: make(map[string]*daemonChild), flags: fs.Args()}
+	if err := os.MkdirAll(d.socketDir(), 0o700); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	d.sync()
+	go func() {
+		for range time.Tick(*poll) {
+			d.sync()
+		}
+	}()
+
+	http.HandleFunc("/", d.indexHandler)
+	http.HandleFunc("/api/projects", gzipped(d.projectsHandler))
+	http.HandleFunc("/p/{name}/", d.proxyHandler)
+	ln, url := listen(*port)
+	fmt.Printf(tr("🗂️ Daemon: %s\n"), url)
+	serve(ln, url)
+}
+
+func (d *daemon) socketDir() string {
+	return filepath.Join(stateDir(), "daemon")
+}
+
+// sync starts servers for projects added to the registry and stops those
+// of removed or changed ones.
+func (d *daemon) sync() {
+	projects, err := readRegistry()
+	if err != nil {
+		log.Printf(tr("⚠️ Reading the project registry failed: %v"), err)
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	wanted := make(map[string]daemonProject, len(projects))
+	for _, p := range projects {
+		wanted[p.Name] = p
+	}
+	for name, c := range d.children {
+		if p, ok := wanted[name]; !ok || p.Path != c.project.Path || !slices.Equal(p.Flags, c.project.Flags) {
+			c.stop()
+			delete(d.children, name)
+			log.Printf(tr("🛑 Stopped %s"), name)
+		}
+	}
+	for _, p := range projects {
+		if d.children[p.Name] == nil {
+			d.children[p.Name] = d.start(p)
+			log.Printf(tr("▶️ Serving %s from %s"), p.Name, p.Path)
+		}
+	}
+}
+
+// start runs the server of a project until it is stopped, restarting it
+// with a growing delay when it exits.
+func (d *daemon) start(p daemonProject) *daemonChild {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &daemonChild{project: p, socket: filepath.Join(d.socketDir(), p.Name+".sock"), stop: cancel, status: "starting"}
+	c.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetXForwarded()
+			r.Out.Header.Set("X-Forwarded-Prefix", pageBase(r.In)+"p/"+p.Name)
+			r.Out.URL.Scheme, r.Out.URL.Host = "http", "unix"
+			r.Out.URL.Path = "/" + strings.TrimPrefix(r.In.URL.Path, "/p/"+p.Name+"/")
+			r.Out.URL.RawPath = ""
+		},
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", c.socket)
+			},
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("%s is %s", p.Name, c.state()), http.StatusBadGateway)
+		},
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	args := append([]string{"-watch", "-open=false", "-listen", "unix:" + c.socket}, d.flags...)
+	args = append(append(args, p.Flags...), p.Path)
+	go func() {
+		delay := time.Second
+		for ctx.Err() == nil {
+			cmd := exec.CommandContext(ctx, exe, args...)
+			out := &prefixWriter{prefix: "[" + p.Name + "] ", w: os.Stdout}
+			cmd.Stdout, cmd.Stderr = out, out
+			c.setState("running", "")
+			started := time.Now()
+			err := cmd.Run()
+			if ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) > time.Minute {
+				delay = time.Second // it ran fine for a while
+			}
+			c.setState("restarting", fmt.Sprint(err))
+			log.Printf(tr("⚠️ %s exited (%v), restarting in %s"), p.Name, err, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, time.Minute)
+		}
+	}()
+	return c
+}
+
+func (c *daemonChild) setState(status, err string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status, c.err = status, err
+}
+
+func (c *daemonChild) state() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// daemonStatus is a project as /api/projects lists it.
+type daemonStatus struct {
+	daemonProject
+	URL    string `json:"url"` // relative to the daemon
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (d *daemon) statuses() []daemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	statuses := []daemonStatus{}
+	for _, c := range d.children {
+		c.mu.Lock()
+		statuses = append(statuses, daemonStatus{c.project, "p/" + c.project.Name + "/", c.status, c.err})
+		c.mu.Unlock()
+	}
+	slices.SortFunc(statuses, func(a, b daemonStatus) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
+
+// projectsHandler serves GET /api/projects, the registered projects and
+// the state of their servers.
+func (d *daemon) projectsHandler(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, d.statuses())
+}
+
+// proxyHandler serves /p/{name}/ from the project's server.
+func (d *daemon) proxyHandler(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	c := d.children[r.PathValue("name")]
+	d.mu.Unlock()
+	if c == nil {
+		http.Error(w, "no such project", http.StatusNotFound)
+		return
+	}
+	c.proxy.ServeHTTP(w, r)
+}
+
+// indexHandler lists the projects, linking to their visualizers.
+func (d *daemon) indexHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page := struct {
+		Projects []daemonStatus
+		Style    template.CSS
+	}{d.statuses(), currentTheme().pageCSS()}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := daemonTemplate.Execute(w, page); err != nil {
+		log.Printf(tr("⚠️ Rendering dashboard failed: %v"), err)
+	}
+}
+
+var daemonTemplate = template.Must(template.New("daemon").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>go-raph daemon</title>
+<style>
+{{.Style}}
+body { font-size: 12px; margin: 40px; }
+table { border-collapse: collapse; margin-top: 12px; }
+td, th { padding: 4px 12px; text-align: left; border-bottom: 1px solid #222; }
+.error { color: rgba(255,100,100,1); }
+</style>
+</head>
+<body>
+<p><a href="api/projects">json</a></p>
+<h2>projects</h2>
+<table>
+<tr><th>project</th><th>path</th><th>status</th></tr>
+{{range .Projects}}<tr><td><a href="{{.URL}}">{{.Name}}</a></td><td>{{.Path}}</td><td>{{.Status}}{{if .Error}} <span class="error">{{.Error}}</span>{{end}}</td></tr>
+{{else}}<tr><td colspan="3">none yet, add one with <code>go-raph daemon add &lt;path&gt;</code></td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// prefixWriter prefixes every line written to w, telling apart the output
+// of the projects' servers.
+type prefixWriter struct {
+	mu      sync.Mutex
+	prefix  string
+	w       io.Writer
+	partial []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s", p.prefix, p.partial[:i+1]); err != nil {
+			return len(b), err
+		}
+		p.partial = p.partial[i+1:]
+	}
+}
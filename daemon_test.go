@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadRegistry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	registry := `[
+		{"name": "foo", "path": "/src/foo"},
+		{"name": "../../escape", "path": "/src/escape"},
+		{"name": "bar", "path": "-exec=sh"},
+		{"name": "foo", "path": "/src/other"},
+		{"name": "", "path": "/src/empty"},
+		{"name": "api.v2", "path": "/src/api"}
+	]`
+	path := filepath.Join(stateDir(), daemonRegistry)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(registry), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := readRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name+" "+p.Path)
+	}
+	if want := []string{"foo /src/foo", "api.v2 /src/api"}; !slices.Equal(names, want) {
+		t.Errorf("readRegistry() = %q, want %q", names, want)
+	}
+}
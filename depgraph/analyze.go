@@ -0,0 +1,612 @@
+package depgraph
+
+import (
+	"errors"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Options selects the optional, more expensive parts of the analysis.
+type Options struct {
+	Generics bool // parse full files to add edges for generic instantiations
+	Embeds   bool // add asset nodes for //go:embed directives
+	Protos   bool // add nodes for .proto files and the packages generated from them
+	Docker   bool // add nodes for the images Dockerfiles build and what goes into them
+	Services bool // add Kubernetes services linked to the binaries calling and serving them
+	Generate bool // add tool nodes for //go:generate directives
+	Files    bool // add a node per Go file, importing in place of its package
+
+	// Excludes are gitignore patterns applied below the .gitignore files of
+	// the tree, typically the user's global excludes.
+	Excludes []string
+	// Packages limits the graph to the packages matching these patterns and
+	// what they import, see MatchPackage. Empty keeps every package.
+	Packages []string
+	// InternalOnly leaves out third-party modules and their import paths.
+	InternalOnly bool
+}
+
+// skipDirs are the directories the analysis never descends into: vendored
+// and test-only code, version control and other ecosystems' build trees.
+var skipDirs = map[string]bool{
+	"vendor":       true,
+	"testdata":     true,
+	".git":         true,
+	"node_modules": true,
+	"bazel-out":    true,
+}
+
+// SkipDir reports whether walks of a module skip directories of this name.
+func SkipDir(name string) bool {
+	return skipDirs[name]
+}
+
+// Analyze builds the dependency graph of the Go module rooted at fsys.
+// Directories SkipDir names and paths the tree's .gitignore files or
+// opts.Excludes ignore are skipped, and unparsable files become warnings.
+// It fails with ErrNoGoMod outside a module and a *ParseError of a corrupt
+// go.mod.
+func Analyze(fsys fs.FS, opts Options) (*Graph, error) {
+	return NewIncremental(fsys, opts).Update()
+}
+
+// Incremental analyzes a module once and afterwards re-parses only the
+// package directories that changed, reusing what it kept about the others.
+// It is not safe for concurrent use.
+type Incremental struct {
+	fsys   fs.FS
+	opts   Options
+	ignore *Ignore
+	dirs   map[string]map[string]*fileInfo // package directory -> file path -> info
+}
+
+// fileInfo is what the analysis needs from one Go file. It is all that is
+// kept of the file between updates.
+type fileInfo struct {
+	size     int64
+	imports  []string
+	embeds   []embed
+	generics []string     // generic symbols declared
+	refs     []genericRef // targets are import paths until resolved
+	tools    []string     // code generators run by //go:generate
+	tooling  bool         // a tools.go file, only built with the tools tag
+	pkg      string       // package clause, for file granularity
+	decls    []string     // package-level names declared, for file granularity
+	uses     []string     // unqualified names referred to, for file granularity
+	proto    *protoInfo   // set for .proto files instead of the above
+	docker   *dockerfile  // set for Dockerfiles instead of the above
+	manifest *manifest    // set for Kubernetes manifests instead of the above
+	err      *ParseError  // why the Go file could not be read or parsed, leaving the rest empty
+}
+
+// NewIncremental prepares an analysis of the module rooted at fsys. The tree
+// is walked on the first Update.
+func NewIncremental(fsys fs.FS, opts Options) *Incremental {
+	return &Incremental{fsys: fsys, opts: opts, ignore: NewIgnore(fsys, opts.Excludes)}
+}
+
+// Update re-parses the Go files directly in the given directories (slash
+// separated and relative to the root, "." being the root) and returns the
+// rebuilt graph. Directories that are gone are dropped with everything below
+// them, and directories seen for the first time are walked completely. The
+// first Update walks the whole tree.
+func (inc *Incremental) Update(dirs ...string) (*Graph, error) {
+	var err error
+	if inc.dirs == nil {
+		inc.dirs = make(map[string]map[string]*fileInfo)
+		err = inc.scan(".", true)
+	}
+	for _, dir := range dirs {
+		if _, statErr := fs.Stat(inc.fsys, dir); statErr != nil {
+			inc.forget(dir, true)
+			continue
+		}
+		_, known := inc.dirs[dir]
+		if known && inc.ignore.reload(dir) {
+			known = false // what the directory's .gitignore ignores changed
+		}
+		if scanErr := inc.scan(dir, !known); err == nil {
+			err = scanErr
+		}
+	}
+	graph, buildErr := inc.build()
+	if buildErr != nil {
+		return nil, buildErr
+	}
+	return graph, err
+}
+
+// forget drops what is known about dir, and below it if recursive.
+func (inc *Incremental) forget(dir string, recursive bool) {
+	for d := range inc.dirs {
+		if d == dir || recursive && (dir == "." || strings.HasPrefix(d, dir+"/")) {
+			delete(inc.dirs, d)
+		}
+	}
+}
+
+// scan re-reads the Go files directly in dir, or in the whole tree below it
+// when recursive.
+func (inc *Incremental) scan(dir string, recursive bool) error {
+	inc.forget(dir, recursive)
+	if recursive {
+		inc.ignore.forget(dir)
+	}
+	return fs.WalkDir(inc.fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (name != dir && (!recursive || SkipDir(d.Name())) || inc.ignore.Ignored(name, true)) {
+			return fs.SkipDir
+		}
+		isProto := inc.opts.Protos && strings.HasSuffix(name, ".proto")
+		isDocker := inc.opts.Docker && !d.IsDir() && IsDockerfile(d.Name())
+		isManifest := inc.opts.Services && (strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml"))
+		if d.IsDir() || !strings.HasSuffix(name, ".go") && !isProto && !isDocker && !isManifest || inc.ignore.Ignored(name, false) {
+			return nil
+		}
+		parse := inc.parseFile
+		switch {
+		case isProto:
+			parse = inc.parseProtoFile
+		case isDocker:
+			parse = inc.parseDockerfile
+		case isManifest:
+			parse = inc.parseManifest
+		}
+		if info, ok := parse(name); ok {
+			pkgDir := path.Dir(name)
+			if inc.dirs[pkgDir] == nil {
+				inc.dirs[pkgDir] = make(map[string]*fileInfo)
+			}
+			inc.dirs[pkgDir][name] = info
+		}
+		return nil
+	})
+}
+
+// parseFile extracts what the analysis needs from a Go file. Files that
+// cannot be read or parsed are kept with the error, to be reported on their
+// package.
+func (inc *Incremental) parseFile(name string) (*fileInfo, bool) {
+	// Generic instantiations and directives live past the imports, so only
+	// parse the whole file when asked
+	mode := parser.ImportsOnly
+	if inc.opts.Generics || inc.opts.Embeds || inc.opts.Generate || inc.opts.Files {
+		mode = parser.SkipObjectResolution
+	}
+	if inc.opts.Embeds || inc.opts.Generate {
+		mode |= parser.ParseComments
+	}
+	src, err := fs.ReadFile(inc.fsys, name)
+	if err != nil {
+		return &fileInfo{err: &ParseError{name, err}}, true
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, mode)
+	if err != nil {
+		return &fileInfo{size: int64(len(src)), err: &ParseError{name, err}}, true
+	}
+
+	info := &fileInfo{size: int64(len(src))}
+	for _, imp := range file.Imports {
+		info.imports = append(info.imports, strings.Trim(imp.Path.Value, `"`))
+	}
+	if inc.opts.Embeds {
+		for _, pattern := range embedPatterns(file) {
+			info.embeds = append(info.embeds, embed{pattern, embedSize(inc.fsys, path.Dir(name), pattern)})
+		}
+	}
+	if inc.opts.Generics {
+		info.generics = genericDecls(file)
+		info.refs = collectGenericRefs(file)
+	}
+	if inc.opts.Generate {
+		info.tools = generateTools(file)
+	}
+	if inc.opts.Files {
+		info.pkg = file.Name.Name
+		info.decls, info.uses = fileSymbols(file)
+	}
+	info.tooling = isToolsFile(src)
+	return info, true
+}
+
+func (inc *Incremental) parseProtoFile(name string) (*fileInfo, bool) {
+	src, err := fs.ReadFile(inc.fsys, name)
+	if err != nil {
+		return nil, false
+	}
+	return &fileInfo{proto: parseProto(src)}, true
+}
+
+func (inc *Incremental) parseDockerfile(name string) (*fileInfo, bool) {
+	src, err := fs.ReadFile(inc.fsys, name)
+	if err != nil {
+		return nil, false
+	}
+	return &fileInfo{docker: parseDockerfile(src)}, true
+}
+
+func (inc *Incremental) parseManifest(name string) (*fileInfo, bool) {
+	src, err := fs.ReadFile(inc.fsys, name)
+	if err != nil {
+		return nil, false
+	}
+	return &fileInfo{manifest: parseManifest(src)}, true
+}
+
+// build assembles the graph from go.mod and the kept file information.
+func (inc *Incremental) build() (*Graph, error) {
+	graph := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	nodeMap := make(map[string]*Node)
+	moduleToImporter := make(map[string][]string) // track which packages import each module
+	directModules := make(map[string]bool)        // track direct vs indirect modules
+	usedModules := make(map[string]bool)          // track modules that are actually imported
+
+	availableModules := make(map[string]bool)
+	versions := make(map[string]string)                   // required version per module
+	sizes := make(map[string]int64)                       // source bytes per package
+	warnings := make(map[string][]string)                 // unparsable files and unresolved imports per importer
+	genericDeclsByPkg := make(map[string]map[string]bool) // generic symbols declared per package
+	var genericRefs []genericRef
+	modTools := make(map[string]string)  // go.mod tool directives by base name
+	toolModules := make(map[string]bool) // modules providing code generators
+	runtimeUse := make(map[string]bool)  // module and import nodes imported by regular files
+	toolingUse := make(map[string]bool)  // module and import nodes imported by tools.go files
+
+	data, err := fs.ReadFile(inc.fsys, "go.mod")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoGoMod
+	} else if err != nil {
+		return nil, &ParseError{"go.mod", err}
+	}
+	modFile, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil, &ParseError{"go.mod", err}
+	}
+	if modFile.Module == nil {
+		return nil, &ParseError{"go.mod", errors.New("no module directive")}
+	}
+	mainModule := modFile.Module.Mod.Path
+
+	// Add main module
+	addNode(graph, nodeMap, mainModule, mainModule, "main", 0)
+
+	// Track available external modules
+	for _, req := range modFile.Require {
+		availableModules[req.Mod.Path] = true
+		versions[req.Mod.Path] = req.Mod.Version
+		directModules[req.Mod.Path] = !req.Indirect
+	}
+	for _, tool := range modFile.Tool {
+		modTools[path.Base(tool.Path)] = tool.Path
+	}
+
+	// Go files in path order, as a walk would visit them
+	files := make(map[string]*fileInfo)
+	protos := make(map[string]*protoInfo)
+	dockerfiles := make(map[string]*dockerfile)
+	manifests := make(map[string]*manifest)
+	goFiles := make(map[string]bool)
+	for _, dirFiles := range inc.dirs {
+		for name, info := range dirFiles {
+			switch {
+			case info.proto != nil:
+				protos[name] = info.proto
+			case info.docker != nil:
+				dockerfiles[name] = info.docker
+			case info.manifest != nil:
+				manifests[name] = info.manifest
+			default:
+				files[name] = info
+				goFiles[name] = true
+			}
+		}
+	}
+	for _, name := range sortedKeys(files) {
+		info := files[name]
+		relPath := path.Dir(name)
+		packageID := PackageID(relPath)
+		// Use directory name for label to avoid confusion with main module
+		displayName := path.Base(relPath)
+		if relPath == "." {
+			displayName = "main"
+		}
+		addNode(graph, nodeMap, packageID, displayName, "package", 0)
+		sizes[packageID] += info.size
+		// Imports come from the file itself at file granularity
+		importer := packageID
+		if inc.opts.Files {
+			importer = "file:" + name
+			addNode(graph, nodeMap, importer, path.Base(name), "file", 0)
+			addEdgeKind(graph, packageID, importer, "contains")
+			sizes[importer] = info.size
+		}
+		if info.err != nil {
+			warnings[importer] = append(warnings[importer], info.err.Error())
+			continue
+		}
+		importTargets := make(map[string]string) // import path -> node the import was attributed to
+
+		// Process imports
+		for _, importPath := range info.imports {
+			// Skip standard library (packages without dots that aren't internal imports)
+			if !strings.Contains(importPath, ".") && !strings.HasPrefix(importPath, mainModule) {
+				continue
+			}
+
+			if strings.HasPrefix(importPath, mainModule) {
+				// Internal import - connect packages directly, no separate import nodes
+				// Find or create the target package
+				targetRelPath := strings.TrimPrefix(importPath, mainModule+"/")
+				if targetRelPath == importPath {
+					// This is importing the main module itself, skip
+					continue
+				}
+
+				targetPackageID := PackageID(targetRelPath)
+				targetDisplayName := path.Base(targetRelPath)
+				addNode(graph, nodeMap, targetPackageID, targetDisplayName, "package", 0)
+				addEdge(graph, importer, targetPackageID)
+				importTargets[importPath] = targetPackageID
+			} else {
+				// External import - find the best matching module (longest prefix)
+				rootModule := requiredModule(availableModules, importPath)
+
+				if rootModule != "" {
+					// Mark this module as actually used
+					usedModules[rootModule] = true
+
+					// Add module node if not exists
+					addNode(graph, nodeMap, rootModule, rootModule, "external", 2)
+
+					// Track that this package imports this module
+					moduleToImporter[rootModule] = append(moduleToImporter[rootModule], importer)
+
+					// If import path exactly matches the module root, connect directly to module
+					if importPath == rootModule {
+						addEdge(graph, importer, rootModule)
+						importTargets[importPath] = rootModule
+					} else {
+						// Create separate import node for sub-packages
+						importID := "import:" + importPath
+						// Use full import path for external dependencies, not just base name;
+						// a LabelPolicy shortens long ones
+						addNode(graph, nodeMap, importID, importPath, "external", 1)
+						addEdge(graph, importer, importID)
+						importTargets[importPath] = importID
+
+						// Connect import to its root module
+						addEdge(graph, importID, rootModule)
+					}
+
+					// Modules only tools.go files import are tooling
+					uses := runtimeUse
+					if info.tooling {
+						uses = toolingUse
+					}
+					uses[rootModule] = true
+					uses[importTargets[importPath]] = true
+				} else if warning := (&ModResolveError{importPath}).Error(); !slices.Contains(warnings[importer], warning) {
+					warnings[importer] = append(warnings[importer], warning)
+				}
+			}
+		}
+
+		if inc.opts.Embeds {
+			addEmbedNodes(graph, nodeMap, packageID, strings.TrimPrefix(packageID, "pkg:"), info.embeds)
+		}
+
+		if inc.opts.Generate {
+			addToolNodes(graph, nodeMap, packageID, info.tools, modTools, func(toolPath string) string {
+				module := requiredModule(availableModules, toolPath)
+				if module != "" {
+					addNode(graph, nodeMap, module, module, "external", 2)
+					toolModules[module] = true
+				}
+				return module
+			})
+		}
+
+		if inc.opts.Generics {
+			if genericDeclsByPkg[packageID] == nil {
+				genericDeclsByPkg[packageID] = make(map[string]bool)
+			}
+			for _, name := range info.generics {
+				genericDeclsByPkg[packageID][name] = true
+			}
+			for _, ref := range info.refs {
+				if target, ok := importTargets[ref.target]; ok {
+					ref.source, ref.target = packageID, target
+					genericRefs = append(genericRefs, ref)
+				}
+			}
+		}
+	}
+
+	if inc.opts.Files {
+		addFileEdges(graph, files)
+	}
+
+	if inc.opts.Generics {
+		addInstantiationEdges(graph, genericRefs, genericDeclsByPkg)
+	}
+
+	if inc.opts.Protos {
+		addProtoNodes(graph, nodeMap, mainModule, protos, goFiles)
+	}
+
+	if inc.opts.Docker {
+		addDockerNodes(graph, nodeMap, mainModule, dockerfiles)
+	}
+
+	if inc.opts.Services {
+		addServiceNodes(graph, nodeMap, mainModule, manifests)
+	}
+
+	// Modules of go.mod tool directives hang off the main module
+	for _, name := range sortedKeys(modTools) {
+		if module := requiredModule(availableModules, modTools[name]); module != "" {
+			addNode(graph, nodeMap, module, module, "external", 2)
+			addEdgeKind(graph, mainModule, module, "tool")
+			toolModules[module] = true
+		}
+	}
+
+	// ONLY connect modules that are actually used in imports. Walk them in a
+	// fixed order so indirect modules always pick the same parent.
+	orphans := make(map[string]bool)
+	for _, modulePath := range sortedKeys(usedModules) {
+		if directModules[modulePath] {
+			// Direct dependency that's actually imported - connect to main
+			addEdge(graph, mainModule, modulePath)
+		} else {
+			// Indirect dependency that's actually imported - find best parent
+			connected := false
+			for _, directModule := range sortedKeys(directModules) {
+				if directModules[directModule] && usedModules[directModule] {
+					// Check if this indirect module is likely a sub-dependency
+					if strings.HasPrefix(modulePath, strings.Split(directModule, "/")[0]) ||
+						strings.Contains(modulePath, strings.Split(directModule, "/")[0]) {
+						addEdge(graph, directModule, modulePath)
+						connected = true
+						break
+					}
+				}
+			}
+			// If we can't find a good parent, don't connect it to avoid orphans
+			if !connected && !toolModules[modulePath] {
+				orphans[modulePath] = true
+			}
+		}
+	}
+	// Remove the orphaned modules to avoid yellow dots, with the edges of
+	// their import paths to them, and re-index the nodes that moved
+	if graph.RemoveNodes(orphans) > 0 {
+		for modulePath := range orphans {
+			delete(nodeMap, modulePath)
+		}
+		for i := range graph.Nodes {
+			nodeMap[graph.Nodes[i].ID] = &graph.Nodes[i]
+		}
+	}
+
+	for i := range graph.Nodes {
+		graph.Nodes[i].FullPath = fullPath(&graph.Nodes[i], mainModule)
+		switch graph.Nodes[i].Type {
+		case "external":
+			graph.Nodes[i].Version = versions[graph.Nodes[i].ID]
+			if id := graph.Nodes[i].ID; !runtimeUse[id] && (toolingUse[id] || toolModules[id]) {
+				graph.Nodes[i].Type = "tooling"
+			}
+		case "package", "file":
+			graph.Nodes[i].Size = sizes[graph.Nodes[i].ID]
+			graph.Nodes[i].Warnings = warnings[graph.Nodes[i].ID]
+		}
+	}
+
+	if len(inc.opts.Packages) > 0 {
+		graph.scope(mainModule, inc.opts.Packages)
+	}
+	if inc.opts.InternalOnly {
+		graph.dropExternal()
+	}
+	graph.Sort()
+	return graph, nil
+}
+
+// isToolsFile reports whether a Go file follows the tools.go convention of
+// blank-importing tools behind a build constraint that needs the tools tag.
+func isToolsFile(src []byte) bool {
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "//") {
+			return false // constraints only come before the package clause
+		}
+		if !constraint.IsGoBuild(line) && !constraint.IsPlusBuild(line) {
+			continue
+		}
+		expr, err := constraint.Parse(line)
+		if err == nil && expr.Eval(func(tag string) bool { return tag == "tools" }) && !expr.Eval(func(string) bool { return false }) {
+			return true
+		}
+	}
+	return false
+}
+
+// fullPath returns the untruncated name of a node: the import path of
+// packages and import nodes, the module path of modules, the path of files
+// and the label of anything else.
+func fullPath(node *Node, mainModule string) string {
+	id := node.ID
+	if dir, ok := PackageDir(id); ok {
+		return path.Join(mainModule, dir)
+	}
+	switch {
+	case strings.HasPrefix(id, "file:"):
+		return mainModule + "/" + strings.TrimPrefix(id, "file:")
+	case strings.HasPrefix(id, "import:"):
+		return strings.TrimPrefix(id, "import:")
+	case node.Type == "main" || node.Type == "external":
+		return id
+	}
+	return node.Label
+}
+
+// requiredModule returns the longest required module path that is a prefix
+// of importPath, or "".
+func requiredModule(modules map[string]bool, importPath string) string {
+	var rootModule string
+	for modulePath := range modules {
+		if strings.HasPrefix(importPath, modulePath) && len(modulePath) > len(rootModule) {
+			rootModule = modulePath
+		}
+	}
+	return rootModule
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func addNode(graph *Graph, nodeMap map[string]*Node, id, label, nodeType string, depth int) {
+	if _, exists := nodeMap[id]; !exists {
+		node := Node{
+			ID:    id,
+			Label: label,
+			Type:  nodeType,
+			Depth: depth,
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		nodeMap[id] = &graph.Nodes[len(graph.Nodes)-1]
+	}
+}
+
+func addEdge(graph *Graph, source, target string) {
+	addEdgeKind(graph, source, target, "")
+}
+
+// addEdgeKind adds an edge of a specific kind; an empty kind is a plain import.
+func addEdgeKind(graph *Graph, source, target, kind string) {
+	// Prevent duplicate edges
+	for _, edge := range graph.Edges {
+		if edge.Source == source && edge.Target == target && edge.Kind == kind {
+			return
+		}
+	}
+	graph.Edges = append(graph.Edges, Edge{Source: source, Target: target, Kind: kind})
+}
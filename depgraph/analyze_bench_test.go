@@ -0,0 +1,52 @@
+package depgraph
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// BenchmarkAnalyze analyzes every checkout in $GORAPH_BENCH_REPOS, as
+// fetched by scripts/bench.sh, reporting the graph's size and JSON payload
+// next to time and memory. Without the variable it analyzes the test
+// module.
+func BenchmarkAnalyze(b *testing.B) {
+	dir := os.Getenv("GORAPH_BENCH_REPOS")
+	if dir == "" {
+		benchmarkAnalyze(b, testModule, Options{})
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	all := Options{Generics: true, Embeds: true, Protos: true, Docker: true, Services: true, Generate: true}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		fsys := os.DirFS(filepath.Join(dir, entry.Name()))
+		b.Run(entry.Name()+"/imports", func(b *testing.B) { benchmarkAnalyze(b, fsys, Options{}) })
+		b.Run(entry.Name()+"/all", func(b *testing.B) { benchmarkAnalyze(b, fsys, all) })
+	}
+}
+
+func benchmarkAnalyze(b *testing.B, fsys fs.FS, opts Options) {
+	b.ReportAllocs()
+	var graph *Graph
+	for b.Loop() {
+		var err error
+		if graph, err = Analyze(fsys, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+	payload, err := json.Marshal(graph)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(len(graph.Nodes)), "nodes")
+	b.ReportMetric(float64(len(graph.Edges)), "edges")
+	b.ReportMetric(float64(len(payload)), "payload-B")
+}
@@ -0,0 +1,295 @@
+package depgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testModule imports enough modules, direct and indirect, that map iteration
+// order would show up in the output if it leaked.
+var testModule = fstest.MapFS{
+	"go.mod": {Data: []byte(`module example.com/app
+
+go 1.24
+
+require (
+	github.com/a/one v1.0.0
+	github.com/b/two v1.2.0
+	github.com/c/three v0.3.0
+	github.com/a/indirect v1.0.0 // indirect
+	golang.org/x/mod v0.20.0 // indirect
+)
+`)},
+	"main.go": {Data: []byte(`package main
+
+import (
+	_ "example.com/app/internal/store"
+	_ "example.com/app/web"
+	_ "github.com/c/three"
+)
+`)},
+	"web/web.go": {Data: []byte(`package web
+
+import (
+	_ "example.com/app/internal/store"
+	_ "github.com/a/one/sub"
+	_ "github.com/b/two"
+	_ "golang.org/x/mod/semver"
+)
+`)},
+	"internal/store/store.go": {Data: []byte(`package store
+
+import (
+	_ "github.com/a/indirect/pkg"
+	_ "github.com/b/two/client"
+)
+`)},
+}
+
+func TestAnalyzeDeterministic(t *testing.T) {
+	first, err := Analyze(testModule, Options{Generics: true, Embeds: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := json.Marshal(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		graph, err := Analyze(testModule, Options{Generics: true, Embeds: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(graph)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("run %d differs:\n got %s\nwant %s", i, got, want)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	graph := &Graph{
+		Nodes: []Node{{ID: "pkg:b"}, {ID: "example.com/app"}, {ID: "pkg:a"}},
+		Edges: []Edge{
+			{Source: "pkg:b", Target: "pkg:a", Kind: "instantiates"},
+			{Source: "pkg:b", Target: "pkg:a"},
+			{Source: "example.com/app", Target: "pkg:b"},
+		},
+	}
+	graph.Sort()
+
+	var ids []string
+	for _, n := range graph.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if got, want := ids, []string{"example.com/app", "pkg:a", "pkg:b"}; !slices.Equal(got, want) {
+		t.Errorf("nodes = %v, want %v", got, want)
+	}
+	wantEdges := []Edge{
+		{Source: "example.com/app", Target: "pkg:b"},
+		{Source: "pkg:b", Target: "pkg:a"},
+		{Source: "pkg:b", Target: "pkg:a", Kind: "instantiates"},
+	}
+	for i, e := range graph.Edges {
+		if e.Source != wantEdges[i].Source || e.Target != wantEdges[i].Target || e.Kind != wantEdges[i].Kind {
+			t.Errorf("edge %d = %+v, want %+v", i, e, wantEdges[i])
+		}
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	graph := &Graph{
+		Nodes: []Node{{ID: "example.com/app"}, {ID: "github.com/a/one"}, {ID: "import:github.com/a/one/sub"}},
+		Edges: []Edge{
+			{Source: "example.com/app", Target: "github.com/a/one"},
+			{Source: "import:github.com/a/one/sub", Target: "github.com/a/one"},
+			{Source: "example.com/app", Target: "import:github.com/a/one/sub"},
+		},
+	}
+	if !graph.RemoveNode("github.com/a/one") || graph.RemoveNode("github.com/a/one") {
+		t.Fatal("RemoveNode reported the wrong existence")
+	}
+	if len(graph.Nodes) != 2 || graph.Node("github.com/a/one") != nil {
+		t.Errorf("nodes = %+v", graph.Nodes)
+	}
+	if len(graph.Edges) != 1 || graph.Edges[0].Target != "import:github.com/a/one/sub" {
+		t.Errorf("edges = %+v", graph.Edges)
+	}
+}
+
+func TestMarkNew(t *testing.T) {
+	base := &Graph{
+		Nodes: []Node{{ID: "example.com/app"}, {ID: "pkg:a"}},
+		Edges: []Edge{{Source: "example.com/app", Target: "pkg:a"}},
+	}
+	graph := &Graph{
+		Nodes: []Node{{ID: "example.com/app"}, {ID: "pkg:a"}, {ID: "pkg:b", New: true}},
+		Edges: []Edge{
+			{Source: "example.com/app", Target: "pkg:a"},
+			{Source: "pkg:b", Target: "pkg:a"},
+			{Source: "example.com/app", Target: "pkg:a", Kind: "instantiates"},
+		},
+	}
+	graph.MarkNew(base)
+
+	var nodes, edges []bool
+	for _, n := range graph.Nodes {
+		nodes = append(nodes, n.New)
+	}
+	for _, e := range graph.Edges {
+		edges = append(edges, e.New)
+	}
+	if want := []bool{false, false, true}; !slices.Equal(nodes, want) {
+		t.Errorf("new nodes = %v, want %v", nodes, want)
+	}
+	if want := []bool{false, true, true}; !slices.Equal(edges, want) {
+		t.Errorf("new edges = %v, want %v", edges, want)
+	}
+}
+
+func TestProvenanceSurvivesCopies(t *testing.T) {
+	graph := &Graph{
+		Nodes:      []Node{{ID: "example.com/app"}, {ID: "pkg:a"}},
+		Edges:      []Edge{{Source: "example.com/app", Target: "pkg:a"}},
+		Provenance: &Provenance{Tool: "go-raph v1.0.0", Flags: []string{"-generics=true"}},
+	}
+	c := graph.Clone()
+	c.Provenance.Flags[0] = "-embeds=true"
+	if graph.Provenance.Flags[0] != "-generics=true" {
+		t.Error("Clone shares the provenance flags")
+	}
+	sub, err := graph.Subgraph("pkg:a", 1, "in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Provenance == nil || sub.Provenance.Tool != "go-raph v1.0.0" {
+		t.Errorf("subgraph provenance = %+v", sub.Provenance)
+	}
+}
+
+func TestIncrementalMatchesAnalyze(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, file := range testModule {
+		fsys[name] = file
+	}
+	inc := NewIncremental(fsys, Options{Generics: true})
+	if _, err := inc.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change a package, add one in a new directory and remove another
+	fsys["web/web.go"] = &fstest.MapFile{Data: []byte("package web\n\nimport _ \"github.com/c/three\"\n")}
+	fsys["cmd/tool/main.go"] = &fstest.MapFile{Data: []byte("package main\n\nimport _ \"example.com/app/web\"\n")}
+	delete(fsys, "internal/store/store.go")
+
+	got, err := inc.Update("web", "cmd", "internal/store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Analyze(fsys, Options{Generics: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("incremental update differs from a full analysis:\n got %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+func TestAnalyzeSkipsDirs(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, file := range testModule {
+		fsys[name] = file
+	}
+	for _, dir := range []string{"vendor/github.com/a/one", "testdata/broken", "node_modules/x", ".git/hooks", "bazel-out/k8-fastbuild/bin"} {
+		fsys[dir+"/skipped.go"] = &fstest.MapFile{Data: []byte("package skipped\n\nimport _ \"github.com/skipped/module\"\n")}
+	}
+	fsys["internal/myvendor/v.go"] = &fstest.MapFile{Data: []byte("package myvendor\n")}
+
+	graph, err := Analyze(fsys, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range graph.Nodes {
+		if strings.Contains(node.ID, "skipped") || strings.Contains(node.ID, "vendor/github.com") {
+			t.Errorf("node %s from a skipped directory", node.ID)
+		}
+	}
+	if graph.Node("pkg:internal/myvendor") == nil {
+		t.Error("internal/myvendor was skipped like vendor/")
+	}
+}
+
+func TestAnalyzeReportsParseErrors(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, file := range testModule {
+		fsys[name] = file
+	}
+	fsys["internal/broken/ok.go"] = &fstest.MapFile{Data: []byte("package broken\n\nimport _ \"github.com/a/one\"\n")}
+	fsys["internal/broken/bad.go"] = &fstest.MapFile{Data: []byte("package broken\n\nimport (\n")}
+
+	graph, err := Analyze(fsys, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := graph.Node("pkg:internal/broken")
+	if node == nil {
+		t.Fatal("the package with a broken file is missing")
+	}
+	if len(node.Warnings) != 1 || !strings.HasPrefix(node.Warnings[0], "internal/broken/bad.go:") {
+		t.Errorf("warnings = %q, want the parse error of bad.go", node.Warnings)
+	}
+	diagnostics := graph.Diagnostics()
+	if len(diagnostics) != 1 || diagnostics[0].Node != "pkg:internal/broken" {
+		t.Errorf("Diagnostics() = %v", diagnostics)
+	}
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	mainGo := &fstest.MapFile{Data: []byte("package main\n\nimport _ \"example.org/unrequired/pkg\"\n")}
+	for _, tt := range []struct {
+		name  string
+		gomod string
+		want  error
+	}{
+		{"no go.mod", "", ErrNoGoMod},
+		{"corrupt go.mod", "module\n", ErrParse},
+		{"no module directive", "go 1.24\n", ErrParse},
+	} {
+		fsys := fstest.MapFS{"main.go": mainGo}
+		if tt.gomod != "" {
+			fsys["go.mod"] = &fstest.MapFile{Data: []byte(tt.gomod)}
+		}
+		_, err := Analyze(fsys, Options{})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Analyze() = %v, want %v", tt.name, err, tt.want)
+		}
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) && parseErr.File != "go.mod" {
+			t.Errorf("%s: ParseError.File = %q, want go.mod", tt.name, parseErr.File)
+		}
+	}
+
+	fsys := fstest.MapFS{"go.mod": {Data: []byte("module example.com/app\n")}, "main.go": mainGo}
+	graph, err := Analyze(fsys, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := (&ModResolveError{"example.org/unrequired/pkg"}).Error()
+	if node := graph.Node("pkg:root"); node == nil || !slices.Equal(node.Warnings, []string{want}) {
+		t.Errorf("pkg:root = %+v, want the warning %q", node, want)
+	}
+	if !errors.Is(&ModResolveError{"x.org/y"}, ErrModResolve) {
+		t.Error("ModResolveError does not match ErrModResolve")
+	}
+}
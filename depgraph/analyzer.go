@@ -0,0 +1,52 @@
+package depgraph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Analyzer enriches an analyzed graph, typically by annotating nodes.
+// Analyzers run after the import graph has been built, in name order.
+type Analyzer interface {
+	Name() string
+	Enrich(ctx context.Context, g *Graph) error
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Analyzer)
+)
+
+// Register makes an analyzer available to go-raph. It is meant to be called
+// from init functions of packages compiled into the binary or loaded as Go
+// plugins, and panics if an analyzer with the same name is registered twice.
+func Register(a Analyzer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[a.Name()]; dup {
+		panic(fmt.Sprintf("depgraph: Register called twice for analyzer %q", a.Name()))
+	}
+	registry[a.Name()] = a
+}
+
+// Registered reports whether an analyzer with the name is registered.
+func Registered(name string) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	_, ok := registry[name]
+	return ok
+}
+
+// Analyzers returns the registered analyzers sorted by name.
+func Analyzers() []Analyzer {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	list := make([]Analyzer, 0, len(registry))
+	for _, a := range registry {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
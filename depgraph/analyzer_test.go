@@ -0,0 +1,28 @@
+package depgraph
+
+import (
+	"context"
+	"testing"
+)
+
+type namedAnalyzer string
+
+func (a namedAnalyzer) Name() string                             { return string(a) }
+func (namedAnalyzer) Enrich(ctx context.Context, g *Graph) error { return nil }
+
+func TestRegister(t *testing.T) {
+	a := namedAnalyzer("test-register")
+	if Registered(a.Name()) {
+		t.Fatalf("%s registered before Register", a)
+	}
+	Register(a)
+	if !Registered(a.Name()) {
+		t.Fatalf("%s not registered after Register", a)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice did not panic")
+		}
+	}()
+	Register(a)
+}
@@ -0,0 +1,167 @@
+package depgraph
+
+import "sort"
+
+// Communities clusters the packages of the graph with the Louvain method,
+// treating plain imports between packages as undirected edges. It returns a
+// community number per package, starting at 1 for the largest community.
+// Results are deterministic for a given graph.
+func (g *Graph) Communities() map[string]int {
+	var ids []string
+	index := make(map[string]int)
+	for _, node := range g.Nodes {
+		if node.Type == "package" {
+			index[node.ID] = len(ids)
+			ids = append(ids, node.ID)
+		}
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	// Symmetric weights; w[i][i] holds twice the weight inside node i
+	w := make([]map[int]float64, len(ids))
+	for i := range w {
+		w[i] = make(map[int]float64)
+	}
+	for _, edge := range g.Edges {
+		s, ok1 := index[edge.Source]
+		t, ok2 := index[edge.Target]
+		if edge.Kind != "" || !ok1 || !ok2 || s == t {
+			continue
+		}
+		w[s][t]++
+		w[t][s]++
+	}
+
+	// member[i] is the community of original package i
+	member := make([]int, len(ids))
+	for i := range member {
+		member[i] = i
+	}
+	for {
+		community, moved := louvainMove(w)
+		if !moved {
+			break
+		}
+		for i := range member {
+			member[i] = community[member[i]]
+		}
+		w = louvainAggregate(w, community)
+	}
+
+	// Number communities by size, then by their first package
+	sizes := make(map[int]int)
+	first := make(map[int]string)
+	for i, c := range member {
+		sizes[c]++
+		if _, ok := first[c]; !ok {
+			first[c] = ids[i]
+		}
+	}
+	var order []int
+	for c := range sizes {
+		order = append(order, c)
+	}
+	sort.Slice(order, func(a, b int) bool {
+		if sizes[order[a]] != sizes[order[b]] {
+			return sizes[order[a]] > sizes[order[b]]
+		}
+		return first[order[a]] < first[order[b]]
+	})
+	number := make(map[int]int)
+	for i, c := range order {
+		number[c] = i + 1
+	}
+	result := make(map[string]int, len(ids))
+	for i, c := range member {
+		result[ids[i]] = number[c]
+	}
+	return result
+}
+
+// louvainMove greedily moves nodes to the neighboring community with the
+// best modularity gain until no move helps. It returns the dense community
+// number of each node and whether any node changed community.
+func louvainMove(w []map[int]float64) ([]int, bool) {
+	n := len(w)
+	degree := make([]float64, n)
+	var m2 float64
+	for i := range w {
+		for _, weight := range w[i] {
+			degree[i] += weight
+		}
+		m2 += degree[i]
+	}
+	community := make([]int, n)
+	total := make([]float64, n) // sum of degrees per community
+	for i := range community {
+		community[i] = i
+		total[i] = degree[i]
+	}
+	if m2 == 0 {
+		return community, false
+	}
+
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n; i++ {
+			current := community[i]
+			links := make(map[int]float64) // weight from i into each neighboring community
+			var neighbors []int
+			for j, weight := range w[i] {
+				if j == i {
+					continue
+				}
+				if _, ok := links[community[j]]; !ok {
+					neighbors = append(neighbors, community[j])
+				}
+				links[community[j]] += weight
+			}
+			sort.Ints(neighbors)
+
+			total[current] -= degree[i]
+			best, bestGain := current, links[current]-total[current]*degree[i]/m2
+			for _, c := range neighbors {
+				if gain := links[c] - total[c]*degree[i]/m2; gain > bestGain {
+					best, bestGain = c, gain
+				}
+			}
+			total[best] += degree[i]
+			if best != current {
+				community[i] = best
+				improved, moved = true, true
+			}
+		}
+	}
+
+	// Renumber densely
+	dense := make(map[int]int)
+	for i, c := range community {
+		if _, ok := dense[c]; !ok {
+			dense[c] = len(dense)
+		}
+		community[i] = dense[c]
+	}
+	return community, moved
+}
+
+// louvainAggregate builds the graph whose nodes are the communities.
+func louvainAggregate(w []map[int]float64, community []int) []map[int]float64 {
+	n := 0
+	for _, c := range community {
+		n = max(n, c+1)
+	}
+	agg := make([]map[int]float64, n)
+	for i := range agg {
+		agg[i] = make(map[int]float64)
+	}
+	for i := range w {
+		for j, weight := range w[i] {
+			agg[community[i]][community[j]] += weight
+		}
+	}
+	return agg
+}
@@ -0,0 +1,32 @@
+package depgraph
+
+import "testing"
+
+func TestCommunities(t *testing.T) {
+	// Two triangles joined by a single import
+	graph := &Graph{}
+	for _, id := range []string{"a1", "a2", "a3", "b1", "b2", "b3"} {
+		graph.Nodes = append(graph.Nodes, Node{ID: "pkg:" + id, Type: "package"})
+	}
+	for _, e := range [][2]string{
+		{"a1", "a2"}, {"a2", "a3"}, {"a3", "a1"},
+		{"b1", "b2"}, {"b2", "b3"}, {"b3", "b1"},
+		{"a1", "b1"},
+	} {
+		graph.Edges = append(graph.Edges, Edge{Source: "pkg:" + e[0], Target: "pkg:" + e[1]})
+	}
+
+	c := graph.Communities()
+	if c["pkg:a1"] != c["pkg:a2"] || c["pkg:a1"] != c["pkg:a3"] {
+		t.Errorf("a packages split: %v", c)
+	}
+	if c["pkg:b1"] != c["pkg:b2"] || c["pkg:b1"] != c["pkg:b3"] {
+		t.Errorf("b packages split: %v", c)
+	}
+	if c["pkg:a1"] == c["pkg:b1"] {
+		t.Errorf("triangles merged: %v", c)
+	}
+	if c["pkg:a1"] != 1 || c["pkg:b1"] != 2 {
+		t.Errorf("communities not numbered by size then name: %v", c)
+	}
+}
@@ -0,0 +1,199 @@
+package depgraph
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestGraphQueries(t *testing.T) {
+	graph := &Graph{}
+	for _, n := range [][2]string{
+		{"example.com/m", "main"},
+		{"pkg:cmd", "package"}, {"pkg:a", "package"}, {"pkg:b", "package"}, {"pkg:c", "package"}, {"pkg:d", "package"},
+		{"github.com/x/y", "external"},
+	} {
+		graph.Nodes = append(graph.Nodes, Node{ID: n[0], Type: n[1]})
+	}
+	for _, e := range [][3]string{
+		{"example.com/m", "github.com/x/y", ""},
+		{"pkg:cmd", "pkg:a", ""}, {"pkg:cmd", "pkg:b", ""},
+		{"pkg:a", "pkg:c", ""}, {"pkg:b", "pkg:c", ""},
+		{"pkg:c", "pkg:d", ""}, {"pkg:d", "pkg:c", ""}, // cycle
+		{"pkg:b", "pkg:b", ""}, // self-import
+		{"pkg:d", "github.com/x/y", ""},
+		{"pkg:a", "pkg:d", "generic"},
+	} {
+		graph.Edges = append(graph.Edges, Edge{Source: e[0], Target: e[1], Kind: e[2]})
+	}
+
+	if got, want := graph.Cycles(), [][]string{{"pkg:b"}, {"pkg:c", "pkg:d"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles() = %v, want %v", got, want)
+	}
+	if got := graph.LongestChain(); got != 4 {
+		t.Errorf("LongestChain() = %d, want 4", got)
+	}
+	want := [][]string{
+		{"pkg:cmd", "pkg:a", "pkg:c", "pkg:d", "github.com/x/y"},
+		{"pkg:cmd", "pkg:b", "pkg:c", "pkg:d", "github.com/x/y"},
+	}
+	if got := graph.ImportChains("github.com/x/y", 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportChains() = %v, want %v", got, want)
+	}
+	if got := graph.ImportChains("github.com/x/y", 1); len(got) != 1 {
+		t.Errorf("ImportChains() with limit 1 returned %d chains", len(got))
+	}
+	if got, want := graph.Unreachable([]string{"pkg:c"}), []string(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unreachable(c) = %v, want %v", got, want) // the generic edge still reaches d
+	}
+	if got, want := graph.Unreachable([]string{"pkg:a", "pkg:b"}), []string{"github.com/x/y", "pkg:c", "pkg:d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unreachable(a, b) = %v, want %v", got, want)
+	}
+
+	sub, err := graph.Subgraph("pkg:c", 1, "in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range sub.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if want := []string{"pkg:a", "pkg:b", "pkg:c", "pkg:d"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Subgraph(c, 1, in) nodes = %v, want %v", ids, want)
+	}
+	if len(sub.Edges) != 6 {
+		t.Errorf("Subgraph(c, 1, in) has %d edges, want 6", len(sub.Edges))
+	}
+	if _, err := graph.Subgraph("pkg:nope", 1, "out"); err == nil {
+		t.Error("Subgraph of a missing node succeeded")
+	}
+}
+
+func TestDuplicateNodes(t *testing.T) {
+	// Graphs built by library users can repeat a node ID
+	graph := func() *Graph {
+		return &Graph{
+			Nodes: []Node{
+				{ID: "pkg:a", Type: "package"},
+				{ID: "pkg:a", Type: "package"},
+				{ID: "pkg:b", Type: "package"},
+				{ID: "x.org/y", Type: "external"},
+			},
+			Edges: []Edge{
+				{Source: "pkg:a", Target: "pkg:b"},
+				{Source: "pkg:b", Target: "x.org/y"},
+			},
+		}
+	}
+	if got := graph().Cycles(); len(got) != 0 {
+		t.Errorf("Cycles() = %v", got)
+	}
+	if got := graph().LongestChain(); got != 2 {
+		t.Errorf("LongestChain() = %d, want 2", got)
+	}
+	if got, want := graph().ImportChains("x.org/y", 0), [][]string{{"pkg:a", "pkg:b", "x.org/y"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImportChains() = %v, want %v", got, want)
+	}
+	if got, want := graph().Unreachable([]string{"pkg:b"}), []string{"x.org/y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unreachable(b) = %v, want %v", got, want)
+	}
+	if hot := graph().HotPaths(func(n *Node) bool { return n.Type == "external" }, 0); len(hot) != 2 || hot[0].Paths != 1 {
+		t.Errorf("HotPaths() = %+v", hot)
+	}
+	sub, err := graph().Subgraph("pkg:b", 1, "in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sub.Nodes) != 3 || len(sub.Edges) != 1 {
+		t.Errorf("Subgraph(b) = %+v", sub)
+	}
+	g := graph()
+	g.dropExternal()
+	if len(g.Nodes) != 3 || len(g.Edges) != 1 {
+		t.Errorf("dropExternal() = %+v", g)
+	}
+	g = graph()
+	g.scope("example.com/app", []string{"./b"})
+	if len(g.Nodes) != 2 || g.Nodes[0].ID != "pkg:b" {
+		t.Errorf("scope(./b) = %+v", g.Nodes)
+	}
+}
+
+// largeGraph returns a graph shaped like a big repository: n packages, each
+// importing a few packages further down and a few of n/10 external
+// modules, with some cycles.
+func largeGraph(n int) *Graph {
+	r := rand.New(rand.NewSource(1))
+	graph := &Graph{Nodes: []Node{{ID: "example.com/big", Label: "example.com/big", Type: "main"}}}
+	modules := n / 10
+	for i := 0; i < modules; i++ {
+		id := fmt.Sprintf("github.com/vendor%d/module%d", i%97, i)
+		graph.Nodes = append(graph.Nodes, Node{ID: id, Label: id, Type: "external", Version: "v1.2.3"})
+		graph.Edges = append(graph.Edges, Edge{Source: "example.com/big", Target: id})
+	}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("pkg:internal/area%d/package%d", i%50, i)
+		graph.Nodes = append(graph.Nodes, Node{ID: id, Label: id, Type: "package"})
+		for j := 0; j < 4 && i+1 < n; j++ {
+			target := i + 1 + r.Intn(min(n-i-1, 200))
+			graph.Edges = append(graph.Edges, Edge{Source: id, Target: fmt.Sprintf("pkg:internal/area%d/package%d", target%50, target)})
+		}
+		if i%100 == 99 {
+			back := i - 1 - r.Intn(50)
+			graph.Edges = append(graph.Edges, Edge{Source: id, Target: fmt.Sprintf("pkg:internal/area%d/package%d", back%50, back)})
+		}
+		for j := 0; j < 2 && modules > 0; j++ {
+			m := r.Intn(modules)
+			graph.Edges = append(graph.Edges, Edge{Source: id, Target: fmt.Sprintf("github.com/vendor%d/module%d", m%97, m)})
+		}
+	}
+	graph.Sort()
+	return graph
+}
+
+const benchmarkNodes = 100000
+
+func BenchmarkCycles(b *testing.B) {
+	graph := largeGraph(benchmarkNodes)
+	b.ReportAllocs()
+	for b.Loop() {
+		graph.Cycles()
+	}
+}
+
+func BenchmarkLongestChain(b *testing.B) {
+	graph := largeGraph(benchmarkNodes)
+	b.ReportAllocs()
+	for b.Loop() {
+		graph.LongestChain()
+	}
+}
+
+func BenchmarkSubgraph(b *testing.B) {
+	graph := largeGraph(benchmarkNodes)
+	root := graph.Nodes[len(graph.Nodes)/2].ID
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := graph.Subgraph(root, 3, "both"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkImportChains(b *testing.B) {
+	graph := largeGraph(benchmarkNodes)
+	b.ReportAllocs()
+	for b.Loop() {
+		graph.ImportChains("github.com/vendor0/module0", 100)
+	}
+}
+
+func BenchmarkUnreachable(b *testing.B) {
+	graph := largeGraph(benchmarkNodes)
+	removed := []string{"pkg:internal/area0/package0"}
+	b.ReportAllocs()
+	for b.Loop() {
+		graph.Unreachable(removed)
+	}
+}
@@ -0,0 +1,75 @@
+package depgraph
+
+import "sort"
+
+// Cycles returns the import cycles of the graph as strongly connected
+// components with more than one node, plus nodes importing themselves. Only
+// plain import edges are considered. Each cycle is sorted, and cycles are
+// ordered by their first node.
+func (g *Graph) Cycles() [][]string {
+	c := g.compact(func(e *Edge, _ *Node) bool { return e.Kind == "" })
+	selfLoops := make([]bool, len(c.ids))
+	for i, s := range c.from {
+		if s == c.to[i] {
+			selfLoops[s] = true
+		}
+	}
+
+	// Tarjan's strongly connected components
+	index := make([]int32, len(c.ids))
+	low := make([]int32, len(c.ids))
+	for i := range index {
+		index[i] = -1
+	}
+	onStack := make([]bool, len(c.ids))
+	var stack []int32
+	var cycles [][]string
+	visited := int32(0)
+
+	var visit func(id int32)
+	visit = func(id int32) {
+		index[id] = visited
+		low[id] = visited
+		visited++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range c.out.of(id) {
+			if next == id {
+				continue
+			}
+			if index[next] < 0 {
+				visit(next)
+				low[id] = min(low[id], low[next])
+			} else if onStack[next] {
+				low[id] = min(low[id], index[next])
+			}
+		}
+
+		if low[id] == index[id] {
+			var scc []int32
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				scc = append(scc, top)
+				if top == id {
+					break
+				}
+			}
+			if len(scc) > 1 || selfLoops[id] {
+				cycle := c.names(scc)
+				sort.Strings(cycle)
+				cycles = append(cycles, cycle)
+			}
+		}
+	}
+
+	for _, id := range c.at {
+		if index[id] < 0 {
+			visit(id)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
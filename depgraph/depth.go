@@ -0,0 +1,35 @@
+package depgraph
+
+// LongestChain returns the number of edges in the longest import chain of
+// the graph. Edges closing a cycle are ignored.
+func (g *Graph) LongestChain() int {
+	c := g.compact(func(e *Edge, _ *Node) bool { return e.Kind == "" })
+
+	memo := make([]int32, len(c.ids))
+	for i := range memo {
+		memo[i] = -1
+	}
+	visiting := make([]bool, len(c.ids))
+	var chain func(id int32) int32
+	chain = func(id int32) int32 {
+		if memo[id] >= 0 {
+			return memo[id]
+		}
+		visiting[id] = true
+		longest := int32(0)
+		for _, next := range c.out.of(id) {
+			if !visiting[next] {
+				longest = max(longest, chain(next)+1)
+			}
+		}
+		visiting[id] = false
+		memo[id] = longest
+		return longest
+	}
+
+	longest := int32(0)
+	for _, id := range c.at {
+		longest = max(longest, chain(id))
+	}
+	return int(longest)
+}
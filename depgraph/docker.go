@@ -0,0 +1,344 @@
+package depgraph
+
+import (
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// dockerfile is what the analysis needs from a Dockerfile: its build stages
+// in order, the last one being the image that is shipped.
+type dockerfile struct {
+	stages []*dockerStage
+}
+
+type dockerStage struct {
+	name    string // AS name, or the stage index
+	from    string // base image or earlier stage
+	workdir string
+	builds  []goBuild
+	copies  []dockerCopy
+}
+
+// goBuild is a `go build` or `go install` in a RUN instruction.
+type goBuild struct {
+	output string // absolute path of the binary in the stage
+	pkg    string // package argument, e.g. ./cmd/api
+}
+
+// dockerCopy is a source of a COPY or ADD instruction.
+type dockerCopy struct {
+	from string // stage or image for --from, empty for the build context
+	src  string
+	dst  string
+}
+
+// IsDockerfile reports whether a file name is a Dockerfile, including
+// variants like Dockerfile.dev and api.Dockerfile.
+func IsDockerfile(base string) bool {
+	return base == "Dockerfile" || strings.HasPrefix(base, "Dockerfile.") || strings.HasSuffix(base, ".Dockerfile")
+}
+
+// parseDockerfile extracts stages, Go builds and copies from a Dockerfile.
+// Global ARG defaults are substituted in FROM lines; everything else is
+// taken literally.
+func parseDockerfile(src []byte) *dockerfile {
+	df := &dockerfile{}
+	args := make(map[string]string)
+	var stage *dockerStage
+
+	for _, line := range dockerInstructions(string(src)) {
+		instruction, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch strings.ToUpper(instruction) {
+		case "ARG":
+			if stage == nil {
+				name, value, _ := strings.Cut(rest, "=")
+				args[name] = strings.Trim(value, `"`)
+			}
+		case "FROM":
+			fields := dockerFlags(strings.Fields(rest), nil)
+			if len(fields) == 0 {
+				continue
+			}
+			stage = &dockerStage{name: strconv.Itoa(len(df.stages)), from: expandArgs(fields[0], args), workdir: "/"}
+			if len(fields) == 3 && strings.EqualFold(fields[1], "as") {
+				stage.name = fields[2]
+			}
+			df.stages = append(df.stages, stage)
+		case "WORKDIR":
+			if stage != nil {
+				stage.workdir = path.Join(stage.workdir, rest)
+			}
+		case "RUN":
+			if stage != nil {
+				stage.builds = append(stage.builds, goBuilds(rest, stage.workdir)...)
+			}
+		case "COPY", "ADD":
+			if stage == nil {
+				continue
+			}
+			flags := make(map[string]string)
+			fields := execForm(rest)
+			if fields == nil {
+				fields = dockerFlags(strings.Fields(rest), flags)
+			}
+			if len(fields) < 2 {
+				continue
+			}
+			dst := fields[len(fields)-1]
+			if !path.IsAbs(dst) {
+				dir := strings.HasSuffix(dst, "/")
+				if dst = path.Join(stage.workdir, dst); dir {
+					dst += "/"
+				}
+			}
+			for _, src := range fields[:len(fields)-1] {
+				stage.copies = append(stage.copies, dockerCopy{from: flags["from"], src: src, dst: dst})
+			}
+		}
+	}
+	return df
+}
+
+// dockerInstructions joins continuation lines and drops comments.
+func dockerInstructions(src string) []string {
+	var lines []string
+	var current strings.Builder
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if cont, ok := strings.CutSuffix(trimmed, `\`); ok {
+			current.WriteString(cont + " ")
+			continue
+		}
+		current.WriteString(trimmed)
+		if s := strings.TrimSpace(current.String()); s != "" {
+			lines = append(lines, s)
+		}
+		current.Reset()
+	}
+	return lines
+}
+
+// dockerFlags strips leading --name=value flags from fields, recording them
+// in flags when it is not nil.
+func dockerFlags(fields []string, flags map[string]string) []string {
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		name, value, _ := strings.Cut(strings.TrimPrefix(fields[0], "--"), "=")
+		if flags != nil {
+			flags[name] = value
+		}
+		fields = fields[1:]
+	}
+	return fields
+}
+
+// execForm decodes the JSON array form of an instruction, returning nil for
+// the shell form.
+func execForm(s string) []string {
+	var fields []string
+	if !strings.HasPrefix(s, "[") || json.Unmarshal([]byte(s), &fields) != nil {
+		return nil
+	}
+	return fields
+}
+
+// expandArgs substitutes $NAME and ${NAME} with ARG defaults.
+func expandArgs(s string, args map[string]string) string {
+	for name, value := range args {
+		s = strings.ReplaceAll(s, "${"+name+"}", value)
+		s = strings.ReplaceAll(s, "$"+name, value)
+	}
+	return s
+}
+
+// goBuilds finds the go build and go install commands in a RUN instruction.
+func goBuilds(run, workdir string) []goBuild {
+	var commands [][]string
+	if fields := execForm(run); fields != nil {
+		commands = append(commands, fields)
+	} else {
+		// Shell form is split into commands at the usual separators
+		for _, cmd := range strings.FieldsFunc(run, func(r rune) bool { return r == '&' || r == ';' || r == '|' }) {
+			commands = append(commands, strings.Fields(cmd))
+		}
+	}
+
+	var builds []goBuild
+	for _, words := range commands {
+		i := 0
+		for i < len(words) && strings.Contains(words[i], "=") {
+			i++ // environment assignments like CGO_ENABLED=0
+		}
+		if len(words) < i+2 || path.Base(words[i]) != "go" || (words[i+1] != "build" && words[i+1] != "install") {
+			continue
+		}
+		var output string
+		var pkgs []string
+		for j := i + 2; j < len(words); j++ {
+			switch w := words[j]; {
+			case w == "-o" && j+1 < len(words):
+				output = words[j+1]
+				j++
+			case strings.HasPrefix(w, "-o="):
+				output = strings.TrimPrefix(w, "-o=")
+			case strings.HasPrefix(w, "-"):
+				// Other flags; those taking a value use the -flag=value form in practice
+			case !strings.HasSuffix(w, "..."):
+				pkgs = append(pkgs, w)
+			}
+		}
+		if len(pkgs) == 0 {
+			pkgs = []string{"."}
+		}
+		for _, pkg := range pkgs {
+			out := output
+			if out == "" || strings.HasSuffix(out, "/") || len(pkgs) > 1 {
+				out = path.Join(out, path.Base(pkg))
+			}
+			if !path.IsAbs(out) {
+				out = path.Join(workdir, out)
+			}
+			builds = append(builds, goBuild{output: out, pkg: pkg})
+		}
+	}
+	return builds
+}
+
+// addDockerNodes adds a node per Dockerfile for the image it builds, with
+// edges to its base images and to the artifacts its final stage copies.
+// Artifacts are linked to the Go packages they were built from, found through
+// a go build in the stage they are copied from, or else a package directory
+// named like the file.
+func addDockerNodes(graph *Graph, nodeMap map[string]*Node, mainModule string, dockerfiles map[string]*dockerfile) {
+	byBase := packagesByBase(graph, mainModule)
+	for _, name := range sortedKeys(dockerfiles) {
+		df := dockerfiles[name]
+		if len(df.stages) == 0 {
+			continue
+		}
+		imageID := "docker:" + name
+		addNode(graph, nodeMap, imageID, name, "image", 1)
+
+		stages := make(map[string]*dockerStage)
+		for _, stage := range df.stages {
+			stages[stage.name] = stage
+		}
+		final := df.stages[len(df.stages)-1]
+		for _, stage := range df.stages {
+			if stages[stage.from] != nil || stage.from == "scratch" {
+				continue
+			}
+			kind := "build-image"
+			if stage == baseStage(final, stages) {
+				kind = "base-image"
+			}
+			addEdgeKind(graph, imageID, addBaseImage(graph, nodeMap, stage.from), kind)
+		}
+
+		// The final stage ships whatever it and the stages it builds on produced
+		seen := make(map[*dockerStage]bool)
+		for stage := final; stage != nil && !seen[stage]; stage = stages[stage.from] {
+			seen[stage] = true
+			for _, c := range stage.copies {
+				if c.from == "" && len(stage.builds) > 0 {
+					continue // sources for the build, not artifacts
+				}
+				dst := c.dst
+				if strings.HasSuffix(dst, "/") {
+					dst += path.Base(c.src)
+				}
+				artifactID := "artifact:" + name + ":" + dst
+				addNode(graph, nodeMap, artifactID, path.Base(c.src), "artifact", 2)
+				graph.Annotate(artifactID, "destination", dst)
+				addEdgeKind(graph, imageID, artifactID, "copies")
+
+				if c.from != "" && stages[c.from] == nil {
+					addEdgeKind(graph, artifactID, addBaseImage(graph, nodeMap, c.from), "copied-from")
+					continue
+				}
+				if pkgID := builtFrom(stages[c.from], c.src, mainModule, byBase); nodeMap[pkgID] != nil {
+					addEdgeKind(graph, artifactID, pkgID, "built-from")
+				}
+			}
+			for _, build := range stage.builds {
+				if pkgID := buildPackage(build.pkg, mainModule); nodeMap[pkgID] != nil {
+					addEdgeKind(graph, imageID, pkgID, "built-from")
+				}
+			}
+		}
+	}
+}
+
+// packagesByBase maps directory base names, the names binaries get by
+// default, to package node IDs.
+func packagesByBase(graph *Graph, mainModule string) map[string][]string {
+	byBase := make(map[string][]string)
+	for _, node := range graph.Nodes {
+		if node.Type == "package" {
+			dir, _ := PackageDir(node.ID)
+			base := path.Base(dir)
+			if dir == "." {
+				base = path.Base(mainModule)
+			}
+			byBase[base] = append(byBase[base], node.ID)
+		}
+	}
+	return byBase
+}
+
+// baseStage follows a stage's FROM through earlier stages to the one built
+// on an image.
+func baseStage(stage *dockerStage, stages map[string]*dockerStage) *dockerStage {
+	for hops := 0; stages[stage.from] != nil && hops < len(stages); hops++ {
+		stage = stages[stage.from]
+	}
+	return stage
+}
+
+// addBaseImage adds a node for an image reference, with the tag as version.
+func addBaseImage(graph *Graph, nodeMap map[string]*Node, ref string) string {
+	id := "image:" + ref
+	if _, exists := nodeMap[id]; !exists {
+		name, tag := ref, ""
+		if i := strings.LastIndexByte(ref, ':'); i > strings.LastIndexByte(ref, '/') {
+			name, tag = ref[:i], ref[i+1:]
+		}
+		name, _, _ = strings.Cut(name, "@")
+		addNode(graph, nodeMap, id, name, "image", 2)
+		graph.Nodes[len(graph.Nodes)-1].Version = tag
+	}
+	return id
+}
+
+// builtFrom finds the package a copied file was built from: the go build in
+// the source stage writing it, or else the package directory named like it.
+func builtFrom(stage *dockerStage, src, mainModule string, byBase map[string][]string) string {
+	if stage != nil {
+		for _, build := range stage.builds {
+			if build.output == path.Join("/", src) || build.output == path.Join(stage.workdir, src) {
+				return buildPackage(build.pkg, mainModule)
+			}
+		}
+	}
+	if ids := byBase[path.Base(src)]; len(ids) == 1 {
+		return ids[0]
+	}
+	return ""
+}
+
+// buildPackage maps a go build package argument to a package node ID,
+// assuming the module root is the build context.
+func buildPackage(pkg, mainModule string) string {
+	if rel, ok := strings.CutPrefix(pkg, mainModule+"/"); ok && mainModule != "" {
+		pkg = rel
+	}
+	if pkg == mainModule {
+		pkg = "."
+	}
+	return PackageID(pkg)
+}
@@ -0,0 +1,46 @@
+package depgraph
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestDockerNodes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":           {Data: []byte("module example.com/app\n\ngo 1.24\n")},
+		"cmd/api/main.go":  {Data: []byte("package main\n")},
+		"cmd/tool/main.go": {Data: []byte("package main\n")},
+		"Dockerfile": {Data: []byte(`ARG GO=1.24
+FROM golang:${GO} AS build
+WORKDIR /src
+COPY . .
+RUN CGO_ENABLED=0 go build \
+    -o /out/api ./cmd/api
+FROM build AS test
+FROM gcr.io/distroless/static
+COPY --from=build /out/api /api
+COPY tool /usr/bin/
+`)},
+	}
+	graph, err := Analyze(fsys, Options{Docker: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Edge{
+		{Source: "docker:Dockerfile", Target: "image:golang:1.24", Kind: "build-image"},
+		{Source: "docker:Dockerfile", Target: "image:gcr.io/distroless/static", Kind: "base-image"},
+		{Source: "docker:Dockerfile", Target: "artifact:Dockerfile:/api", Kind: "copies"},
+		{Source: "artifact:Dockerfile:/api", Target: "pkg:cmd/api", Kind: "built-from"},
+		{Source: "artifact:Dockerfile:/usr/bin/tool", Target: "pkg:cmd/tool", Kind: "built-from"},
+	}
+	for _, w := range want {
+		found := false
+		for _, e := range graph.Edges {
+			found = found || e.Source == w.Source && e.Target == w.Target && e.Kind == w.Kind
+		}
+		if !found {
+			t.Errorf("missing edge %+v in %+v", w, graph.Edges)
+		}
+	}
+}
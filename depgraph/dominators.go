@@ -0,0 +1,93 @@
+package depgraph
+
+import "sort"
+
+// Dominators computes the immediate dominator of every node reachable from
+// root in the directed graph given by succ: the last node every path from
+// root must pass through. Removing a node makes everything it dominates
+// unreachable. The root maps to itself.
+func Dominators(root string, succ map[string][]string) map[string]string {
+	// Number nodes in reverse postorder from root
+	var order []string
+	visited := map[string]bool{root: true}
+	var dfs func(id string)
+	dfs = func(id string) {
+		next := append([]string(nil), succ[id]...)
+		sort.Strings(next)
+		for _, n := range next {
+			if !visited[n] {
+				visited[n] = true
+				dfs(n)
+			}
+		}
+		order = append(order, id)
+	}
+	dfs(root)
+	index := make(map[string]int, len(order))
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	for i, id := range order {
+		index[id] = i
+	}
+	pred := make(map[string][]string)
+	for from, tos := range succ {
+		if !visited[from] {
+			continue
+		}
+		for _, to := range tos {
+			pred[to] = append(pred[to], from)
+		}
+	}
+
+	// Cooper, Harvey and Kennedy's iterative algorithm
+	idom := map[string]string{root: root}
+	intersect := func(a, b string) string {
+		for a != b {
+			for index[a] > index[b] {
+				a = idom[a]
+			}
+			for index[b] > index[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, id := range order[1:] {
+			var dom string
+			for _, p := range pred[id] {
+				if _, ok := idom[p]; !ok {
+					continue
+				}
+				if dom == "" {
+					dom = p
+				} else {
+					dom = intersect(p, dom)
+				}
+			}
+			if idom[id] != dom {
+				idom[id] = dom
+				changed = true
+			}
+		}
+	}
+	return idom
+}
+
+// Dominated returns the nodes an immediate dominator map says are dominated
+// by id, excluding id itself, sorted.
+func Dominated(idom map[string]string, id string) []string {
+	var dominated []string
+	for n := range idom {
+		for d := n; d != idom[d]; d = idom[d] {
+			if idom[d] == id {
+				dominated = append(dominated, n)
+				break
+			}
+		}
+	}
+	sort.Strings(dominated)
+	return dominated
+}
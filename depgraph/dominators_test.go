@@ -0,0 +1,31 @@
+package depgraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDominators(t *testing.T) {
+	// main requires a and b; c is only reachable through a, d through both
+	succ := map[string][]string{
+		"main": {"a", "b"},
+		"a":    {"c", "d"},
+		"b":    {"d"},
+		"c":    {"e"},
+		"e":    {"c"},
+	}
+	idom := Dominators("main", succ)
+	want := map[string]string{"main": "main", "a": "main", "b": "main", "c": "a", "d": "main", "e": "c"}
+	for id, dom := range want {
+		if idom[id] != dom {
+			t.Errorf("idom[%s] = %q, want %q", id, idom[id], dom)
+		}
+	}
+
+	if got, want := Dominated(idom, "a"), []string{"c", "e"}; !slices.Equal(got, want) {
+		t.Errorf("Dominated(a) = %v, want %v", got, want)
+	}
+	if got := Dominated(idom, "b"); len(got) != 0 {
+		t.Errorf("Dominated(b) = %v, want none", got)
+	}
+}
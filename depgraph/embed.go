@@ -0,0 +1,109 @@
+package depgraph
+
+import (
+	"fmt"
+	"go/ast"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// embedPatterns returns the patterns of all //go:embed directives in file.
+// The file must have been parsed with comments.
+func embedPatterns(file *ast.File) []string {
+	var patterns []string
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			args, ok := strings.CutPrefix(c.Text, "//go:embed")
+			if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+				continue
+			}
+			patterns = append(patterns, splitEmbedArgs(args)...)
+		}
+	}
+	return patterns
+}
+
+// splitEmbedArgs splits a directive's arguments, honoring Go string quoting.
+func splitEmbedArgs(args string) []string {
+	var fields []string
+	for args = strings.TrimSpace(args); args != ""; args = strings.TrimSpace(args) {
+		if args[0] == '"' || args[0] == '`' {
+			end := strings.IndexByte(args[1:], args[0])
+			if end < 0 {
+				break
+			}
+			if s, err := strconv.Unquote(args[:end+2]); err == nil {
+				fields = append(fields, s)
+			}
+			args = args[end+2:]
+			continue
+		}
+		end := strings.IndexAny(args, " \t")
+		if end < 0 {
+			end = len(args)
+		}
+		fields = append(fields, args[:end])
+		args = args[end:]
+	}
+	return fields
+}
+
+// embedSize returns the number of bytes a pattern embeds from dir. Like the go
+// tool, files starting with . or _ inside directories are skipped unless the
+// pattern uses the all: prefix.
+func embedSize(fsys fs.FS, dir, pattern string) int64 {
+	pattern, all := strings.CutPrefix(pattern, "all:")
+	matches, _ := fs.Glob(fsys, path.Join(dir, pattern))
+
+	var size int64
+	for _, match := range matches {
+		fs.WalkDir(fsys, match, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			base := d.Name()
+			if name != match && !all && (strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_")) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+				size += info.Size()
+			}
+			return nil
+		})
+	}
+	return size
+}
+
+// embed is a //go:embed pattern and the number of bytes it embeds.
+type embed struct {
+	pattern string
+	size    int64
+}
+
+// addEmbedNodes adds an asset node per embed pattern of a package.
+func addEmbedNodes(graph *Graph, nodeMap map[string]*Node, packageID, relPath string, embeds []embed) {
+	for _, e := range embeds {
+		assetID := "embed:" + relPath + ":" + e.pattern
+		if _, exists := nodeMap[assetID]; !exists {
+			addNode(graph, nodeMap, assetID, e.pattern+" ("+FormatBytes(e.size)+")", "asset", 1)
+			graph.Nodes[len(graph.Nodes)-1].Size = e.size
+		}
+		addEdgeKind(graph, packageID, assetID, "embeds")
+	}
+}
+
+// FormatBytes renders a byte count for labels, e.g. "12.3 KB".
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
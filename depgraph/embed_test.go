@@ -0,0 +1,122 @@
+package depgraph
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbedPatterns(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"single pattern", "//go:embed static\nvar fs embed.FS", []string{"static"}},
+		{"several patterns", "//go:embed a.txt b/*.html\nvar fs embed.FS", []string{"a.txt", "b/*.html"}},
+		{"several directives", "//go:embed a.txt\n//go:embed\tb.txt\nvar fs embed.FS", []string{"a.txt", "b.txt"}},
+		{"quoted patterns", "//go:embed \"with space.txt\" `raw name.txt` plain\nvar fs embed.FS", []string{"with space.txt", "raw name.txt", "plain"}},
+		{"all prefix", "//go:embed all:web\nvar fs embed.FS", []string{"all:web"}},
+		{"unterminated quote", "//go:embed ok \"broken\nvar fs embed.FS", []string{"ok"}},
+		{"other directive", "//go:embedded x\n//go:generate y\nvar fs embed.FS", nil},
+		{"prose", "// go:embed x is not a directive\nvar fs embed.FS", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\n\nimport \"embed\"\n\n"+tt.src+"\n", parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := embedPatterns(file); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("embedPatterns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedSize(t *testing.T) {
+	fsys := fstest.MapFS{
+		"web/index.html":          {Data: make([]byte, 100)},
+		"web/app.js":              {Data: make([]byte, 20)},
+		"web/.hidden":             {Data: make([]byte, 1000)},
+		"web/_draft/page.html":    {Data: make([]byte, 3000)},
+		"web/img/logo.png":        {Data: make([]byte, 400)},
+		"web/img/.DS_Store":       {Data: make([]byte, 5000)},
+		"web/templates/a.tmpl":    {Data: make([]byte, 7)},
+		"web/templates/b.tmpl":    {Data: make([]byte, 8)},
+		"web/templates/notes.txt": {Data: make([]byte, 9)},
+	}
+	for pattern, want := range map[string]int64{
+		"index.html":          100,
+		"templates/*.tmpl":    15,
+		"*.js":                20,
+		"img":                 400,
+		"all:img":             5400,
+		".hidden":             1000, // named explicitly
+		"all:templates":       24,
+		"missing":             0,
+		"*.html":              100,
+		"templates/[ab].tmpl": 15,
+	} {
+		if got := embedSize(fsys, "web", pattern); got != want {
+			t.Errorf("embedSize(%q) = %d, want %d", pattern, got, want)
+		}
+	}
+	// The directory itself skips hidden files below it, all: keeps them
+	if got, want := embedSize(fsys, ".", "web"), int64(100+20+400+7+8+9); got != want {
+		t.Errorf("embedSize(web) = %d, want %d", got, want)
+	}
+	if got, want := embedSize(fsys, ".", "all:web"), int64(100+20+1000+3000+400+5000+7+8+9); got != want {
+		t.Errorf("embedSize(all:web) = %d, want %d", got, want)
+	}
+}
+
+func TestEmbedNodes(t *testing.T) {
+	graph, err := Analyze(fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/app\n\ngo 1.24\n")},
+		"main.go": {Data: []byte(`package main
+
+import _ "example.com/app/web"
+`)},
+		"web/web.go": {Data: []byte(`package web
+
+import "embed"
+
+//go:embed static/*.css
+var styles embed.FS
+
+//go:embed version.txt
+var version string
+`)},
+		"web/static/site.css": {Data: make([]byte, 2048)},
+		"web/static/site.js":  {Data: make([]byte, 10)},
+		"web/version.txt":     {Data: []byte("v1\n")},
+	}, Options{Embeds: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]struct {
+		label string
+		size  int64
+	}{
+		"embed:web:static/*.css": {"static/*.css (2.0 KB)", 2048},
+		"embed:web:version.txt":  {"version.txt (3 B)", 3},
+	} {
+		n := graph.Node(id)
+		if n == nil {
+			t.Errorf("no node %s in %+v", id, graph.Nodes)
+			continue
+		}
+		if n.Type != "asset" || n.Label != want.label || n.Size != want.size {
+			t.Errorf("node %s = %+v, want label %q and size %d", id, n, want.label, want.size)
+		}
+		found := false
+		for _, e := range graph.Edges {
+			found = found || (e.Source == "pkg:web" && e.Target == id && e.Kind == "embeds")
+		}
+		if !found {
+			t.Errorf("no embeds edge from pkg:web to %s", id)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package depgraph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors of the analysis, to be matched with errors.Is. Analyze fails with
+// ErrNoGoMod when the tree is not a module and with a *ParseError for
+// go.mod when its go.mod is corrupt. Go files that fail to parse and imports
+// no requirement provides do not fail the analysis: the *ParseError or
+// *ModResolveError becomes a warning of the importing node.
+var (
+	ErrNoGoMod    = errors.New("no go.mod, not a Go module")
+	ErrParse      = errors.New("parse error")
+	ErrModResolve = errors.New("no required module provides the package")
+)
+
+// ParseError is a file that could not be read or parsed. It matches
+// ErrParse and unwraps to the underlying error.
+type ParseError struct {
+	File string // slash separated and relative to the root
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	// Parser errors already start with the file name and position
+	if msg := e.Err.Error(); strings.HasPrefix(msg, e.File+":") {
+		return msg
+	}
+	return e.File + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+func (e *ParseError) Is(target error) bool { return target == ErrParse }
+
+// ModResolveError is an import no module go.mod requires provides. It
+// matches ErrModResolve.
+type ModResolveError struct {
+	Import string
+}
+
+func (e *ModResolveError) Error() string {
+	return fmt.Sprintf("no required module provides package %s", e.Import)
+}
+
+func (e *ModResolveError) Is(target error) bool { return target == ErrModResolve }
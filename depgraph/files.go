@@ -0,0 +1,81 @@
+package depgraph
+
+import (
+	"go/ast"
+	"path"
+)
+
+// fileSymbols returns the package-level names a Go file declares and the
+// unqualified names it refers to. Field and method selections are skipped,
+// since they never name another file's declaration directly.
+func fileSymbols(file *ast.File) (decls, uses []string) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name != "init" && d.Name.Name != "_" {
+				decls = append(decls, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					decls = append(decls, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if name.Name != "_" {
+							decls = append(decls, name.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && !seen[id.Name] {
+					seen[id.Name] = true
+					uses = append(uses, id.Name)
+				}
+				return true
+			})
+			return false
+		case *ast.Ident:
+			if !seen[n.Name] {
+				seen[n.Name] = true
+				uses = append(uses, n.Name)
+			}
+		}
+		return true
+	})
+	return decls, uses
+}
+
+// addFileEdges adds "uses" edges between the nodes of Go files referring to
+// what another file of the same package declares. The match is by name
+// only, so a local shadowing a package-level name may add a spurious edge.
+func addFileEdges(graph *Graph, files map[string]*fileInfo) {
+	declaredIn := make(map[string]string) // directory, package and name -> declaring file
+	for _, name := range sortedKeys(files) {
+		for _, decl := range files[name].decls {
+			key := path.Dir(name) + " " + files[name].pkg + " " + decl
+			if _, dup := declaredIn[key]; !dup {
+				declaredIn[key] = name
+			}
+		}
+	}
+	for _, name := range sortedKeys(files) {
+		for _, use := range files[name].uses {
+			// External test packages share the directory but not the scope
+			target, ok := declaredIn[path.Dir(name)+" "+files[name].pkg+" "+use]
+			if ok && target != name {
+				addEdgeKind(graph, "file:"+name, "file:"+target, "uses")
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package depgraph
+
+import (
+	"go/ast"
+	"path"
+	"strings"
+)
+
+// protocBuiltins are protoc outputs that need no plugin.
+var protocBuiltins = map[string]bool{
+	"cpp": true, "csharp": true, "java": true, "js": true, "kotlin": true,
+	"objc": true, "php": true, "pyi": true, "python": true, "ruby": true,
+}
+
+// generateTools returns the tools the //go:generate directives of file run.
+// The file must have been parsed with comments.
+func generateTools(file *ast.File) []string {
+	var tools []string
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			args, ok := strings.CutPrefix(c.Text, "//go:generate")
+			if !ok || args == "" || (args[0] != ' ' && args[0] != '\t') {
+				continue
+			}
+			tools = append(tools, generatorTools(splitEmbedArgs(args))...)
+		}
+	}
+	return tools
+}
+
+// generatorTools names the tools a generator command runs: the command
+// itself, the package of `go run` and `go tool`, or protoc and its plugins.
+func generatorTools(words []string) []string {
+	for len(words) > 0 && strings.Contains(words[0], "=") {
+		words = words[1:] // environment assignments
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	switch name := path.Base(words[0]); name {
+	case "go":
+		if len(words) < 3 || (words[1] != "run" && words[1] != "tool") {
+			return []string{name}
+		}
+		for _, w := range words[2:] {
+			if !strings.HasPrefix(w, "-") {
+				tool, _, _ := strings.Cut(w, "@")
+				return []string{tool}
+			}
+		}
+		return nil
+	case "protoc":
+		tools := []string{name}
+		for _, w := range words[1:] {
+			flag, _, _ := strings.Cut(strings.TrimPrefix(w, "--"), "=")
+			if lang, ok := strings.CutSuffix(flag, "_out"); ok && !protocBuiltins[lang] {
+				tools = append(tools, "protoc-gen-"+lang)
+			}
+		}
+		return tools
+	default:
+		return []string{name}
+	}
+}
+
+// addToolNodes adds a node per code generator a package runs. Tools named
+// by their base name resolve through the go.mod tool directives, and tools
+// from required modules are connected to the module.
+func addToolNodes(graph *Graph, nodeMap map[string]*Node, packageID string, tools []string, modTools map[string]string, requireModule func(importPath string) string) {
+	for _, tool := range tools {
+		if full, ok := modTools[tool]; ok {
+			tool = full
+		}
+		toolID := "tool:" + tool
+		if _, exists := nodeMap[toolID]; !exists {
+			addNode(graph, nodeMap, toolID, path.Base(tool), "tool", 1)
+			if module := requireModule(tool); module != "" {
+				addEdge(graph, toolID, module)
+			}
+		}
+		addEdgeKind(graph, packageID, toolID, "generate")
+	}
+}
@@ -0,0 +1,166 @@
+package depgraph
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+)
+
+// genericRef is a reference from a package to a (possibly) generic symbol
+// declared in another package, e.g. lo.Map[int, string] or list.New(x).
+type genericRef struct {
+	source   string // package node ID of the user
+	target   string // node ID of the package that declares the symbol
+	name     string // symbol name
+	explicit bool   // type arguments spelled out, so certainly an instantiation
+}
+
+// predeclaredTypes are identifiers that can only appear as a type argument.
+var predeclaredTypes = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true,
+	"complex128": true, "error": true, "float32": true, "float64": true, "int": true,
+	"int8": true, "int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true,
+	"uint64": true, "uintptr": true,
+}
+
+// importName returns the identifier a file uses to refer to an import.
+func importName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	importPath := strings.Trim(imp.Path.Value, `"`)
+	name := path.Base(importPath)
+	// Major version suffixes (foo/v2) are not part of the package name
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// genericDecls returns the names of generic functions and types declared in file.
+func genericDecls(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Type.TypeParams != nil {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.TypeParams != nil {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// collectGenericRefs finds qualified references in file that may instantiate
+// generics. The refs have no source yet and their target is the import path
+// the qualifier refers to.
+func collectGenericRefs(file *ast.File) []genericRef {
+	byName := make(map[string]string)
+	for _, imp := range file.Imports {
+		byName[importName(imp)] = strings.Trim(imp.Path.Value, `"`)
+	}
+	if len(byName) == 0 {
+		return nil
+	}
+
+	var refs []genericRef
+	qualified := func(expr ast.Expr) (string, string, bool) {
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			return "", "", false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return "", "", false
+		}
+		target, ok := byName[pkg.Name]
+		return target, sel.Sel.Name, ok
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IndexListExpr:
+			if target, name, ok := qualified(x.X); ok {
+				refs = append(refs, genericRef{target: target, name: name, explicit: true})
+			}
+		case *ast.IndexExpr:
+			if target, name, ok := qualified(x.X); ok && isTypeExpr(x.Index) {
+				refs = append(refs, genericRef{target: target, name: name, explicit: true})
+			}
+		case *ast.CallExpr:
+			// Type arguments may be inferred; resolved against declarations later
+			if target, name, ok := qualified(x.Fun); ok {
+				refs = append(refs, genericRef{target: target, name: name})
+			}
+		}
+		return true
+	})
+	return refs
+}
+
+// isTypeExpr reports whether expr can only be a type, which distinguishes
+// pkg.Generic[T] from indexing a package-level slice or map.
+func isTypeExpr(expr ast.Expr) bool {
+	switch x := expr.(type) {
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType,
+		*ast.InterfaceType, *ast.StructType, *ast.IndexListExpr:
+		return true
+	case *ast.Ident:
+		return predeclaredTypes[x.Name]
+	case *ast.StarExpr:
+		return isTypeExpr(x.X)
+	case *ast.IndexExpr:
+		return isTypeExpr(x.Index)
+	case *ast.SelectorExpr:
+		// Exported qualified identifiers are overwhelmingly types in index position
+		return ast.IsExported(x.Sel.Name)
+	}
+	return false
+}
+
+// addInstantiationEdges connects packages to the packages defining the generics
+// they instantiate. Inferred calls only count when the callee is known to be
+// generic, which is only the case for packages in the analyzed tree.
+func addInstantiationEdges(graph *Graph, refs []genericRef, decls map[string]map[string]bool) {
+	symbols := make(map[[2]string]map[string]bool)
+	var order [][2]string
+	for _, ref := range refs {
+		if !ref.explicit && !decls[ref.target][ref.name] {
+			continue
+		}
+		key := [2]string{ref.source, ref.target}
+		if symbols[key] == nil {
+			symbols[key] = make(map[string]bool)
+			order = append(order, key)
+		}
+		symbols[key][ref.name] = true
+	}
+
+	for _, key := range order {
+		var names []string
+		for name := range symbols[key] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		addEdgeKind(graph, key[0], key[1], "instantiates")
+		for i := range graph.Edges {
+			edge := &graph.Edges[i]
+			if edge.Source == key[0] && edge.Target == key[1] && edge.Kind == "instantiates" {
+				edge.Symbols = names
+			}
+		}
+	}
+}
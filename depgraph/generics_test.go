@@ -0,0 +1,130 @@
+package depgraph
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCollectGenericRefs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		body string
+		want []genericRef
+	}{
+		{"two type arguments", "var _ = lo.Map[int, string]", []genericRef{
+			{target: "github.com/samber/lo", name: "Map", explicit: true},
+		}},
+		{"one type argument", "var _ lo.Tuple[string]", []genericRef{
+			{target: "github.com/samber/lo", name: "Tuple", explicit: true},
+		}},
+		{"pointer type argument", "var _ = lo.Empty[*int]()", []genericRef{
+			{target: "github.com/samber/lo", name: "Empty", explicit: true},
+		}},
+		{"qualified type argument", "var _ = lo.Empty[x.Config]", []genericRef{
+			{target: "github.com/samber/lo", name: "Empty", explicit: true},
+		}},
+		{"nested instantiation", "var _ = lo.Empty[lo.Tuple[int]]", []genericRef{
+			{target: "github.com/samber/lo", name: "Empty", explicit: true},
+			{target: "github.com/samber/lo", name: "Tuple", explicit: true},
+		}},
+		{"inferred call", "var _ = lo.Uniq(nil)", []genericRef{
+			{target: "github.com/samber/lo", name: "Uniq"},
+		}},
+		{"indexed variable", "var _ = lo.Names[i]", nil},
+		{"renamed import", "var _ = v2.Pair[int, int]{}", []genericRef{
+			{target: "example.com/pair/v2", name: "Pair", explicit: true},
+		}},
+		{"unqualified", "var _ = Map[int, string]", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			src := `package p
+
+import (
+	"github.com/samber/lo"
+	v2 "example.com/pair/v2"
+	"example.com/x"
+)
+
+var i int
+` + tt.body + "\n"
+			file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, parser.SkipObjectResolution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := collectGenericRefs(file); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectGenericRefs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportName(t *testing.T) {
+	for importPath, want := range map[string]string{
+		"github.com/samber/lo":        "lo",
+		"github.com/foo/bar/v2":       "bar",
+		"github.com/mattn/go-isatty":  "isatty",
+		"gopkg.in/some-pkg":           "some_pkg",
+		"github.com/jackc/pgx/v5/pgx": "pgx",
+	} {
+		file, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\nimport \""+importPath+"\"\n", parser.ImportsOnly)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := importName(file.Imports[0]); got != want {
+			t.Errorf("importName(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
+func TestInstantiationEdges(t *testing.T) {
+	graph, err := Analyze(fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/app\n\ngo 1.24\n\nrequire github.com/samber/lo v1.47.0\n")},
+		"main.go": {Data: []byte(`package main
+
+import (
+	"example.com/app/set"
+	"example.com/app/util"
+	"github.com/samber/lo"
+)
+
+func main() {
+	s := set.New(1, 2)
+	_ = set.Set[string]{}
+	_ = util.Sum(1, 2)
+	_ = lo.Map[int, string](nil, nil)
+	_ = lo.Uniq(s.Items())
+}
+`)},
+		"set/set.go": {Data: []byte(`package set
+
+type Set[T comparable] map[T]struct{}
+
+func New[T comparable](items ...T) Set[T] { return nil }
+
+func (s Set[T]) Items() []T { return nil }
+`)},
+		"util/util.go": {Data: []byte("package util\n\nfunc Sum(a, b int) int { return a + b }\n")},
+	}, Options{Generics: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instantiates := make(map[string][]string)
+	for _, e := range graph.Edges {
+		if e.Kind == "instantiates" {
+			instantiates[e.Source+" -> "+e.Target] = e.Symbols
+		}
+	}
+	want := map[string][]string{
+		// Inferred calls count for generics of the analyzed tree
+		"pkg:root -> pkg:set": {"New", "Set"},
+		// Only explicit instantiations count for other modules
+		"pkg:root -> github.com/samber/lo": {"Map"},
+	}
+	if !reflect.DeepEqual(instantiates, want) {
+		t.Errorf("instantiates edges = %v, want %v", instantiates, want)
+	}
+}
@@ -0,0 +1,170 @@
+package depgraph
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Ignore matches slash separated paths against gitignore patterns: those of
+// the .gitignore files in a tree, of its .git/info/exclude and any given
+// globally, such as the user's core.excludesFile. As in git, a .gitignore
+// applies to its directory and below, deeper files and later patterns take
+// precedence, and a path below an ignored directory cannot be re-included.
+// .gitignore files are read on first use. It is not safe for concurrent use.
+type Ignore struct {
+	fsys   fs.FS
+	global []ignorePattern
+	dirs   map[string]*ignoreFile // directory -> its .gitignore
+}
+
+type ignoreFile struct {
+	text     string
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	segments []string // split on "/", "**" matching any number of them
+	negate   bool     // a "!" pattern re-including what earlier ones excluded
+	dirOnly  bool     // a pattern ending in "/"
+}
+
+// NewIgnore returns the matcher of the tree rooted at fsys, with the given
+// patterns taking the lowest precedence.
+func NewIgnore(fsys fs.FS, excludes []string) *Ignore {
+	ig := &Ignore{fsys: fsys, dirs: make(map[string]*ignoreFile)}
+	ig.global = parseIgnore(excludes)
+	if data, err := fs.ReadFile(fsys, ".git/info/exclude"); err == nil {
+		ig.global = append(ig.global, parseIgnore(strings.Split(string(data), "\n"))...)
+	}
+	return ig
+}
+
+// Ignored reports whether name, relative to the root, is ignored. The
+// directories above it are assumed not to be.
+func (ig *Ignore) Ignored(name string, isDir bool) bool {
+	if name == "." {
+		return false
+	}
+	ignored := matchIgnore(ig.global, name, isDir, false)
+	dir, rel := ".", name
+	for {
+		ignored = matchIgnore(ig.patterns(dir), rel, isDir, ignored)
+		first, rest, ok := strings.Cut(rel, "/")
+		if !ok {
+			return ignored
+		}
+		dir, rel = path.Join(dir, first), rest
+	}
+}
+
+// matchIgnore returns the verdict of the last of patterns matching rel, or
+// the verdict so far when none does.
+func matchIgnore(patterns []ignorePattern, rel string, isDir, ignored bool) bool {
+	var parts []string
+	for _, p := range patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if parts == nil {
+			parts = strings.Split(rel, "/")
+		}
+		if matchSegments(p.segments, parts) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// patterns returns the patterns of the .gitignore in dir, reading it on
+// first use.
+func (ig *Ignore) patterns(dir string) []ignorePattern {
+	if ig.dirs[dir] == nil {
+		ig.reload(dir)
+	}
+	return ig.dirs[dir].patterns
+}
+
+// reload reads the .gitignore in dir again, reporting whether it changed.
+func (ig *Ignore) reload(dir string) bool {
+	data, _ := fs.ReadFile(ig.fsys, path.Join(dir, ".gitignore"))
+	old := ig.dirs[dir]
+	if old != nil && old.text == string(data) {
+		return false
+	}
+	ig.dirs[dir] = &ignoreFile{string(data), parseIgnore(strings.Split(string(data), "\n"))}
+	return old != nil
+}
+
+// forget drops the .gitignore files read in and below dir, so they are read
+// again.
+func (ig *Ignore) forget(dir string) {
+	for d := range ig.dirs {
+		if d == dir || dir == "." || strings.HasPrefix(d, dir+"/") {
+			delete(ig.dirs, d)
+		}
+	}
+}
+
+// parseIgnore parses the lines of a gitignore file.
+func parseIgnore(lines []string) []ignorePattern {
+	var patterns []ignorePattern
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var p ignorePattern
+		if line[0] == '!' {
+			p.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' {
+			line = line[1:] // an escaped leading "#" or "!"
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		// Patterns without an inner slash match at any depth
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		// path.Match negates character classes with "^" rather than "!"
+		line = strings.ReplaceAll(line, "[!", "[^")
+		p.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// matchSegments matches path segments against pattern segments, "**"
+// matching any number of segments except, trailing, none.
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return len(parts) > 0
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
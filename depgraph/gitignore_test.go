@@ -0,0 +1,81 @@
+package depgraph
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestIgnore(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore": {Data: []byte(`# build output
+/bin
+dist/
+*.pb.go
+!keep.pb.go
+gen/**/*.go
+\#literal
+`)},
+		".git/info/exclude": {Data: []byte("scratch*\n")},
+		"sub/.gitignore":    {Data: []byte("local.go\n!/dist\n")},
+	}
+	ig := NewIgnore(fsys, []string{"*.tmp", "tool/"})
+	for _, tt := range []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"bin", true, true},
+		{"cmd/bin", true, false}, // anchored to the root
+		{"dist", true, true},
+		{"cmd/dist", true, true},
+		{"dist", false, false}, // only directories
+		{"api/v1/api.pb.go", false, true},
+		{"api/v1/keep.pb.go", false, false},
+		{"gen/a.go", false, true},
+		{"gen/x/y/a.go", false, true},
+		{"other/gen/a.go", false, false},
+		{"#literal", false, true},
+		{"scratch_test.go", false, true},
+		{"x.tmp", false, true},
+		{"tool", true, true},
+		{"sub/local.go", false, true},
+		{"local.go", false, false},
+		{"sub/dist", true, false}, // re-included by the deeper .gitignore
+		{"main.go", false, false},
+		{".", true, false},
+	} {
+		if got := ig.Ignored(tt.name, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.name, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeHonorsGitignore(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, file := range testModule {
+		fsys[name] = file
+	}
+	fsys[".gitignore"] = &fstest.MapFile{Data: []byte("/build/\n")}
+	fsys["build/out/gen.go"] = &fstest.MapFile{Data: []byte("package out\n\nimport _ \"github.com/ignored/module\"\n")}
+	fsys["internal/tmp/tmp.go"] = &fstest.MapFile{Data: []byte("package tmp\n")}
+
+	inc := NewIncremental(fsys, Options{Excludes: []string{"tmp/"}})
+	graph, err := inc.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"pkg:build/out", "github.com/ignored/module", "pkg:internal/tmp"} {
+		if graph.Node(id) != nil {
+			t.Errorf("ignored %s is in the graph", id)
+		}
+	}
+
+	// Un-ignoring the directory picks it up on the next update
+	fsys[".gitignore"] = &fstest.MapFile{Data: []byte("")}
+	if graph, err = inc.Update("."); err != nil {
+		t.Fatal(err)
+	}
+	if graph.Node("pkg:build/out") == nil {
+		t.Error("build/out is still missing after being un-ignored")
+	}
+}
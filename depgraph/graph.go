@@ -0,0 +1,200 @@
+// Package depgraph holds the dependency graph model shared by the go-raph
+// server and its analyzers.
+package depgraph
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+type Node struct {
+	ID          string            `json:"id"`
+	Label       string            `json:"label"`    // display name, possibly shortened by a LabelPolicy
+	FullPath    string            `json:"fullPath"` // untruncated import path, module path or file path, for tooltips
+	X           float64           `json:"x"`
+	Y           float64           `json:"y"`
+	VX          float64           `json:"vx"`
+	VY          float64           `json:"vy"`
+	Type        string            `json:"type"`
+	Depth       int               `json:"depth"`
+	Pinned      bool              `json:"pinned,omitempty"` // X and Y were placed by a user
+	Size        int64             `json:"size,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	License     string            `json:"license,omitempty"`
+	Color       string            `json:"color,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Warnings    []string          `json:"warnings,omitempty"` // why the node may be incomplete, e.g. files that failed to parse
+	New         bool              `json:"new,omitempty"`      // not in the baseline graph
+}
+
+type Edge struct {
+	Source  string   `json:"source"`
+	Target  string   `json:"target"`
+	Kind    string   `json:"kind,omitempty"`
+	Symbols []string `json:"symbols,omitempty"`
+	New     bool     `json:"new,omitempty"` // not in the baseline graph
+}
+
+type Graph struct {
+	Nodes   []Node        `json:"nodes"`
+	Edges   []Edge        `json:"edges"`
+	ColorBy string        `json:"colorBy,omitempty"`
+	Legend  []LegendEntry `json:"legend,omitempty"`
+
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records how a graph was produced, so consumers of an exported
+// graph can tell which tool version, flags and revision of the analyzed
+// module it reflects.
+type Provenance struct {
+	Tool          string    `json:"tool"`          // analyzer and its version, e.g. go-raph v1.4.0
+	AnalyzedAt    time.Time `json:"analyzedAt"`    // when the analysis ran, in UTC
+	Flags         []string  `json:"flags"`         // analysis flags changed from their defaults, as -name=value
+	Module        string    `json:"module"`        // main module path
+	ModuleVersion string    `json:"moduleVersion"` // revision of the analyzed module, e.g. from git describe
+}
+
+// LegendEntry explains one color of the dimension nodes are colored by.
+type LegendEntry struct {
+	Label string `json:"label"`
+	Color string `json:"color"`
+}
+
+// Node returns the node with the given ID, or nil if there is none.
+func (g *Graph) Node(id string) *Node {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// RemoveNode removes the node with the given ID and the edges to and from
+// it. It reports whether the node existed. Pointers into Nodes, such as
+// those Node returns, are invalid afterwards.
+func (g *Graph) RemoveNode(id string) bool {
+	i := slices.IndexFunc(g.Nodes, func(n Node) bool { return n.ID == id })
+	if i < 0 {
+		return false
+	}
+	g.Nodes = slices.Delete(g.Nodes, i, i+1)
+	g.Edges = slices.DeleteFunc(g.Edges, func(e Edge) bool { return e.Source == id || e.Target == id })
+	return true
+}
+
+// RemoveNodes removes every node whose ID is in ids and the edges to and
+// from them, in one pass over the nodes and one over the edges where
+// RemoveNode takes both per node. It returns the number of nodes removed.
+// Pointers into Nodes, such as those Node returns, are invalid afterwards.
+func (g *Graph) RemoveNodes(ids map[string]bool) int {
+	removed := make(map[string]bool)
+	n := len(g.Nodes)
+	g.Nodes = slices.DeleteFunc(g.Nodes, func(node Node) bool {
+		if ids[node.ID] {
+			removed[node.ID] = true
+		}
+		return ids[node.ID]
+	})
+	if len(removed) > 0 {
+		g.Edges = slices.DeleteFunc(g.Edges, func(e Edge) bool { return removed[e.Source] || removed[e.Target] })
+	}
+	return n - len(g.Nodes)
+}
+
+// Annotate sets a key/value annotation on a node. It reports whether the node exists.
+func (g *Graph) Annotate(id, key, value string) bool {
+	node := g.Node(id)
+	if node == nil {
+		return false
+	}
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[key] = value
+	return true
+}
+
+// Sort orders nodes by ID and edges by source, target and kind so that
+// serialized graphs are stable across runs.
+func (g *Graph) Sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.Kind < b.Kind
+	})
+}
+
+// Clone returns a copy of the graph that can be modified without affecting
+// the original. Edge symbols are shared, as nothing modifies them.
+func (g *Graph) Clone() *Graph {
+	c := *g
+	c.Nodes = append([]Node(nil), g.Nodes...)
+	c.Edges = append([]Edge(nil), g.Edges...)
+	c.Legend = append([]LegendEntry(nil), g.Legend...)
+	if g.Provenance != nil {
+		p := *g.Provenance
+		p.Flags = append([]string(nil), p.Flags...)
+		c.Provenance = &p
+	}
+	for i := range c.Nodes {
+		if c.Nodes[i].Annotations != nil {
+			annotations := make(map[string]string, len(c.Nodes[i].Annotations))
+			for k, v := range c.Nodes[i].Annotations {
+				annotations[k] = v
+			}
+			c.Nodes[i].Annotations = annotations
+		}
+		if c.Nodes[i].Warnings != nil {
+			c.Nodes[i].Warnings = append([]string(nil), c.Nodes[i].Warnings...)
+		}
+	}
+	return &c
+}
+
+// MarkNew marks the nodes and edges that are not in base as new, and clears
+// the mark on the others. Nodes are matched by ID, edges by source, target
+// and kind.
+func (g *Graph) MarkNew(base *Graph) {
+	nodes := make(map[string]bool, len(base.Nodes))
+	for _, n := range base.Nodes {
+		nodes[n.ID] = true
+	}
+	edges := make(map[[3]string]bool, len(base.Edges))
+	for _, e := range base.Edges {
+		edges[[3]string{e.Source, e.Target, e.Kind}] = true
+	}
+	for i := range g.Nodes {
+		g.Nodes[i].New = !nodes[g.Nodes[i].ID]
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		e.New = !edges[[3]string{e.Source, e.Target, e.Kind}]
+	}
+}
+
+// Diagnostic lists the warnings of one node.
+type Diagnostic struct {
+	Node     string   `json:"node"`
+	Warnings []string `json:"warnings"`
+}
+
+// Diagnostics returns the warnings of the nodes that have any, in node
+// order.
+func (g *Graph) Diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, n := range g.Nodes {
+		if len(n.Warnings) > 0 {
+			diagnostics = append(diagnostics, Diagnostic{n.ID, n.Warnings})
+		}
+	}
+	return diagnostics
+}
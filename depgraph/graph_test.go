@@ -0,0 +1,39 @@
+package depgraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRemoveNodes(t *testing.T) {
+	g := &Graph{
+		Nodes: []Node{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "b"}, {ID: "d"}},
+		Edges: []Edge{
+			{Source: "a", Target: "b"},
+			{Source: "b", Target: "c"},
+			{Source: "c", Target: "d"},
+			{Source: "d", Target: "a"},
+			{Source: "a", Target: "x"}, // to a node that is not in the graph
+		},
+	}
+	if n := g.RemoveNodes(map[string]bool{"b": true, "d": true, "x": true, "y": true}); n != 3 {
+		t.Errorf("RemoveNodes() = %d, want 3", n)
+	}
+	var nodes, edges []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n.ID)
+	}
+	for _, e := range g.Edges {
+		edges = append(edges, e.Source+"->"+e.Target)
+	}
+	if want := []string{"a", "c"}; !slices.Equal(nodes, want) {
+		t.Errorf("nodes = %v, want %v", nodes, want)
+	}
+	// x is no node, so its edge stays, as with RemoveNode
+	if want := []string{"a->x"}; !slices.Equal(edges, want) {
+		t.Errorf("edges = %v, want %v", edges, want)
+	}
+	if n := g.RemoveNodes(nil); n != 0 || len(g.Nodes) != 2 {
+		t.Errorf("RemoveNodes(nil) = %d, %d nodes left", n, len(g.Nodes))
+	}
+}
@@ -0,0 +1,130 @@
+package depgraph
+
+import (
+	"sort"
+	"strings"
+)
+
+// HotEdge is an import edge with the number of import paths from the entry
+// packages to external modules that traverse it.
+type HotEdge struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Paths  float64 `json:"paths"`  // a float, as counts outgrow integers on large graphs
+	Weight float64 `json:"weight"` // Paths relative to the hottest edge, in (0, 1]
+}
+
+// HotPaths returns the n plain import edges that most import paths from
+// the entry packages to target nodes run along, hottest first, or all of
+// them when n is not positive. The entries are the packages under cmd/, or
+// the packages nothing imports when there are none. Paths end at the first
+// target they reach, and edges closing a cycle are not followed.
+//
+// Paths are counted rather than enumerated one by one: an edge lies on as
+// many paths as there are paths into its source times paths from its
+// target, so the count takes two passes over the graph however many paths
+// there are.
+func (g *Graph) HotPaths(target func(*Node) bool, n int) []HotEdge {
+	c := g.compact(func(e *Edge, _ *Node) bool { return e.Kind == "" && e.Source != e.Target })
+	var entries []int32
+	for i := range g.Nodes {
+		dir, ok := PackageDir(g.Nodes[i].ID)
+		if ok && g.Nodes[i].Type == "package" && (dir == "cmd" || strings.HasPrefix(dir, "cmd/")) {
+			entries = append(entries, c.at[i])
+		}
+	}
+	if len(entries) == 0 {
+		entries = g.entryPackages(c)
+	}
+	isTarget := make([]bool, len(c.ids))
+	for i, node := range c.nodes {
+		isTarget[i] = node != nil && target(node)
+	}
+
+	// Depth-first postorder of what the entries reach; its reverse is a
+	// topological order in which only cycle-closing edges point backwards
+	order := make([]int32, 0, len(c.ids))
+	visited := make([]bool, len(c.ids))
+	type frame struct {
+		node int32
+		next int
+	}
+	for _, entry := range entries {
+		if visited[entry] {
+			continue
+		}
+		visited[entry] = true
+		stack := []frame{{node: entry}}
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			out := c.out.of(top.node)
+			if isTarget[top.node] || top.next == len(out) {
+				order = append(order, top.node)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			next := out[top.next]
+			top.next++
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, frame{node: next})
+			}
+		}
+	}
+	rank := make([]int, len(c.ids)) // position in topological order
+	for i, node := range order {
+		rank[node] = len(order) - i
+	}
+	forward := func(from, to int32) bool {
+		return visited[from] && !isTarget[from] && rank[from] < rank[to]
+	}
+
+	into := make([]float64, len(c.ids)) // paths from the entries
+	for _, entry := range entries {
+		into[entry] = 1
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		from := order[i]
+		for _, to := range c.out.of(from) {
+			if forward(from, to) {
+				into[to] += into[from]
+			}
+		}
+	}
+	onward := make([]float64, len(c.ids)) // paths to targets
+	for _, node := range order {
+		if isTarget[node] {
+			onward[node] = 1
+			continue
+		}
+		for _, to := range c.out.of(node) {
+			if forward(node, to) {
+				onward[node] += onward[to]
+			}
+		}
+	}
+
+	var hot []HotEdge
+	for i := range c.from {
+		from, to := c.from[i], c.to[i]
+		if paths := into[from] * onward[to]; forward(from, to) && paths > 0 {
+			hot = append(hot, HotEdge{Source: c.ids[from], Target: c.ids[to], Paths: paths})
+		}
+	}
+	sort.Slice(hot, func(i, j int) bool {
+		if hot[i].Paths != hot[j].Paths {
+			return hot[i].Paths > hot[j].Paths
+		}
+		if hot[i].Source != hot[j].Source {
+			return hot[i].Source < hot[j].Source
+		}
+		return hot[i].Target < hot[j].Target
+	})
+	if n > 0 && len(hot) > n {
+		hot = hot[:n]
+	}
+	for i := range hot {
+		hot[i].Weight = hot[i].Paths / hot[0].Paths
+	}
+	return hot
+}
@@ -0,0 +1,67 @@
+package depgraph
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHotPaths(t *testing.T) {
+	graph := &Graph{
+		Nodes: []Node{
+			{ID: "example.com/app", Type: "main"},
+			{ID: "pkg:cmd/a", Type: "package"},
+			{ID: "pkg:cmd/b", Type: "package"},
+			{ID: "pkg:lib", Type: "package"},
+			{ID: "pkg:util", Type: "package"},
+			{ID: "import:x.org/y/z", Type: "external"},
+			{ID: "x.org/y", Type: "external"},
+		},
+		Edges: []Edge{
+			{Source: "pkg:cmd/a", Target: "pkg:lib"},
+			{Source: "pkg:cmd/b", Target: "pkg:lib"},
+			{Source: "pkg:lib", Target: "pkg:util"},
+			{Source: "pkg:lib", Target: "import:x.org/y/z"},
+			{Source: "pkg:util", Target: "import:x.org/y/z"},
+			{Source: "pkg:util", Target: "pkg:lib", Kind: "instantiates"},
+			{Source: "import:x.org/y/z", Target: "x.org/y"},
+			{Source: "example.com/app", Target: "x.org/y"},
+		},
+	}
+	module := func(n *Node) bool { return n.Type == "external" && !strings.HasPrefix(n.ID, "import:") }
+
+	hot := graph.HotPaths(module, 0)
+	want := map[[2]string]float64{
+		{"import:x.org/y/z", "x.org/y"}:  4,
+		{"pkg:cmd/a", "pkg:lib"}:         2,
+		{"pkg:cmd/b", "pkg:lib"}:         2,
+		{"pkg:lib", "pkg:util"}:          2,
+		{"pkg:lib", "import:x.org/y/z"}:  2,
+		{"pkg:util", "import:x.org/y/z"}: 2,
+	}
+	if len(hot) != len(want) {
+		t.Fatalf("HotPaths = %+v, want %d edges", hot, len(want))
+	}
+	for _, e := range hot {
+		if paths, ok := want[[2]string{e.Source, e.Target}]; !ok || e.Paths != paths || e.Weight != paths/4 {
+			t.Errorf("edge %s -> %s: %v paths, weight %v", e.Source, e.Target, e.Paths, e.Weight)
+		}
+	}
+	if hot[0].Target != "x.org/y" {
+		t.Errorf("hottest edge = %+v", hot[0])
+	}
+	if top := graph.HotPaths(module, 2); len(top) != 2 || top[1].Source != "pkg:cmd/a" {
+		t.Errorf("HotPaths(2) = %+v", top)
+	}
+}
+
+func TestHotPathsCycle(t *testing.T) {
+	// Modules requiring each other must not make the counts diverge
+	graph := &Graph{
+		Nodes: []Node{{ID: "pkg:root", Type: "package"}, {ID: "a", Type: "external"}, {ID: "b", Type: "external"}},
+		Edges: []Edge{{Source: "pkg:root", Target: "a"}, {Source: "a", Target: "b"}, {Source: "b", Target: "a"}},
+	}
+	hot := graph.HotPaths(func(n *Node) bool { return n.ID == "b" }, 0)
+	if len(hot) != 2 || hot[0].Paths != 1 || hot[1].Paths != 1 {
+		t.Errorf("HotPaths = %+v", hot)
+	}
+}
@@ -0,0 +1,131 @@
+package depgraph
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PackageID returns the node ID of the main module's package in dir,
+// slash separated and relative to the module root ("." for the root). The
+// root package is pkg:root, so a top-level directory named root is
+// pkg:./root rather than colliding with it.
+func PackageID(dir string) string {
+	switch dir = path.Clean(dir); dir {
+	case ".":
+		return "pkg:root"
+	case "root":
+		return "pkg:./root"
+	}
+	return "pkg:" + dir
+}
+
+// PackageDir returns the directory of a package node ID, the reverse of
+// PackageID. It reports false for IDs of other nodes.
+func PackageDir(id string) (string, bool) {
+	dir, ok := strings.CutPrefix(id, "pkg:")
+	if !ok {
+		return "", false
+	}
+	if dir == "root" {
+		return ".", true
+	}
+	return path.Clean(dir), true
+}
+
+// NodeID is a node ID in the structured kind/scope/path scheme, which stays
+// unambiguous where the compact IDs of the analysis (pkg:internal/db,
+// import:golang.org/x/mod/semver, golang.org/x/mod) are not once graphs of
+// several projects are combined: package paths of different modules, or a
+// directory named like a module path, can look the same. The scope is the
+// module a node belongs to, empty for modules themselves.
+type NodeID struct {
+	Kind  string // package, file, import, module or the prefix of other IDs, e.g. embed
+	Scope string // module path
+	Path  string // directory or file within the scope, or the import or module path
+}
+
+// String encodes the ID as kind/scope/path, escaping the kind and scope so
+// that the first two slashes separate the parts.
+func (id NodeID) String() string {
+	return url.PathEscape(id.Kind) + "/" + url.PathEscape(id.Scope) + "/" + id.Path
+}
+
+// ParseNodeID decodes an ID encoded by NodeID.String.
+func ParseNodeID(s string) (NodeID, error) {
+	parts := strings.SplitN(s, "/", 3)
+	if len(parts) != 3 {
+		return NodeID{}, fmt.Errorf("node ID %q is not kind/scope/path", s)
+	}
+	kind, err := url.PathUnescape(parts[0])
+	if err != nil {
+		return NodeID{}, fmt.Errorf("node ID %q: %w", s, err)
+	}
+	scope, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return NodeID{}, fmt.Errorf("node ID %q: %w", s, err)
+	}
+	return NodeID{kind, scope, parts[2]}, nil
+}
+
+// StructuredID returns the structured ID of a node of a graph of module,
+// as given by its main node. Import paths are scoped to the module
+// providing them, given by owner.
+func StructuredID(node *Node, module string, owner map[string]string) NodeID {
+	if dir, ok := PackageDir(node.ID); ok {
+		return NodeID{"package", module, dir}
+	}
+	kind, rest, ok := strings.Cut(node.ID, ":")
+	switch {
+	case !ok:
+		return NodeID{"module", "", node.ID}
+	case kind == "import":
+		return NodeID{"import", owner[node.ID], rest}
+	case kind == "tool" || kind == "image":
+		return NodeID{kind, "", rest} // shared by every project using them
+	}
+	return NodeID{kind, module, rest}
+}
+
+// StructuredIDs returns a copy of the graph with its node IDs and edge
+// endpoints in the structured scheme, for exports read by other tools.
+// Merged graphs of several projects, whose package IDs already start with
+// their module path, leave the scope of packages empty.
+func (g *Graph) StructuredIDs() *Graph {
+	var module string
+	mains := 0
+	for _, n := range g.Nodes {
+		if n.Type == "main" {
+			module = n.ID
+			mains++
+		}
+	}
+	if mains > 1 {
+		module = ""
+	}
+	owner := make(map[string]string)
+	for _, e := range g.Edges {
+		if strings.HasPrefix(e.Source, "import:") && e.Kind == "" {
+			owner[e.Source] = e.Target
+		}
+	}
+
+	c := g.Clone()
+	ids := make(map[string]string, len(c.Nodes))
+	for i := range c.Nodes {
+		id := StructuredID(&c.Nodes[i], module, owner).String()
+		ids[c.Nodes[i].ID] = id
+		c.Nodes[i].ID = id
+	}
+	for i := range c.Edges {
+		if id, ok := ids[c.Edges[i].Source]; ok {
+			c.Edges[i].Source = id
+		}
+		if id, ok := ids[c.Edges[i].Target]; ok {
+			c.Edges[i].Target = id
+		}
+	}
+	c.Sort()
+	return c
+}
@@ -0,0 +1,86 @@
+package depgraph
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestPackageID(t *testing.T) {
+	for dir, want := range map[string]string{
+		".":            "pkg:root",
+		"root":         "pkg:./root",
+		"root/sub":     "pkg:root/sub",
+		"internal/db/": "pkg:internal/db",
+	} {
+		id := PackageID(dir)
+		if id != want {
+			t.Errorf("PackageID(%q) = %q, want %q", dir, id, want)
+		}
+		if back, ok := PackageDir(id); !ok || PackageID(back) != id {
+			t.Errorf("PackageDir(%q) = %q, %v", id, back, ok)
+		}
+	}
+	if _, ok := PackageDir("import:golang.org/x/mod"); ok {
+		t.Error("PackageDir accepted an import node")
+	}
+}
+
+func TestAnalyzeRootDirectory(t *testing.T) {
+	graph, err := Analyze(fstest.MapFS{
+		"go.mod":       {Data: []byte("module example.com/m\n\ngo 1.24\n")},
+		"main.go":      {Data: []byte("package main\n\nimport _ \"example.com/m/root\"\n")},
+		"root/root.go": {Data: []byte("package root\n")},
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	main, dir := graph.Node("pkg:root"), graph.Node("pkg:./root")
+	if main == nil || dir == nil || main.Label != "main" || dir.Label != "root" {
+		t.Fatalf("nodes = %+v", graph.Nodes)
+	}
+	if dir.FullPath != "example.com/m/root" {
+		t.Errorf("full path = %q", dir.FullPath)
+	}
+}
+
+func TestNodeID(t *testing.T) {
+	for _, id := range []NodeID{
+		{"package", "example.com/app", "internal/store"},
+		{"module", "", "github.com/a/one"},
+		{"embed", "example.com/app", "web:static/*"},
+	} {
+		s := id.String()
+		if got, err := ParseNodeID(s); err != nil || got != id {
+			t.Errorf("ParseNodeID(%q) = %+v, %v, want %+v", s, got, err, id)
+		}
+	}
+	if _, err := ParseNodeID("pkg:root"); err == nil {
+		t.Error("ParseNodeID accepted a compact ID")
+	}
+}
+
+func TestStructuredIDs(t *testing.T) {
+	graph, err := Analyze(testModule, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	structured := graph.StructuredIDs()
+	for _, want := range []string{
+		"module//example.com/app",
+		"package/example.com%2Fapp/.",
+		"package/example.com%2Fapp/internal/store",
+		"import/github.com%2Fa%2Fone/github.com/a/one/sub",
+	} {
+		if structured.Node(want) == nil {
+			t.Errorf("no node %s", want)
+		}
+	}
+	for _, e := range structured.Edges {
+		if e.Source == "import/github.com%2Fa%2Fone/github.com/a/one/sub" && e.Target != "module//github.com/a/one" {
+			t.Errorf("import edge to %s", e.Target)
+		}
+	}
+	if graph.Node("pkg:root") == nil {
+		t.Error("StructuredIDs changed the original graph")
+	}
+}
@@ -0,0 +1,239 @@
+package depgraph
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifest is what the analysis needs from a Kubernetes manifest or Helm
+// chart template: the workloads and services it declares.
+type manifest struct {
+	workloads []workload
+	services  []service
+}
+
+// workload is a pod-running object, like a Deployment or CronJob.
+type workload struct {
+	labels   map[string]string // pod template labels
+	binaries []string          // names the Go binary may go by, most telling first
+	values   []string          // env values and args that may address services
+}
+
+type service struct {
+	name     string
+	selector map[string]string
+}
+
+var (
+	helmAction = regexp.MustCompile(`\{\{.*?\}\}`)
+	yamlSplit  = regexp.MustCompile(`(?m)^---.*$`)
+)
+
+// templated stands in for Helm template actions, so values computed at
+// install time are never mistaken for names.
+const templated = "__helm__"
+
+// parseManifest extracts workloads and services from a YAML file with one or
+// more documents. Helm template actions are replaced first, so charts
+// parse as long as their structure is plain YAML; documents that still do
+// not parse are skipped.
+func parseManifest(src []byte) *manifest {
+	m := &manifest{}
+	var lines []string
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") {
+			continue // control lines like {{- if .Values.ingress }}
+		}
+		lines = append(lines, helmAction.ReplaceAllString(line, templated))
+	}
+
+	for _, text := range yamlSplit.Split(strings.Join(lines, "\n"), -1) {
+		var doc map[string]any
+		if yaml.Unmarshal([]byte(text), &doc) != nil || doc == nil {
+			continue
+		}
+		kind, _ := doc["kind"].(string)
+		name, _ := yamlGet(doc, "metadata", "name").(string)
+		switch kind {
+		case "Service":
+			if name == "" || strings.Contains(name, templated) {
+				continue
+			}
+			m.services = append(m.services, service{name: name, selector: yamlStrings(yamlGet(doc, "spec", "selector"))})
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+			m.workloads = append(m.workloads, podWorkload(name, yamlGet(doc, "spec", "template")))
+		case "CronJob":
+			m.workloads = append(m.workloads, podWorkload(name, yamlGet(doc, "spec", "jobTemplate", "spec", "template")))
+		case "Pod":
+			m.workloads = append(m.workloads, podWorkload(name, doc))
+		}
+	}
+	return m
+}
+
+// podWorkload collects what identifies a pod template's binary and the
+// values its containers are configured with.
+func podWorkload(name string, template any) workload {
+	w := workload{labels: yamlStrings(yamlGet(template, "metadata", "labels"))}
+	var containers []any
+	for _, key := range []string{"containers", "initContainers"} {
+		list, _ := yamlGet(template, "spec", key).([]any)
+		containers = append(containers, list...)
+	}
+	for _, c := range containers {
+		if command, _ := yamlGet(c, "command").([]any); len(command) > 0 {
+			if s, ok := command[0].(string); ok {
+				w.binaries = append(w.binaries, path.Base(s))
+			}
+		}
+		if image, ok := yamlGet(c, "image").(string); ok {
+			// registry.example.com/team/api:v1.2 -> api
+			image, _, _ = strings.Cut(image, "@")
+			if i := strings.LastIndexByte(image, ':'); i > strings.LastIndexByte(image, '/') {
+				image = image[:i]
+			}
+			w.binaries = append(w.binaries, path.Base(image))
+		}
+		if s, ok := yamlGet(c, "name").(string); ok {
+			w.binaries = append(w.binaries, s)
+		}
+		env, _ := yamlGet(c, "env").([]any)
+		for _, e := range env {
+			if s, ok := yamlGet(e, "value").(string); ok {
+				w.values = append(w.values, s)
+			}
+		}
+		args, _ := yamlGet(c, "args").([]any)
+		for _, a := range args {
+			if s, ok := a.(string); ok {
+				_, value, found := strings.Cut(s, "=")
+				if !found {
+					value = s
+				}
+				w.values = append(w.values, value)
+			}
+		}
+	}
+	if name != "" && !strings.Contains(name, templated) {
+		w.binaries = append(w.binaries, name)
+	}
+	return w
+}
+
+// yamlGet follows keys through nested mappings, returning nil when a key is
+// missing.
+func yamlGet(v any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// yamlStrings returns the string values of a mapping, like labels.
+func yamlStrings(v any) map[string]string {
+	m, _ := v.(map[string]any)
+	strs := make(map[string]string)
+	for key, value := range m {
+		if s, ok := value.(string); ok {
+			strs[key] = s
+		}
+	}
+	return strs
+}
+
+// serviceHost returns the service name a value addresses, if it looks like
+// a URL or host:port: http://billing.prod.svc.cluster.local:8080/v1 and
+// billing:9090 both address billing.
+func serviceHost(value string) string {
+	if _, rest, ok := strings.Cut(value, "://"); ok {
+		value = rest
+	}
+	if i := strings.IndexByte(value, '@'); i >= 0 {
+		value = value[i+1:]
+	}
+	if i := strings.IndexAny(value, ":/?"); i >= 0 {
+		value = value[:i]
+	}
+	name, _, _ := strings.Cut(value, ".")
+	return name
+}
+
+// addServiceNodes adds a node per Kubernetes service, with edges from the Go
+// binaries whose workloads address the service to it, and from the service
+// to the binaries of the workloads it selects. A workload's binary is the
+// package named like its container command, image, container or workload,
+// preferring packages under cmd/.
+func addServiceNodes(graph *Graph, nodeMap map[string]*Node, mainModule string, manifests map[string]*manifest) {
+	byBase := packagesByBase(graph, mainModule)
+	binary := func(w workload) string {
+		for _, name := range w.binaries {
+			ids := byBase[name]
+			if len(ids) > 1 {
+				var cmds []string
+				for _, id := range ids {
+					if strings.HasPrefix(id, "pkg:cmd/") {
+						cmds = append(cmds, id)
+					}
+				}
+				ids = cmds
+			}
+			if len(ids) == 1 {
+				return ids[0]
+			}
+		}
+		return ""
+	}
+
+	services := make(map[string]bool)
+	var all []service
+	for _, name := range sortedKeys(manifests) {
+		for _, svc := range manifests[name].services {
+			id := "service:" + svc.name
+			addNode(graph, nodeMap, id, svc.name, "service", 1)
+			graph.Annotate(id, "manifest", name)
+			services[svc.name] = true
+			all = append(all, svc)
+		}
+	}
+	for _, name := range sortedKeys(manifests) {
+		for _, w := range manifests[name].workloads {
+			pkgID := binary(w)
+			if nodeMap[pkgID] == nil {
+				continue
+			}
+			own := make(map[string]bool)
+			for _, svc := range all {
+				if selects(svc.selector, w.labels) {
+					own[svc.name] = true
+					addEdgeKind(graph, "service:"+svc.name, pkgID, "routes-to")
+				}
+			}
+			for _, value := range w.values {
+				if host := serviceHost(value); services[host] && !own[host] {
+					addEdgeKind(graph, pkgID, "service:"+host, "calls")
+				}
+			}
+		}
+	}
+}
+
+// selects reports whether a service selector matches pod labels.
+func selects(selector, labels map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package depgraph
+
+import "strings"
+
+// LabelPolicy shortens the labels of import path nodes, whose full paths
+// are long enough to clutter a drawing. The full path stays in the node's
+// FullPath.
+type LabelPolicy struct {
+	MaxLength int    // labels up to this many characters are kept whole; 0 never shortens
+	Elide     string // "middle" keeps the first and last path elements, "end" the start
+}
+
+// DefaultLabelPolicy shows import paths longer than 40 characters as their
+// first and last elements, like github.com/.../client.
+var DefaultLabelPolicy = LabelPolicy{MaxLength: 40, Elide: "middle"}
+
+// Label returns the label of a node with the given full path.
+func (p LabelPolicy) Label(fullPath string) string {
+	if p.MaxLength <= 0 || len(fullPath) <= p.MaxLength {
+		return fullPath
+	}
+	switch p.Elide {
+	case "end":
+		runes := []rune(fullPath)
+		if len(runes) <= p.MaxLength {
+			return fullPath
+		}
+		return string(runes[:max(p.MaxLength-1, 1)]) + "…"
+	default:
+		parts := strings.Split(fullPath, "/")
+		if len(parts) <= 2 {
+			return fullPath
+		}
+		return parts[0] + "/.../" + parts[len(parts)-1]
+	}
+}
+
+// ApplyLabels relabels the import path nodes of the graph from their full
+// paths.
+func (g *Graph) ApplyLabels(p LabelPolicy) {
+	for i := range g.Nodes {
+		if strings.HasPrefix(g.Nodes[i].ID, "import:") && g.Nodes[i].FullPath != "" {
+			g.Nodes[i].Label = p.Label(g.Nodes[i].FullPath)
+		}
+	}
+}
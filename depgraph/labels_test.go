@@ -0,0 +1,40 @@
+package depgraph
+
+import "testing"
+
+func TestLabelPolicy(t *testing.T) {
+	const long = "github.com/example/project/internal/pkg/client"
+	for _, tt := range []struct {
+		policy LabelPolicy
+		path   string
+		want   string
+	}{
+		{DefaultLabelPolicy, "github.com/a/one/sub", "github.com/a/one/sub"},
+		{DefaultLabelPolicy, long, "github.com/.../client"},
+		{DefaultLabelPolicy, "github.com/an-organization-with-a-long-name", "github.com/an-organization-with-a-long-name"},
+		{LabelPolicy{MaxLength: 20, Elide: "end"}, long, "github.com/example/…"},
+		{LabelPolicy{}, long, long},
+	} {
+		if got := tt.policy.Label(tt.path); got != tt.want {
+			t.Errorf("%+v.Label(%q) = %q, want %q", tt.policy, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeFullPaths(t *testing.T) {
+	graph, err := Analyze(testModule, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{
+		"example.com/app":             "example.com/app",
+		"pkg:root":                    "example.com/app",
+		"pkg:internal/store":          "example.com/app/internal/store",
+		"import:github.com/a/one/sub": "github.com/a/one/sub",
+		"github.com/a/one":            "github.com/a/one",
+	} {
+		if node := graph.Node(id); node == nil || node.FullPath != want {
+			t.Errorf("full path of %s = %+v, want %q", id, node, want)
+		}
+	}
+}
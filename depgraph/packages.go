@@ -0,0 +1,93 @@
+package depgraph
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MatchPackage reports whether the package in dir, slash separated and
+// relative to the root of module ("." for the root), matches a Go package
+// pattern. Patterns are relative to the root, like ./cmd/..., or import
+// paths of the module, like example.com/m/internal/.... As in go tooling,
+// "..." matches any string and a trailing /... also matches nothing, so
+// ./cmd/... matches cmd itself.
+func MatchPackage(pattern, module, dir string) bool {
+	switch {
+	case pattern == module:
+		pattern = "."
+	case strings.HasPrefix(pattern, module+"/"):
+		pattern = strings.TrimPrefix(pattern, module+"/")
+	case pattern == "." || strings.HasPrefix(pattern, "./"):
+		pattern = path.Clean(pattern)
+	default:
+		return false // another module's packages
+	}
+	if pattern == "..." {
+		return true
+	}
+	re := regexp.QuoteMeta(pattern)
+	if strings.HasSuffix(re, `/\.\.\.`) {
+		re = strings.TrimSuffix(re, `/\.\.\.`) + `(/\.\.\.)?`
+	}
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	matched, _ := regexp.MatchString("^"+re+"$", dir)
+	return matched
+}
+
+// scope keeps the packages matching any of patterns and the nodes they
+// reach, like `go list -deps` does, along with the main module. A pattern
+// matching no package leaves a warning on the main module.
+func (g *Graph) scope(module string, patterns []string) {
+	c := g.compact(nil)
+	kept := make([]bool, len(c.ids))
+	var queue []int32
+	var unmatched []string
+	for _, pattern := range patterns {
+		matched := false
+		for i := range g.Nodes {
+			dir, ok := PackageDir(g.Nodes[i].ID)
+			if ok && g.Nodes[i].Type == "package" && MatchPackage(pattern, module, dir) {
+				matched = true
+				if id := c.at[i]; !kept[id] {
+					kept[id] = true
+					queue = append(queue, id)
+				}
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range c.out.of(id) {
+			if !kept[next] {
+				kept[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == module {
+			for _, pattern := range unmatched {
+				g.Nodes[i].Warnings = append(g.Nodes[i].Warnings, "pattern "+pattern+" matched no packages")
+			}
+			kept[c.at[i]] = true
+		}
+	}
+	g.retain(c, kept)
+}
+
+// dropExternal removes third-party modules and their import paths, leaving
+// the main module's packages and the imports between them.
+func (g *Graph) dropExternal() {
+	c := g.compact(nil)
+	kept := make([]bool, len(c.ids))
+	for i := range g.Nodes {
+		kept[c.at[i]] = g.Nodes[i].Type != "external" && g.Nodes[i].Type != "tooling"
+	}
+	g.retain(c, kept)
+}
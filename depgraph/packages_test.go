@@ -0,0 +1,84 @@
+package depgraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchPackage(t *testing.T) {
+	const module = "example.com/m"
+	for _, tt := range []struct {
+		pattern, dir string
+		want         bool
+	}{
+		{"./...", ".", true},
+		{"./...", "cmd/tool", true},
+		{".", ".", true},
+		{".", "cmd", false},
+		{"./cmd/...", "cmd", true},
+		{"./cmd/...", "cmd/tool", true},
+		{"./cmd/...", "cmdline", false},
+		{"./cmd", "cmd/tool", false},
+		{"./internal/.../store", "internal/a/b/store", true},
+		{"example.com/m", ".", true},
+		{"example.com/m/...", "web", true},
+		{"example.com/m/internal/...", "internal/store", true},
+		{"example.com/m/internal/...", "web", false},
+		{"example.com/mother/...", "web", false},
+		{"github.com/x/y/...", "web", false},
+	} {
+		if got := MatchPackage(tt.pattern, module, tt.dir); got != tt.want {
+			t.Errorf("MatchPackage(%q, %q) = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzePackages(t *testing.T) {
+	graph, err := Analyze(testModule, Options{Packages: []string{"./internal/..."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range graph.Nodes {
+		ids = append(ids, n.ID)
+	}
+	want := []string{
+		"example.com/app",
+		"github.com/a/indirect", "github.com/b/two",
+		"import:github.com/a/indirect/pkg", "import:github.com/b/two/client",
+		"pkg:internal/store",
+	}
+	if !slices.Equal(ids, want) {
+		t.Errorf("nodes = %v, want %v", ids, want)
+	}
+	for _, e := range graph.Edges {
+		if e.Source == "example.com/app" && e.Target != "github.com/b/two" && e.Target != "github.com/a/indirect" {
+			t.Errorf("edge to %s out of scope", e.Target)
+		}
+	}
+
+	graph, err = Analyze(testModule, Options{Packages: []string{"example.com/app/nope/..."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main := graph.Node("example.com/app"); len(graph.Nodes) != 1 || main == nil || len(main.Warnings) != 1 {
+		t.Errorf("unmatched pattern gave %+v", graph.Nodes)
+	}
+}
+
+func TestAnalyzeInternalOnly(t *testing.T) {
+	graph, err := Analyze(testModule, Options{InternalOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range graph.Nodes {
+		ids = append(ids, n.ID)
+	}
+	if want := []string{"example.com/app", "pkg:internal/store", "pkg:root", "pkg:web"}; !slices.Equal(ids, want) {
+		t.Errorf("nodes = %v, want %v", ids, want)
+	}
+	if len(graph.Edges) != 3 {
+		t.Errorf("edges = %v, want the 3 package imports", graph.Edges)
+	}
+}
@@ -0,0 +1,99 @@
+package depgraph
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// protoInfo is what the analysis needs from a .proto file.
+type protoInfo struct {
+	imports   []string // imported .proto files, relative to an include root
+	goPackage string   // import path from option go_package
+	services  []string
+}
+
+var (
+	protoImport    = regexp.MustCompile(`(?m)^\s*import\s+(?:public\s+|weak\s+)?"([^"]+)"\s*;`)
+	protoGoPackage = regexp.MustCompile(`(?m)^\s*option\s+go_package\s*=\s*"([^"]+)"\s*;`)
+	protoService   = regexp.MustCompile(`(?m)^\s*service\s+(\w+)\s*\{`)
+	protoComment   = regexp.MustCompile(`//[^\n]*|/\*(?s:.*?)\*/`)
+)
+
+// parseProto extracts imports, the Go package and services from a .proto
+// source. It is a scan for the few declarations needed, not a full parser.
+func parseProto(src []byte) *protoInfo {
+	text := protoComment.ReplaceAllString(string(src), "")
+	info := &protoInfo{}
+	for _, m := range protoImport.FindAllStringSubmatch(text, -1) {
+		info.imports = append(info.imports, m[1])
+	}
+	if m := protoGoPackage.FindStringSubmatch(text); m != nil {
+		// "example.com/gen/foo;foopb" names the package after the semicolon
+		info.goPackage, _, _ = strings.Cut(m[1], ";")
+	}
+	for _, m := range protoService.FindAllStringSubmatch(text, -1) {
+		info.services = append(info.services, m[1])
+	}
+	return info
+}
+
+// addProtoNodes adds a node per .proto file, edges to the files it imports
+// and edges from the Go packages generated from it. Generated packages are found through go_package, or else as the
+// directory holding the file's .pb.go.
+func addProtoNodes(graph *Graph, nodeMap map[string]*Node, mainModule string, protos map[string]*protoInfo, goFiles map[string]bool) {
+	for _, name := range sortedKeys(protos) {
+		info := protos[name]
+		id := "proto:" + name
+		label := path.Base(name)
+		if len(info.services) > 0 {
+			label += " (" + strings.Join(info.services, ", ") + ")"
+		}
+		addNode(graph, nodeMap, id, label, "proto", 1)
+		if len(info.services) > 0 {
+			graph.Annotate(id, "services", strings.Join(info.services, ", "))
+		}
+	}
+
+	for _, name := range sortedKeys(protos) {
+		info := protos[name]
+		id := "proto:" + name
+		for _, imp := range info.imports {
+			if target, ok := resolveProtoImport(protos, name, imp); ok {
+				addEdgeKind(graph, id, "proto:"+target, "proto-import")
+			}
+		}
+
+		pkgDir := ""
+		if rel, ok := strings.CutPrefix(info.goPackage, mainModule+"/"); ok && mainModule != "" {
+			pkgDir = rel
+		} else if generated := strings.TrimSuffix(name, ".proto") + ".pb.go"; goFiles[generated] {
+			pkgDir = path.Dir(generated)
+		}
+		if pkgDir != "" && nodeMap[PackageID(pkgDir)] != nil {
+			addEdgeKind(graph, PackageID(pkgDir), id, "generated-from")
+		}
+	}
+}
+
+// resolveProtoImport finds the file an import refers to. Include paths are
+// unknown, so the import is tried relative to the importing file and the
+// module root, then matched against the end of file paths when that is
+// unambiguous. Files outside the tree, like well-known types, are not found.
+func resolveProtoImport(protos map[string]*protoInfo, from, imp string) (string, bool) {
+	for _, candidate := range []string{path.Join(path.Dir(from), imp), imp} {
+		if protos[candidate] != nil {
+			return candidate, true
+		}
+	}
+	var match string
+	for name := range protos {
+		if strings.HasSuffix(name, "/"+imp) {
+			if match != "" {
+				return "", false
+			}
+			match = name
+		}
+	}
+	return match, match != ""
+}
@@ -0,0 +1,62 @@
+package depgraph
+
+import "sort"
+
+// Unreachable simulates removing nodes from the graph and returns the other
+// nodes that could then no longer be reached from the entry packages over
+// edges of any kind, sorted. Nodes that were unreachable to begin with, like
+// the main module, are not reported. When every package is imported by
+// another one, all packages count as entries.
+func (g *Graph) Unreachable(removed []string) []string {
+	c := g.compact(nil)
+	gone := make([]bool, len(c.ids))
+	for _, id := range removed {
+		if i, ok := c.index[id]; ok {
+			gone[i] = true
+		}
+	}
+	before := g.reachable(c, nil)
+	after := g.reachable(c, gone)
+
+	var unreachable []string
+	for i, id := range c.ids {
+		if before[i] && !after[i] && !gone[i] {
+			unreachable = append(unreachable, id)
+		}
+	}
+	sort.Strings(unreachable)
+	return unreachable
+}
+
+// reachable returns which nodes are reachable from the entry packages
+// without passing through gone nodes; gone may be nil.
+func (g *Graph) reachable(c *compactGraph, gone []bool) []bool {
+	entries := g.entryPackages(c)
+	if len(entries) == 0 {
+		for i := range g.Nodes {
+			if g.Nodes[i].Type == "package" {
+				entries = append(entries, c.at[i])
+			}
+		}
+	}
+
+	reached := make([]bool, len(c.ids))
+	var queue []int32
+	for _, id := range entries {
+		if gone == nil || !gone[id] {
+			reached[id] = true
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range c.out.of(id) {
+			if !reached[next] && (gone == nil || !gone[next]) {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return reached
+}
@@ -0,0 +1,59 @@
+package depgraph
+
+import "fmt"
+
+// Subgraph returns the neighborhood of root: the nodes reachable from it
+// within depth edges, following edges out of nodes ("out", what root
+// depends on), into them ("in", what depends on root) or both. A negative
+// depth is unlimited. Edges between the kept nodes are kept.
+func (g *Graph) Subgraph(root string, depth int, direction string) (*Graph, error) {
+	if g.Node(root) == nil {
+		return nil, fmt.Errorf("no node %q", root)
+	}
+	out := direction == "out" || direction == "both"
+	in := direction == "in" || direction == "both"
+	if !out && !in {
+		return nil, fmt.Errorf("unknown direction %q, want out, in or both", direction)
+	}
+
+	c := g.compact(nil)
+	var follow []*adjacency
+	if out {
+		follow = append(follow, c.out)
+	}
+	if in {
+		follow = append(follow, c.reverse())
+	}
+
+	// Breadth-first, so each node is reached at its shortest distance
+	kept := make([]bool, len(c.ids))
+	kept[c.index[root]] = true
+	frontier := []int32{c.index[root]}
+	for d := 0; len(frontier) > 0 && (depth < 0 || d < depth); d++ {
+		var next []int32
+		for _, id := range frontier {
+			for _, adj := range follow {
+				for _, n := range adj.of(id) {
+					if !kept[n] {
+						kept[n] = true
+						next = append(next, n)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sub := &Graph{Nodes: []Node{}, Edges: []Edge{}, ColorBy: g.ColorBy, Legend: g.Legend, Provenance: g.Provenance}
+	for i, node := range g.Nodes {
+		if kept[c.at[i]] {
+			sub.Nodes = append(sub.Nodes, node)
+		}
+	}
+	for i, edge := range g.Edges {
+		if kept[c.from[i]] && kept[c.to[i]] {
+			sub.Edges = append(sub.Edges, edge)
+		}
+	}
+	return sub, nil
+}
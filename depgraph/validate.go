@@ -0,0 +1,66 @@
+package depgraph
+
+import "fmt"
+
+// SelfLoopKinds are the edge kinds whose edges may lead from a node to
+// itself. No built-in kind does; plugins register theirs.
+var SelfLoopKinds = map[string]bool{}
+
+// Validate reports what is wrong with the graph: nodes sharing an ID, edges
+// to or from nodes the graph does not have, self-loops of kinds not in
+// SelfLoopKinds and repeated edges.
+func (g *Graph) Validate() []string {
+	return g.check(false)
+}
+
+// Repair removes what Validate reports, keeping the first of the nodes
+// sharing an ID and of repeated edges, and returns what it removed.
+func (g *Graph) Repair() []string {
+	return g.check(true)
+}
+
+func (g *Graph) check(fix bool) []string {
+	var problems []string
+	ids := make(map[string]bool, len(g.Nodes))
+	var nodes []Node // filtered in place when fixing
+	if fix {
+		nodes = g.Nodes[:0]
+	}
+	for _, n := range g.Nodes {
+		if ids[n.ID] {
+			problems = append(problems, fmt.Sprintf("duplicate node %s", n.ID))
+			continue
+		}
+		ids[n.ID] = true
+		if fix {
+			nodes = append(nodes, n)
+		}
+	}
+
+	seen := make(map[[3]string]bool, len(g.Edges))
+	var edges []Edge
+	if fix {
+		edges = g.Edges[:0]
+	}
+	for _, e := range g.Edges {
+		key := [3]string{e.Source, e.Target, e.Kind}
+		switch {
+		case !ids[e.Source] || !ids[e.Target]:
+			problems = append(problems, fmt.Sprintf("edge %s -> %s references a missing node", e.Source, e.Target))
+		case e.Source == e.Target && !SelfLoopKinds[e.Kind]:
+			problems = append(problems, fmt.Sprintf("self-loop on %s", e.Source))
+		case seen[key]:
+			problems = append(problems, fmt.Sprintf("duplicate edge %s -> %s", e.Source, e.Target))
+		default:
+			seen[key] = true
+			if fix {
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	if fix {
+		g.Nodes, g.Edges = nodes, edges
+	}
+	return problems
+}
@@ -0,0 +1,50 @@
+package depgraph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAnalyzeIsValid(t *testing.T) {
+	for _, opts := range []Options{{}, {Files: true}, {Generics: true, Embeds: true}, {InternalOnly: true}} {
+		graph, err := Analyze(testModule, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if problems := graph.Validate(); len(problems) > 0 {
+			t.Errorf("%+v: %v", opts, problems)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	graph := &Graph{
+		Nodes: []Node{{ID: "a"}, {ID: "b"}, {ID: "a", Label: "again"}},
+		Edges: []Edge{
+			{Source: "a", Target: "b"},
+			{Source: "a", Target: "gone"},
+			{Source: "b", Target: "b"},
+			{Source: "a", Target: "b"},
+			{Source: "a", Target: "b", Kind: "instantiates"},
+		},
+	}
+	if problems := graph.Validate(); len(problems) != 4 || len(graph.Nodes) != 3 {
+		t.Fatalf("Validate() = %v and changed the graph to %+v", problems, graph)
+	}
+	if problems := graph.Repair(); len(problems) != 4 {
+		t.Errorf("Repair() = %v", problems)
+	}
+	if len(graph.Nodes) != 2 || graph.Nodes[0].Label != "" {
+		t.Errorf("nodes = %+v", graph.Nodes)
+	}
+	var kinds []string
+	for _, e := range graph.Edges {
+		kinds = append(kinds, e.Source+">"+e.Target+":"+e.Kind)
+	}
+	if want := []string{"a>b:", "a>b:instantiates"}; !slices.Equal(kinds, want) {
+		t.Errorf("edges = %v, want %v", kinds, want)
+	}
+	if problems := graph.Validate(); len(problems) > 0 {
+		t.Errorf("repaired graph has %v", problems)
+	}
+}
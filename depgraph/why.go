@@ -0,0 +1,107 @@
+package depgraph
+
+// ImportChains returns the import chains that lead to target, like `go mod
+// why`. Chains start at entry packages, which no other package imports, and
+// follow plain import edges; the main module's requirements are not
+// imports. Chains never visit a node twice. When limit is positive, at most
+// limit chains are returned.
+func (g *Graph) ImportChains(target string, limit int) [][]string {
+	c := g.compact(func(e *Edge, source *Node) bool {
+		return e.Kind == "" && (source == nil || source.Type != "main")
+	})
+	t, ok := c.index[target]
+	if !ok {
+		return nil
+	}
+
+	// Only nodes that can reach the target are worth exploring
+	reaches := make([]bool, len(c.ids))
+	reaches[t] = true
+	queue := []int32{t}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, prev := range c.reverse().of(id) {
+			if !reaches[prev] {
+				reaches[prev] = true
+				queue = append(queue, prev)
+			}
+		}
+	}
+
+	var chains [][]string
+	onChain := make([]bool, len(c.ids))
+	var walk func(chain []int32) bool
+	walk = func(chain []int32) bool {
+		id := chain[len(chain)-1]
+		if id == t {
+			chains = append(chains, c.names(chain))
+			return limit <= 0 || len(chains) < limit
+		}
+		onChain[id] = true
+		defer func() { onChain[id] = false }()
+		for _, next := range c.out.of(id) {
+			if reaches[next] && !onChain[next] && !walk(append(chain, next)) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, id := range g.entryPackages(c) {
+		if reaches[id] && !walk([]int32{id}) {
+			break
+		}
+	}
+	return chains
+}
+
+// entryPackages returns the packages no other package imports, such as
+// main packages, in node order.
+func (g *Graph) entryPackages(c *compactGraph) []int32 {
+	imported := make([]bool, len(c.ids))
+	for _, edge := range g.Edges {
+		if edge.Kind != "" || edge.Source == edge.Target {
+			continue
+		}
+		s, ok := c.index[edge.Source]
+		t, interned := c.index[edge.Target]
+		if ok && interned && c.nodes[s] != nil && c.nodes[s].Type == "package" {
+			imported[t] = true
+		}
+	}
+	var entries []int32
+	for i := range g.Nodes {
+		// Duplicates of a node are one entry
+		if id := c.at[i]; g.Nodes[i].Type == "package" && !imported[id] && c.nodes[id] == &g.Nodes[i] {
+			entries = append(entries, id)
+		}
+	}
+	return entries
+}
+
+// ChainGraph returns the part of the graph the chains pass through.
+func (g *Graph) ChainGraph(chains [][]string) *Graph {
+	kept := make(map[string]bool)
+	steps := make(map[[2]string]bool)
+	for _, chain := range chains {
+		for i, id := range chain {
+			kept[id] = true
+			if i > 0 {
+				steps[[2]string{chain[i-1], id}] = true
+			}
+		}
+	}
+
+	sub := &Graph{Nodes: []Node{}, Edges: []Edge{}, ColorBy: g.ColorBy, Legend: g.Legend, Provenance: g.Provenance}
+	for _, node := range g.Nodes {
+		if kept[node.ID] {
+			sub.Nodes = append(sub.Nodes, node)
+		}
+	}
+	for _, edge := range g.Edges {
+		if edge.Kind == "" && steps[[2]string{edge.Source, edge.Target}] {
+			sub.Edges = append(sub.Edges, edge)
+		}
+	}
+	return sub
+}
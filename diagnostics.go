@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+// diagnosticsHandler lists the nodes with warnings, such as packages with
+// files that failed to parse or imports no requirement provides, so clients
+// can tell the graph is incomplete.
+func diagnosticsHandler(w http.ResponseWriter, r *http.Request) {
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, graph.Diagnostics())
+}
+
+// maxReportedWarnings bounds the warnings reportDiagnostics prints.
+const maxReportedWarnings = 10
+
+// reported is the last set of warnings reportDiagnostics printed, so
+// repeated analyses of an unchanged tree stay quiet.
+var reported struct {
+	sync.Mutex
+	warnings string
+}
+
+// reportDiagnostics prints a summary of the graph's warnings to the console
+// when they changed since the last analysis.
+func reportDiagnostics(graph *depgraph.Graph) {
+	var warnings []string
+	for _, d := range graph.Diagnostics() {
+		warnings = append(warnings, d.Warnings...)
+	}
+	key := strings.Join(warnings, "\n")
+	reported.Lock()
+	defer reported.Unlock()
+	if key == reported.warnings {
+		return
+	}
+	reported.warnings = key
+	if len(warnings) == 0 {
+		return
+	}
+	log.Printf(tr("⚠️ The graph is incomplete, %d problems:"), len(warnings))
+	for i, warning := range warnings {
+		if i == maxReportedWarnings {
+			log.Printf(tr("   … and %d more"), len(warnings)-i)
+			break
+		}
+		log.Printf("   %s", warning)
+	}
+}
+
+// repairGraph removes the duplicate nodes and edges, self-loops and
+// dangling edges analyzers, plugins or a saved file left in a graph before
+// it is served, logging what it removed.
+func repairGraph(graph *depgraph.Graph) {
+	problems := graph.Repair()
+	if len(problems) == 0 {
+		return
+	}
+	log.Printf(tr("⚠️ Repaired %d problems in the graph:"), len(problems))
+	for i, problem := range problems {
+		if i == maxReportedWarnings {
+			log.Printf(tr("   … and %d more"), len(problems)-i)
+			break
+		}
+		log.Printf("   %s", problem)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(sourceFilesAnalyzer{})
+}
+
+// editor is how /api/open opens files (-editor): vscode, vscode-remote or
+// editor for links the browser follows with those URL schemes, or a
+// command run on the server with {file} replaced by the file, e.g.
+// "idea {file}".
+var editor = "vscode"
+
+// sourceFilesAnalyzer annotates the packages of an analyzed directory with
+// the directory they are in and their main file, relative to the target.
+type sourceFilesAnalyzer struct{}
+
+func (sourceFilesAnalyzer) Name() string { return "source-files" }
+
+func (sourceFilesAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if graphFrom != "" {
+		return nil
+	}
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if file, ok := packageFile(node); ok {
+			graph.Annotate(node.ID, "dir", path.Dir(file))
+			graph.Annotate(node.ID, "file", file)
+		}
+	}
+	return nil
+}
+
+// packageFile returns the main file of a package node of the analyzed
+// directory, relative to the target.
+func packageFile(node *depgraph.Node) (string, bool) {
+	dir, ok := depgraph.PackageDir(node.ID)
+	if node.Type != "package" || !ok || !filepath.IsLocal(filepath.FromSlash(dir)) {
+		return "", false
+	}
+	file, ok := mainFile(filepath.Join(targetPath, filepath.FromSlash(dir)))
+	if !ok {
+		return "", false
+	}
+	return path.Join(dir, file), true
+}
+
+// mainFile picks the file of a package directory to jump to: the one named
+// after the directory, main.go, doc.go, or else the first non-test file.
+func mainFile(dir string) (string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, name)
+		}
+	}
+	if len(files) == 0 {
+		return "", false
+	}
+	abs, _ := filepath.Abs(dir)
+	for _, name := range []string{filepath.Base(abs) + ".go", "main.go", "doc.go"} {
+		for _, f := range files {
+			if f == name {
+				return f, true
+			}
+		}
+	}
+	sort.Strings(files)
+	return files[0], true
+}
+
+// editorLink is what /api/open did: the URL for the browser to open the
+// file with, or nothing when -editor ran a command.
+type editorLink struct {
+	File string `json:"file"`
+	URL  string `json:"url,omitempty"`
+}
+
+// openHandler opens the main file of a package node (?node=) in the editor
+// of -editor. The file is looked up again rather than taken from the
+// node's annotations, which API clients can set. Commands only run for
+// requests made on this machine, not forwarded by a proxy, from
+// go-raph's own page.
+func openHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+	if graphFrom != "" {
+		http.Error(w, "a saved graph has no source files", http.StatusNotFound)
+		return
+	}
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	node := graph.Node(r.URL.Query().Get("node"))
+	if node == nil {
+		http.Error(w, "node has no source file", http.StatusNotFound)
+		return
+	}
+	rel, ok := packageFile(node)
+	if !ok {
+		http.Error(w, "node has no source file", http.StatusNotFound)
+		return
+	}
+	file, err := filepath.Abs(filepath.Join(targetPath, filepath.FromSlash(rel)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	link := editorLink{File: file}
+	switch editor {
+	case "vscode":
+		link.URL = "vscode://file" + slashPath(file)
+	case "vscode-remote":
+		host, err := os.Hostname()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		link.URL = "vscode://vscode-remote/ssh-remote+" + url.PathEscape(host) + slashPath(file)
+	case "editor":
+		link.URL = "editor://open?" + url.Values{"file": {file}}.Encode()
+	default:
+		if !localPeer(r) {
+			http.Error(w, "editor commands only run for local requests", http.StatusForbidden)
+			return
+		}
+		if err := runEditor(editor, file); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	respondJSON(w, link)
+}
+
+// slashPath writes a file path the way file URLs take it, with a leading
+// slash also before Windows drive letters.
+func slashPath(file string) string {
+	p := filepath.ToSlash(file)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// runEditor starts an editor command, substituting {file} or appending
+// the file when the command does not mention it.
+func runEditor(command, file string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return errors.New("empty editor command")
+	}
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{file}") {
+			args[i] = strings.ReplaceAll(arg, "{file}", file)
+			substituted = true
+		}
+	}
+	if !substituted {
+		args = append(args, file)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// moduleGraph returns the module requirement graph of the target from
+// `go mod graph`, by module path; versions are dropped, as only one of them
+// is selected. The first return value is the main module.
+func moduleGraph(ctx context.Context) (string, map[string][]string, error) {
+	cmd := exec.CommandContext(ctx, "go", "mod", "graph")
+	cmd.Dir = targetPath
+	out, err := cmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("go mod graph: %w", err)
+	}
+
+	var mainModule string
+	succ := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		from, to, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		from, _, _ = strings.Cut(from, "@")
+		to, _, _ = strings.Cut(to, "@")
+		if to == "go" || to == "toolchain" {
+			continue
+		}
+		if mainModule == "" {
+			mainModule = from
+		}
+		succ[from] = append(succ[from], to)
+	}
+	return mainModule, succ, nil
+}
+
+// exclusiveDeps lists the modules only reachable through a dependency.
+type exclusiveDeps struct {
+	Module    string   `json:"module"`
+	Exclusive []string `json:"exclusive"`
+}
+
+// exclusiveDepsHandler answers "if I remove this direct dependency, which
+// modules disappear?" from the dominator tree of the module graph: the
+// modules only reachable through ?module=. Without a module, it answers for
+// every requirement in go.mod.
+func exclusiveDepsHandler(w http.ResponseWriter, r *http.Request) {
+	mainModule, succ, err := moduleGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idom := depgraph.Dominators(mainModule, succ)
+
+	answer := func(module string) exclusiveDeps {
+		return exclusiveDeps{Module: module, Exclusive: append([]string{}, depgraph.Dominated(idom, module)...)}
+	}
+
+	if module := r.URL.Query().Get("module"); module != "" {
+		if _, ok := idom[module]; !ok || module == mainModule {
+			http.Error(w, "not a dependency: "+module, http.StatusNotFound)
+			return
+		}
+		respondJSON(w, answer(module))
+		return
+	}
+	required := append([]string(nil), succ[mainModule]...)
+	slices.Sort(required)
+	all := []exclusiveDeps{}
+	for _, module := range slices.Compact(required) {
+		all = append(all, answer(module))
+	}
+	respondJSON(w, all)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"go-raph/depgraph"
+)
+
+// Export only the neighborhood of a node, like /api/subgraph
+var (
+	subgraphRoot      string
+	subgraphDepth     = -1
+	subgraphDirection = "out"
+)
+
+// structuredIDs makes exports of graph data use structured node IDs
+// (-ids structured), see depgraph.NodeID. Reports keep the compact IDs they
+// read.
+var structuredIDs bool
+
+// graphFormats are the formats carrying the graph itself, which -ids
+// applies to.
+var graphFormats = map[string]bool{"json": true, "cypher": true, "parquet": true, "sqlite": true}
+
+// exporters maps -format values to functions writing the analyzed graph.
+var exporters = map[string]func(w io.Writer, graph *depgraph.Graph) error{
+	"json":                writeJSON,
+	"markdown-summary":    writeMarkdownSummary,
+	"split-markdown":      writeSplitMarkdown,
+	"split-json":          writeSplitJSON,
+	"duplicates-markdown": writeDuplicatesMarkdown,
+	"duplicates-json":     writeDuplicatesJSON,
+	"skew-markdown":       writeSkewMarkdown,
+	"skew-json":           writeSkewJSON,
+	"text-tree":           writeTextTree,
+	"cypher":              writeCypher,
+}
+
+// fileExporters write formats that are not a single stream to -o.
+var fileExporters = map[string]func(path string, graph *depgraph.Graph) error{
+	"parquet": writeParquet, // -o is a directory
+	"sqlite":  writeSQLite,  // -o is the database, graph.db by default
+}
+
+// exportGraph analyzes the target once and writes it in the requested format
+// to outputPath, or stdout when it is empty, instead of starting the server.
+func exportGraph(format, outputPath string) {
+	export, ok := exporters[format]
+	if !ok && fileExporters[format] == nil {
+		fmt.Fprintf(os.Stderr, tr("❌ Unknown format '%s'\n"), format)
+		os.Exit(1)
+	}
+
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+	if subgraphRoot != "" {
+		if graph, err = graph.Subgraph(subgraphRoot, subgraphDepth, subgraphDirection); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if structuredIDs && graphFormats[format] {
+		graph = graph.StructuredIDs()
+	}
+
+	if write := fileExporters[format]; write != nil {
+		if err := write(outputPath, graph); err != nil {
+			fmt.Fprintf(os.Stderr, tr("❌ Export failed: %v\n"), err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var w io.Writer = os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := export(w, graph); err != nil {
+		fmt.Fprintf(os.Stderr, tr("❌ Export failed: %v\n"), err)
+		os.Exit(1)
+	}
+}
+
+func writeJSON(w io.Writer, graph *depgraph.Graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graph)
+}
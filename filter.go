@@ -0,0 +1,186 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// matchFilter reports whether a node matches a filter expression. An
+// expression is a list of whitespace-separated terms that must all match:
+//
+//	type:external      field equals value (id, label, type, version, license,
+//	                   or any annotation key)
+//	id:github.com/*    * matches any run of characters
+//	-type:asset        a leading - negates the term
+//	scorecard<5        field compared as a number, with < or >; nodes
+//	                   without a numeric value never match
+//	auth               bare terms match the ID or label as a substring
+func matchFilter(node *depgraph.Node, expr string) bool {
+	for _, term := range strings.Fields(expr) {
+		negate := strings.HasPrefix(term, "-")
+		term = strings.TrimPrefix(term, "-")
+		if matchTerm(node, term) == negate {
+			return false
+		}
+	}
+	return true
+}
+
+func matchTerm(node *depgraph.Node, term string) bool {
+	if i := strings.IndexAny(term, "<>"); i > 0 && !strings.Contains(term[:i], ":") {
+		actual, err1 := strconv.ParseFloat(fieldValue(node, term[:i]), 64)
+		limit, err2 := strconv.ParseFloat(term[i+1:], 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if term[i] == '<' {
+			return actual < limit
+		}
+		return actual > limit
+	}
+	field, value, ok := strings.Cut(term, ":")
+	if !ok {
+		return strings.Contains(node.ID, term) || strings.Contains(node.Label, term)
+	}
+	return wildcardMatch(value, fieldValue(node, field))
+}
+
+// fieldValue returns a node field or annotation by name.
+func fieldValue(node *depgraph.Node, field string) string {
+	switch field {
+	case "id":
+		return node.ID
+	case "label":
+		return node.Label
+	case "type":
+		return node.Type
+	case "version":
+		return node.Version
+	case "license":
+		return node.License
+	}
+	return node.Annotations[field]
+}
+
+// wildcardMatch matches s against a pattern where * stands for any run of
+// characters, including slashes.
+func wildcardMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// filterGraph returns the nodes matching expr and the edges between them.
+func filterGraph(graph *depgraph.Graph, expr string) *depgraph.Graph {
+	if strings.TrimSpace(expr) == "" {
+		return graph
+	}
+	filtered := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}}
+	kept := make(map[string]bool)
+	for i := range graph.Nodes {
+		if matchFilter(&graph.Nodes[i], expr) {
+			filtered.Nodes = append(filtered.Nodes, graph.Nodes[i])
+			kept[graph.Nodes[i].ID] = true
+		}
+	}
+	for _, edge := range graph.Edges {
+		if kept[edge.Source] && kept[edge.Target] {
+			filtered.Edges = append(filtered.Edges, edge)
+		}
+	}
+	return filtered
+}
+
+// collapseImports makes server graphs fold leaf import nodes into their
+// modules (-collapse-imports), unless a request asks otherwise.
+var collapseImports bool
+
+// collapseModules folds the sub-package import nodes of the given modules
+// into the module nodes, redirecting their edges.
+func collapseModules(graph *depgraph.Graph, modules []string) *depgraph.Graph {
+	if len(modules) == 0 {
+		return graph
+	}
+	collapse := make(map[string]bool)
+	for _, m := range modules {
+		collapse[m] = true
+	}
+	_, owner := moduleImporters(graph)
+	folded := make(map[string]string) // import node -> module
+	for imp, module := range owner {
+		if collapse[module] {
+			folded[imp] = module
+		}
+	}
+	return foldNodes(graph, folded)
+}
+
+// collapseLeafImports folds the import nodes that lead nowhere but to their
+// module, the long-labeled leaves of the graph, into the module nodes, except
+// for the expanded modules.
+func collapseLeafImports(graph *depgraph.Graph, expanded []string) *depgraph.Graph {
+	_, owner := moduleImporters(graph)
+	leaf := make(map[string]bool, len(owner))
+	for imp := range owner {
+		leaf[imp] = true
+	}
+	for _, edge := range graph.Edges {
+		if leaf[edge.Source] && edge.Target != owner[edge.Source] {
+			leaf[edge.Source] = false
+		}
+	}
+	folded := make(map[string]string) // import node -> module
+	for imp, module := range owner {
+		if leaf[imp] && !slices.Contains(expanded, module) {
+			folded[imp] = module
+		}
+	}
+	if len(folded) == 0 {
+		return graph
+	}
+	return foldNodes(graph, folded)
+}
+
+// foldNodes removes the folded nodes, redirecting their edges to the nodes
+// they are folded into and dropping the edges that become loops or
+// duplicates.
+func foldNodes(graph *depgraph.Graph, folded map[string]string) *depgraph.Graph {
+	collapsed := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}, ColorBy: graph.ColorBy, Legend: graph.Legend, Provenance: graph.Provenance}
+	for _, node := range graph.Nodes {
+		if _, ok := folded[node.ID]; !ok {
+			collapsed.Nodes = append(collapsed.Nodes, node)
+		}
+	}
+	seen := make(map[[3]string]bool)
+	for _, edge := range graph.Edges {
+		if m, ok := folded[edge.Source]; ok {
+			edge.Source = m
+		}
+		if m, ok := folded[edge.Target]; ok {
+			edge.Target = m
+		}
+		key := [3]string{edge.Source, edge.Target, edge.Kind}
+		if edge.Source == edge.Target || seen[key] {
+			continue
+		}
+		seen[key] = true
+		collapsed.Edges = append(collapsed.Edges, edge)
+	}
+	return collapsed
+}
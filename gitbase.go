@@ -0,0 +1,66 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+// analyzeRef analyzes the target path as it was at a git ref, reading the
+// tree straight from the repository without touching the working copy.
+func analyzeRef(projectPath, ref string) (*depgraph.Graph, error) {
+	top, err := gitOutput(projectPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := gitOutput(projectPath, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	var archive bytes.Buffer
+	cmd := exec.Command("git", "-C", strings.TrimSpace(top), "archive", "--format=zip", ref+":"+strings.TrimSpace(prefix))
+	cmd.Stdout = &archive
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git archive %s: %w", ref, err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		return nil, err
+	}
+	return depgraph.Analyze(zr, analyzeOptions())
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// gitExcludes returns the patterns of git's global excludes file, set by
+// core.excludesFile and otherwise $XDG_CONFIG_HOME/git/ignore, which the
+// analysis skips along with what the project's .gitignore files do.
+var gitExcludes = sync.OnceValue(func() []string {
+	file := ""
+	if out, err := exec.Command("git", "config", "--path", "--get", "core.excludesFile").Output(); err == nil {
+		file = strings.TrimSpace(string(out))
+	} else if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		file = filepath.Join(dir, "git", "ignore")
+	} else if home, err := os.UserHomeDir(); err == nil {
+		file = filepath.Join(home, ".config", "git", "ignore")
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+})
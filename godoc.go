@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(godocAnalyzer{})
+}
+
+// docSite is where "doc" annotations link to.
+const docSite = "https://pkg.go.dev/"
+
+// godocAnalyzer annotates packages and modules with "doc", their page on
+// pkg.go.dev, and "synopsis", the first sentence of their package doc
+// comment. Synopses are read from the analyzed directory and the module
+// cache; private modules and modules without a domain get no link.
+type godocAnalyzer struct{}
+
+func (godocAnalyzer) Name() string { return "godoc" }
+
+func (godocAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	mainModule := ""
+	for _, node := range graph.Nodes {
+		if node.Type == "main" {
+			mainModule = node.ID
+		}
+	}
+	_, owner := moduleImporters(graph)
+
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		var importPath, version, dir string
+		switch {
+		case node.Type == "package" && mainModule != "":
+			rel, ok := depgraph.PackageDir(node.ID)
+			if !ok {
+				continue
+			}
+			importPath = path.Join(mainModule, rel)
+			if graphFrom == "" {
+				dir = filepath.Join(targetPath, filepath.FromSlash(rel))
+			}
+		case isExternal(node) && strings.HasPrefix(node.ID, "import:"):
+			importPath = strings.TrimPrefix(node.ID, "import:")
+			module := graph.Node(owner[node.ID])
+			if module == nil {
+				continue
+			}
+			version = module.Version
+			if root, ok := moduleDir(module.ID, module.Version); ok {
+				if rel, ok := strings.CutPrefix(importPath, module.ID); ok {
+					dir = filepath.Join(root, filepath.FromSlash(rel))
+				}
+			}
+		case isExternal(node):
+			importPath, version = node.ID, node.Version
+			dir, _ = moduleDir(node.ID, node.Version)
+		default:
+			continue
+		}
+
+		if node.Type != privateType && !isPrivate(importPath) && strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			url := docSite + importPath
+			if version != "" {
+				url += "@" + version
+			}
+			graph.Annotate(node.ID, "doc", url)
+		}
+		if dir != "" {
+			if synopsis := packageSynopsis(dir); synopsis != "" {
+				graph.Annotate(node.ID, "synopsis", synopsis)
+			}
+		}
+	}
+	return nil
+}
+
+// packageSynopsis returns the first sentence of the package doc comment of
+// the Go files in dir, preferring doc.go as godoc does.
+func packageSynopsis(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			files = append(files, name)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i] == "doc.go" && files[j] != "doc.go" })
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || f.Doc == nil {
+			continue
+		}
+		if synopsis := new(doc.Package).Synopsis(f.Doc.Text()); synopsis != "" {
+			return synopsis
+		}
+	}
+	return ""
+}
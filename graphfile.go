@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"go-raph/depgraph"
+)
+
+var (
+	graphFrom string // -from
+	graphSave string // -save
+
+	baseline *depgraph.Graph // -baseline
+)
+
+// loadBaseline reads the -baseline graph, saved with -save from another
+// branch or an earlier commit, that marks what the analyzed graph adds.
+func loadBaseline(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	var graph depgraph.Graph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	baseline = &graph
+	return nil
+}
+
+// markNew marks the nodes and edges of graph missing from the -baseline
+// graph as new, so reviewers see what a branch added.
+func markNew(graph *depgraph.Graph) {
+	if baseline == nil {
+		return
+	}
+	graph.MarkNew(baseline)
+	nodes, edges := 0, 0
+	for _, n := range graph.Nodes {
+		if n.New {
+			nodes++
+		}
+	}
+	for _, e := range graph.Edges {
+		if e.New {
+			edges++
+		}
+	}
+	log.Printf(tr("🆕 %d nodes and %d edges are not in the baseline"), nodes, edges)
+}
+
+// loadSavedGraph makes the graph in the -from file the snapshot, so that it
+// is served and reported on instead of analyzing the target. It exits when
+// the file cannot be read.
+func loadSavedGraph() {
+	if graphFrom == "" {
+		return
+	}
+	data, err := os.ReadFile(graphFrom)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	var graph depgraph.Graph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		fmt.Printf("❌ %s: %v\n", graphFrom, err)
+		os.Exit(1)
+	}
+	if graph.Nodes == nil {
+		graph.Nodes = []depgraph.Node{}
+	}
+	if graph.Edges == nil {
+		graph.Edges = []depgraph.Edge{}
+	}
+	repairGraph(&graph)
+	applyLabels(&graph)
+	markNew(&graph)
+	setSnapshot(&graph)
+}
+
+// saveGraph writes an analyzed graph to the -save file, in the format -from
+// reads.
+func saveGraph(graph *depgraph.Graph) {
+	if graphSave == "" {
+		return
+	}
+	f, err := os.Create(graphSave)
+	if err != nil {
+		log.Printf(tr("⚠️ Saving graph failed: %v"), err)
+		return
+	}
+	defer f.Close()
+	if err := writeJSON(f, graph); err != nil {
+		log.Printf(tr("⚠️ Saving graph failed: %v"), err)
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"go-raph/depgraph"
+)
+
+// graphqlSchema describes the graph for /graphql, so clients fetch the
+// slice they need rather than the whole graph.
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# A node by ID, e.g. "pkg:internal/auth" or "github.com/foo/bar"
+	node(id: ID!): Node
+	# Nodes of a type and/or whose ID contains search, up to first
+	nodes(type: String, search: String, first: Int): [Node!]!
+	# Edges of a kind and/or from source or to target; kind "" is an import
+	edges(kind: String, source: ID, target: ID): [Edge!]!
+	# Nodes within depth edges of a node, following edges out (dependencies),
+	# in (dependents) or both, and the edges between them
+	neighbors(id: ID!, direction: String = "out", depth: Int = 1): Subgraph
+	# Import chains from the project's entry packages to a node, like go-raph
+	# why; limit must be positive and is capped at 1000
+	paths(to: ID!, limit: Int = 100): [[Node!]!]!
+	# Import cycles
+	cycles: [[Node!]!]!
+	metrics: Metrics!
+}
+
+type Node {
+	id: ID!
+	label: String!
+	type: String!
+	depth: Int!
+	version: String
+	license: String
+	# Source size in bytes
+	size: Float
+	color: String
+	annotations: [Annotation!]!
+	annotation(key: String!): String
+	# Nodes this node depends on through edges of a kind, all kinds by default
+	dependencies(kind: String): [Node!]!
+	# Nodes depending on this node through edges of a kind
+	dependents(kind: String): [Node!]!
+	# Import edges to and from the node
+	fanIn: Int!
+	fanOut: Int!
+	# Not in the -baseline graph
+	new: Boolean!
+}
+
+type Edge {
+	source: Node!
+	target: Node!
+	kind: String!
+	symbols: [String!]!
+	# Not in the -baseline graph
+	new: Boolean!
+}
+
+type Subgraph {
+	nodes: [Node!]!
+	edges: [Edge!]!
+}
+
+type Annotation {
+	key: String!
+	value: String!
+}
+
+type Metrics {
+	packages: Int!
+	modules: Int!
+	imports: Int!
+	directDeps: Int!
+	edges: Int!
+	longestChain: Int!
+	cycles: Int!
+}
+`
+
+// Limits of /graphql queries, which would otherwise grow exponentially with
+// nesting like dependencies { dependencies { ... } }. The depth leaves room
+// for the introspection query of GraphQL clients.
+const (
+	maxGraphQLQuery = 16 << 10 // bytes of query text
+	maxGraphQLDepth = 12       // nested fields
+	maxGraphQLNodes = 100000   // nodes resolved by a query
+	maxGraphQLPaths = 1000     // chains paths returns
+)
+
+var parsedSchema = graphql.MustParseSchema(graphqlSchema, &queryResolver{}, graphql.UseFieldResolvers(), graphql.MaxDepth(maxGraphQLDepth))
+
+// graphqlRequest is the body of a /graphql request.
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphqlHandler serves /graphql; the /api/graph parameters apply, e.g.
+// /graphql?view=backend.
+func graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Query) > maxGraphQLQuery {
+		http.Error(w, fmt.Sprintf("query longer than %d bytes", maxGraphQLQuery), http.StatusRequestEntityTooLarge)
+		return
+	}
+	graph, ok := requestedGraph(w, r)
+	if !ok {
+		return
+	}
+	idx := newGraphIndex(graph)
+	idx.budget.Store(maxGraphQLNodes)
+	ctx := context.WithValue(r.Context(), graphIndexKey{}, idx)
+	respondJSON(w, parsedSchema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+type graphIndexKey struct{}
+
+// graphIndex is the graph of one request with its adjacency.
+type graphIndex struct {
+	graph   *depgraph.Graph
+	nodes   map[string]*depgraph.Node
+	out, in map[string][]depgraph.Edge
+	metrics graphMetrics
+	fanIn   map[string]int
+	fanOut  map[string]int
+	budget  atomic.Int64 // nodes the query may still resolve through neighbors
+}
+
+func newGraphIndex(graph *depgraph.Graph) *graphIndex {
+	idx := &graphIndex{
+		graph:   graph,
+		nodes:   make(map[string]*depgraph.Node),
+		out:     make(map[string][]depgraph.Edge),
+		in:      make(map[string][]depgraph.Edge),
+		metrics: computeMetrics(graph),
+		fanIn:   make(map[string]int),
+		fanOut:  make(map[string]int),
+	}
+	for i := range graph.Nodes {
+		idx.nodes[graph.Nodes[i].ID] = &graph.Nodes[i]
+	}
+	for _, e := range graph.Edges {
+		idx.out[e.Source] = append(idx.out[e.Source], e)
+		idx.in[e.Target] = append(idx.in[e.Target], e)
+	}
+	for _, n := range idx.metrics.Nodes {
+		idx.fanIn[n.ID], idx.fanOut[n.ID] = n.FanIn, n.FanOut
+	}
+	return idx
+}
+
+func indexOf(ctx context.Context) *graphIndex {
+	return ctx.Value(graphIndexKey{}).(*graphIndex)
+}
+
+// node resolves a node by ID, or nil.
+func (idx *graphIndex) node(id string) *nodeResolver {
+	if n := idx.nodes[id]; n != nil {
+		return &nodeResolver{n: n, idx: idx}
+	}
+	return nil
+}
+
+type queryResolver struct{}
+
+func (*queryResolver) Node(ctx context.Context, args struct{ ID graphql.ID }) *nodeResolver {
+	return indexOf(ctx).node(string(args.ID))
+}
+
+func (*queryResolver) Nodes(ctx context.Context, args struct {
+	Type   *string
+	Search *string
+	First  *int32
+}) []*nodeResolver {
+	idx := indexOf(ctx)
+	nodes := []*nodeResolver{}
+	for i := range idx.graph.Nodes {
+		n := &idx.graph.Nodes[i]
+		if args.Type != nil && n.Type != *args.Type {
+			continue
+		}
+		if args.Search != nil && !strings.Contains(strings.ToLower(n.ID), strings.ToLower(*args.Search)) {
+			continue
+		}
+		if args.First != nil && len(nodes) >= int(*args.First) {
+			break
+		}
+		nodes = append(nodes, &nodeResolver{n: n, idx: idx})
+	}
+	return nodes
+}
+
+func (*queryResolver) Edges(ctx context.Context, args struct {
+	Kind   *string
+	Source *graphql.ID
+	Target *graphql.ID
+}) []*edgeResolver {
+	idx := indexOf(ctx)
+	edges := []*edgeResolver{}
+	for _, e := range idx.graph.Edges {
+		if (args.Kind != nil && e.Kind != *args.Kind) ||
+			(args.Source != nil && e.Source != string(*args.Source)) ||
+			(args.Target != nil && e.Target != string(*args.Target)) {
+			continue
+		}
+		edges = append(edges, &edgeResolver{e: e, idx: idx})
+	}
+	return edges
+}
+
+func (*queryResolver) Neighbors(ctx context.Context, args struct {
+	ID        graphql.ID
+	Direction string
+	Depth     int32
+}) (*subgraphResolver, error) {
+	idx := indexOf(ctx)
+	if idx.nodes[string(args.ID)] == nil {
+		return nil, nil
+	}
+	sub, err := idx.graph.Subgraph(string(args.ID), int(args.Depth), args.Direction)
+	if err != nil {
+		return nil, err
+	}
+	return &subgraphResolver{graph: sub, idx: idx}, nil
+}
+
+// subgraphResolver resolves the nodes and edges of a subgraph through the
+// request's index, so fan-in and neighbors stay those of the whole graph.
+type subgraphResolver struct {
+	graph *depgraph.Graph
+	idx   *graphIndex
+}
+
+func (r *subgraphResolver) Nodes() []*nodeResolver {
+	nodes := make([]*nodeResolver, 0, len(r.graph.Nodes))
+	for _, n := range r.graph.Nodes {
+		if node := r.idx.node(n.ID); node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func (r *subgraphResolver) Edges() []*edgeResolver {
+	edges := make([]*edgeResolver, len(r.graph.Edges))
+	for i, e := range r.graph.Edges {
+		edges[i] = &edgeResolver{e: e, idx: r.idx}
+	}
+	return edges
+}
+
+func (*queryResolver) Paths(ctx context.Context, args struct {
+	To    graphql.ID
+	Limit int32
+}) ([][]*nodeResolver, error) {
+	// ImportChains lists every chain without a limit, exponentially many
+	if args.Limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	idx := indexOf(ctx)
+	return idx.chains(idx.graph.ImportChains(string(args.To), min(int(args.Limit), maxGraphQLPaths)))
+}
+
+func (*queryResolver) Cycles(ctx context.Context) ([][]*nodeResolver, error) {
+	idx := indexOf(ctx)
+	return idx.chains(idx.graph.Cycles())
+}
+
+// chains resolves lists of node IDs, counting them against the query's
+// budget.
+func (idx *graphIndex) chains(chains [][]string) ([][]*nodeResolver, error) {
+	resolved := make([][]*nodeResolver, 0, len(chains))
+	for _, chain := range chains {
+		if idx.budget.Add(-int64(len(chain))) < 0 {
+			return nil, fmt.Errorf("query resolves more than %d nodes", maxGraphQLNodes)
+		}
+		nodes := make([]*nodeResolver, 0, len(chain))
+		for _, id := range chain {
+			if n := idx.node(id); n != nil {
+				nodes = append(nodes, n)
+			}
+		}
+		resolved = append(resolved, nodes)
+	}
+	return resolved, nil
+}
+
+func (*queryResolver) Metrics(ctx context.Context) *metricsResolver {
+	idx := indexOf(ctx)
+	m := idx.metrics
+	return &metricsResolver{
+		Packages:     int32(m.Packages),
+		Modules:      int32(m.Modules),
+		Imports:      int32(m.Imports),
+		DirectDeps:   int32(m.DirectDeps),
+		Edges:        int32(m.Edges),
+		LongestChain: int32(idx.graph.LongestChain()),
+		Cycles:       int32(len(idx.graph.Cycles())),
+	}
+}
+
+type metricsResolver struct {
+	Packages, Modules, Imports, DirectDeps, Edges, LongestChain, Cycles int32
+}
+
+type nodeResolver struct {
+	n   *depgraph.Node
+	idx *graphIndex
+}
+
+func (r *nodeResolver) ID() graphql.ID { return graphql.ID(r.n.ID) }
+func (r *nodeResolver) Label() string  { return r.n.Label }
+func (r *nodeResolver) Type() string   { return r.n.Type }
+func (r *nodeResolver) Depth() int32   { return int32(r.n.Depth) }
+func (r *nodeResolver) FanIn() int32   { return int32(r.idx.fanIn[r.n.ID]) }
+func (r *nodeResolver) FanOut() int32  { return int32(r.idx.fanOut[r.n.ID]) }
+func (r *nodeResolver) New() bool      { return r.n.New }
+
+func (r *nodeResolver) Version() *string { return optional(r.n.Version) }
+func (r *nodeResolver) License() *string { return optional(r.n.License) }
+func (r *nodeResolver) Color() *string   { return optional(r.n.Color) }
+
+func (r *nodeResolver) Size() *float64 {
+	if r.n.Size == 0 {
+		return nil
+	}
+	size := float64(r.n.Size)
+	return &size
+}
+
+func (r *nodeResolver) Annotations() []*annotationResolver {
+	list := []*annotationResolver{}
+	for key, value := range r.n.Annotations {
+		list = append(list, &annotationResolver{Key: key, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+func (r *nodeResolver) Annotation(args struct{ Key string }) *string {
+	return optional(r.n.Annotations[args.Key])
+}
+
+func (r *nodeResolver) Dependencies(args struct{ Kind *string }) ([]*nodeResolver, error) {
+	return r.neighbors(r.idx.out[r.n.ID], args.Kind, func(e depgraph.Edge) string { return e.Target })
+}
+
+func (r *nodeResolver) Dependents(args struct{ Kind *string }) ([]*nodeResolver, error) {
+	return r.neighbors(r.idx.in[r.n.ID], args.Kind, func(e depgraph.Edge) string { return e.Source })
+}
+
+// neighbors resolves the nodes at the other end of edges, counting them
+// against the query's budget.
+func (r *nodeResolver) neighbors(edges []depgraph.Edge, kind *string, end func(depgraph.Edge) string) ([]*nodeResolver, error) {
+	nodes := []*nodeResolver{}
+	for _, e := range edges {
+		if kind != nil && e.Kind != *kind {
+			continue
+		}
+		if n := r.idx.node(end(e)); n != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	if r.idx.budget.Add(-int64(len(nodes))) < 0 {
+		return nil, fmt.Errorf("query resolves more than %d nodes", maxGraphQLNodes)
+	}
+	return nodes, nil
+}
+
+type edgeResolver struct {
+	e   depgraph.Edge
+	idx *graphIndex
+}
+
+func (r *edgeResolver) Source() *nodeResolver { return r.idx.node(r.e.Source) }
+func (r *edgeResolver) Target() *nodeResolver { return r.idx.node(r.e.Target) }
+func (r *edgeResolver) Kind() string          { return r.e.Kind }
+func (r *edgeResolver) New() bool             { return r.e.New }
+
+func (r *edgeResolver) Symbols() []string {
+	if r.e.Symbols == nil {
+		return []string{}
+	}
+	return r.e.Symbols
+}
+
+type annotationResolver struct {
+	Key, Value string
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+func TestGraphQLLimits(t *testing.T) {
+	// a complete graph of 20 nodes: every level of nesting multiplies the
+	// nodes resolved by 19
+	graph := &depgraph.Graph{}
+	for i := 0; i < 20; i++ {
+		graph.Nodes = append(graph.Nodes, depgraph.Node{ID: fmt.Sprintf("m%d", i), Type: "module"})
+		for j := 0; j < 20; j++ {
+			if i != j {
+				graph.Edges = append(graph.Edges, depgraph.Edge{Source: fmt.Sprintf("m%d", i), Target: fmt.Sprintf("m%d", j)})
+			}
+		}
+	}
+	nested := func(depth int) string { // node and id add two levels
+		return `{ node(id: "m0") { ` + strings.Repeat("dependencies { ", depth) + "id" + strings.Repeat(" }", depth) + " } }"
+	}
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{nested(2), ""},
+		{nested(maxGraphQLDepth - 2), "resolves more than"},
+		{nested(maxGraphQLDepth - 1), "exceeds max depth"},
+		// the type references of the introspection query clients send
+		{"{ __schema { types { fields { type { " + strings.Repeat("ofType { ", 7) + "name" + strings.Repeat(" }", 7) + " } } } } }", ""},
+	}
+	for _, test := range tests {
+		idx := newGraphIndex(graph)
+		idx.budget.Store(maxGraphQLNodes)
+		resp := parsedSchema.Exec(context.WithValue(context.Background(), graphIndexKey{}, idx), test.query, "", nil)
+		var errs []string
+		for _, err := range resp.Errors {
+			errs = append(errs, err.Message)
+		}
+		got := strings.Join(errs, "; ")
+		if test.err == "" && got != "" || !strings.Contains(got, test.err) {
+			t.Errorf("%s: errors %q, want %q", test.query, got, test.err)
+		}
+	}
+
+	body := fmt.Sprintf(`{"query": %q}`, "{ metrics { modules } }"+strings.Repeat(" ", maxGraphQLQuery))
+	w := httptest.NewRecorder()
+	graphqlHandler(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	if w.Code != 413 {
+		t.Errorf("long query: status %d, want 413", w.Code)
+	}
+}
+
+func TestGraphQLPaths(t *testing.T) {
+	graph := diamondGraph(12)
+	tests := []struct {
+		limit  int
+		chains int
+		err    string
+	}{
+		{5, 5, ""},
+		{0, 0, "limit must be positive"},
+		{-1, 0, "limit must be positive"},
+		{100000, maxGraphQLPaths, ""},
+	}
+	for _, test := range tests {
+		idx := newGraphIndex(graph)
+		idx.budget.Store(maxGraphQLNodes)
+		query := fmt.Sprintf(`{ paths(to: "x.org/y", limit: %d) { id } }`, test.limit)
+		resp := parsedSchema.Exec(context.WithValue(context.Background(), graphIndexKey{}, idx), query, "", nil)
+		var errs []string
+		for _, err := range resp.Errors {
+			errs = append(errs, err.Message)
+		}
+		if got := strings.Join(errs, "; "); test.err == "" && got != "" || !strings.Contains(got, test.err) {
+			t.Errorf("limit %d: errors %q, want %q", test.limit, got, test.err)
+			continue
+		}
+		if test.err != "" {
+			continue
+		}
+		var data struct{ Paths [][]struct{ ID string } }
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		if len(data.Paths) != test.chains {
+			t.Errorf("limit %d: %d chains, want %d", test.limit, len(data.Paths), test.chains)
+		}
+	}
+
+	// chains count against the node budget
+	idx := newGraphIndex(graph)
+	idx.budget.Store(100)
+	resp := parsedSchema.Exec(context.WithValue(context.Background(), graphIndexKey{}, idx), `{ paths(to: "x.org/y", limit: 50) { id } }`, "", nil)
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "resolves more than") {
+		t.Errorf("paths over the budget: errors %v", resp.Errors)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// defaultHotPaths is how many hot edges are sent when no limit is asked for.
+const defaultHotPaths = 50
+
+// hotPaths returns the limit import edges most paths from the cmd/
+// packages to external modules run along, in the graph as clients receive
+// it, for the visualizer to animate the traffic on.
+func hotPaths(graph *depgraph.Graph, limit int) []depgraph.HotEdge {
+	if collapseImports {
+		graph = collapseLeafImports(graph, nil)
+	}
+	hot := graph.HotPaths(func(n *depgraph.Node) bool {
+		return isExternal(n) && !strings.HasPrefix(n.ID, "import:")
+	}, limit)
+	if hot == nil {
+		hot = []depgraph.HotEdge{}
+	}
+	return hot
+}
+
+// hotPathsHandler serves the ?limit= hottest edges, 50 by default.
+func hotPathsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultHotPaths
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, hotPaths(graph, limit))
+}
+
+// subscribeHotPaths answers a client's {"type": "hot-paths"} with the hot
+// edges of the current graph and keeps sending them after every graph
+// update until it asks with a negative limit.
+func subscribeHotPaths(c *client, limit int) {
+	if limit == 0 {
+		limit = defaultHotPaths
+	}
+	c.mu.Lock()
+	c.hotPaths = max(limit, 0)
+	c.mu.Unlock()
+	if limit < 0 {
+		return
+	}
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		c.send(map[string]interface{}{"error": err.Error()})
+		return
+	}
+	c.send(map[string]interface{}{"hotPaths": hotPaths(graph, limit)})
+}
+
+// hotPathMessage returns the hot paths update for a client subscribed to
+// them, sharing one count of all edges between clients. The caller holds
+// c.mu.
+func (c *client) hotPathMessage(all *[]depgraph.HotEdge, graph *depgraph.Graph) (interface{}, bool) {
+	if c.hotPaths == 0 {
+		return nil, false
+	}
+	if *all == nil {
+		*all = hotPaths(graph, 0)
+	}
+	return map[string]interface{}{"hotPaths": (*all)[:min(c.hotPaths, len(*all))]}, true
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait  = 10 * time.Second  // time allowed to write a message
+	pongWait   = 60 * time.Second  // time allowed between pongs before a client is dead
+	pingPeriod = pongWait * 9 / 10 // must be less than pongWait
+)
+
+// client serializes writes to a WebSocket connection, which may come from
+// both its handler and broadcasts.
+type client struct {
+	conn      *websocket.Conn
+	mu        sync.Mutex
+	binary    bool       // negotiated msgpackProtocol
+	id        string     // identifies the client in presence events
+	base      string     // path of the page the client loaded, for links sent to it
+	trusted   bool       // connected from the visualizer's own pages, see sameOrigin
+	recording *recording // session being recorded, guarded by mu
+	hotPaths  int        // hot edges sent after each graph update, 0 for none; guarded by mu
+}
+
+func (c *client) send(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.write(v)
+}
+
+// write encodes v in the client's wire format. The caller holds c.mu. A
+// failed or stalled write closes the connection, which ends its read loop.
+func (c *client) write(v interface{}) error {
+	if c.recording != nil {
+		c.recording.add(true, v)
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	var err error
+	if c.binary {
+		var data []byte
+		if data, err = encodeMsgpack(v); err != nil {
+			return err
+		}
+		err = c.conn.WriteMessage(websocket.BinaryMessage, data)
+	} else {
+		err = c.conn.WriteJSON(v)
+	}
+	if err != nil {
+		c.conn.Close()
+	}
+	return err
+}
+
+// keepAlive pings the client until done is closed. Missing pongs let the
+// read deadline expire; pings also keep proxies from dropping idle
+// connections.
+func (c *client) keepAlive(done <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// sendAll writes msgs without letting other writes interleave, so chunked
+// graphs arrive in one piece.
+func (c *client) sendAll(msgs []interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range msgs {
+		if err := c.write(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hub tracks connected clients so graph updates can be pushed to all of them.
+var hub = struct {
+	sync.Mutex
+	clients map[*client]bool
+}{clients: make(map[*client]bool)}
+
+func register(c *client) {
+	hub.Lock()
+	defer hub.Unlock()
+	hub.clients[c] = true
+}
+
+func unregister(c *client) {
+	hub.Lock()
+	defer hub.Unlock()
+	delete(hub.clients, c)
+}
+
+func broadcast(v interface{}) {
+	broadcastExcept(nil, v)
+}
+
+// broadcastExcept sends v to every client but the one it originated from.
+func broadcastExcept(from *client, v interface{}) {
+	hub.Lock()
+	defer hub.Unlock()
+	for c := range hub.clients {
+		if c != from {
+			c.send(v)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"go-raph/depgraph"
+)
+
+// dialVisualizer connects to the WebSocket of a test server with an
+// Origin header, as a page served from origin would.
+func dialVisualizer(t *testing.T, server *httptest.Server, origin string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {origin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// nextError returns the next error message the server sends, skipping the
+// others.
+func nextError(t *testing.T, conn *websocket.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg map[string]interface{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatal(err)
+		}
+		if e, ok := msg["error"].(string); ok {
+			return e
+		}
+	}
+}
+
+func TestUntrustedSocket(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	notes.Lock()
+	notes.loaded, notes.projects = false, nil
+	notes.Unlock()
+	setSnapshot(&depgraph.Graph{Nodes: []depgraph.Node{{ID: "pkg:api", Type: "package"}}})
+	t.Cleanup(func() { setSnapshot(nil) })
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", websocketHandler)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	evil := dialVisualizer(t, server, "https://evil.example")
+	for _, msg := range []clientMessage{
+		{Type: "add-note", Node: "pkg:api", Text: "pwned"},
+		{Type: "delete-note", Node: "pkg:api", NoteID: "x"},
+		{Type: "positions", Positions: map[string]position{"pkg:api": {}}},
+		{Type: "reset-layout"},
+	} {
+		if err := evil.WriteJSON(msg); err != nil {
+			t.Fatal(err)
+		}
+		if e := nextError(t, evil); !strings.Contains(e, "own page") {
+			t.Errorf("%s from another site: error %q", msg.Type, e)
+		}
+	}
+	if list := projectNotes(); len(list) != 0 {
+		t.Errorf("another site changed notes: %v", list)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir(), "layouts.json")); err == nil {
+		t.Error("another site saved a layout")
+	}
+
+	own := dialVisualizer(t, server, server.URL)
+	if err := own.WriteJSON(clientMessage{Type: "add-note", Node: "pkg:api", Text: "split this"}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(projectNotes()["pkg:api"]) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(projectNotes()["pkg:api"]) != 1 {
+		t.Errorf("the visualizer's own page could not add a note")
+	}
+}
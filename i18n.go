@@ -0,0 +1,228 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// lang is the language of console messages, from -lang or the locale.
+var lang = localeLang()
+
+// messages translates console messages, keyed by their English format.
+// Messages without a translation are printed in English.
+var messages = map[string]map[string]string{
+	"ja": {
+		"⚠️ Invalid port '%s', defaulting to 8084\n":                  "⚠️ 無効なポート '%s'、8084 を使用します\n",
+		"❌ -watch cannot serve a saved graph":                         "❌ -watch は保存済みグラフを配信できません",
+		"⚠️ Watch mode analyzes a single module, go.work is ignored":  "⚠️ ウォッチモードは単一モジュールを解析します。go.work は無視されます",
+		"📂 Serving saved graph: %s\n":                                 "📂 保存済みグラフを配信中: %s\n",
+		"🎨 Analyzing: %s\n":                                           "🎨 解析中: %s\n",
+		"🌐 Visualizer: %s\n":                                          "🌐 ビジュアライザ: %s\n",
+		"📊 Dashboard: %s\n":                                           "📊 ダッシュボード: %s\n",
+		"⚠️ Empty path provided, defaulting to current directory":     "⚠️ パスが空です。カレントディレクトリを使用します",
+		"❌ Path '%s' does not exist\n":                                "❌ パス '%s' は存在しません\n",
+		"❌ Analysis failed: %v\n":                                     "❌ 解析に失敗しました: %v\n",
+		"⚠️ Re-analysis failed: %v":                                   "⚠️ 再解析に失敗しました: %v",
+		"❌ Cannot watch '%s': %v\n":                                   "❌ '%s' を監視できません: %v\n",
+		"👀 Watching for changes":                                      "👀 変更を監視中",
+		"❌ Unknown format '%s'\n":                                     "❌ 不明な形式 '%s'\n",
+		"❌ Export failed: %v\n":                                       "❌ エクスポートに失敗しました: %v\n",
+		"❌ Cannot profile: %v\n":                                      "❌ プロファイルを取得できません: %v\n",
+		"🔎 Validating: %s\n":                                          "🔎 検証中: %s\n",
+		"❌ The target cannot be analyzed with the requested features": "❌ 指定された機能では対象を解析できません",
+		"✅ Ready to analyze":                                          "✅ 解析の準備ができています",
+		"❌ Looking up the release failed: %v\n":                       "❌ リリースの取得に失敗しました: %v\n",
+		"ℹ️ This is a development build, the release is %s\n":         "ℹ️ 開発ビルドです。最新リリースは %s です\n",
+		" (modified)":                                                 " (変更あり)",
+		"✅ %s is up to date\n":                                        "✅ %s は最新です\n",
+		"⬆️ %s is available, this is %s\n":                            "⬆️ %s が利用可能です。現在は %s です\n",
+		"❌ Update failed: %v\n":                                       "❌ 更新に失敗しました: %v\n",
+		"✅ Installed %s to %s\n":                                      "✅ %[1]s を %[2]s にインストールしました\n",
+		"missing, not a Go module":                                    "見つかりません。Go モジュールではありません",
+		"module %s, %d packages":                                      "モジュール %s、%d 個のパッケージ",
+		"the graph will be incomplete, %d problems, the first: %s":    "グラフは不完全になります。問題 %d 件、最初の問題: %s",
+		"not found, -symbols, -tidy and /api/mvs need it":             "見つかりません。-symbols、-tidy、/api/mvs に必要です",
+		"%d of %d modules downloaded to %s":                           "%[2]d 個中 %[1]d 個のモジュールが %[3]s にダウンロード済み",
+		", run `go mod download` for the licenses of the others":      "。残りのライセンスには `go mod download` を実行してください",
+		"module cache":                                                "モジュールキャッシュ",
+		"GOPROXY names no proxy":                                      "GOPROXY にプロキシが指定されていません",
+		"skipped with -offline":                                       "-offline のためスキップ",
+		"⚠️ The graph is incomplete, %d problems:":                    "⚠️ グラフは不完全です。問題が %d 件あります:",
+		"   … and %d more":                                            "   … ほか %d 件",
+		"⚠️ Repaired %d problems in the graph:":                       "⚠️ グラフの問題を %d 件修復しました:",
+		"⚠️ Listing the module graph failed, showing the imported modules only: %v": "⚠️ モジュールグラフの取得に失敗したため、インポートされたモジュールのみを表示します: %v",
+		"❌ -internal-only and -external-only cannot be combined":                    "❌ -internal-only と -external-only は同時に指定できません",
+		"🆕 %d nodes and %d edges are not in the baseline":                           "🆕 ベースラインにないノードが %d 個、エッジが %d 本あります",
+		"❌ Inventory failed: %v\n":                                                  "❌ インベントリの作成に失敗しました: %v\n",
+		"❌ Publish failed: %v\n":                                                    "❌ 公開に失敗しました: %v\n",
+		"📦 Published %d module pages to %s\n":                                       "📦 %[2]s に %[1]d 件のモジュールページを公開しました\n",
+		"⚠️ Vulnerability lookup failed: %v\n":                                      "⚠️ 脆弱性の照会に失敗しました: %v\n",
+		"🏢 Analyzing %d repositories\n":                                             "🏢 %d 件のリポジトリを解析中\n",
+		"❌ Failed to load plugin '%s': %v\n":                                        "❌ プラグイン '%s' を読み込めません: %v\n",
+		"❌ '%s' is not imported by %s\n":                                            "❌ '%s' は %s からインポートされていません\n",
+		"❌ '%s' is not in the module graph of %s\n":                                 "❌ '%s' は %s のモジュールグラフにありません\n",
+		"✅ All external modules are on %s\n":                                        "✅ すべての外部モジュールが %s に登録されています\n",
+		"🚫 %d external modules are not on %s:\n":                                    "🚫 %[2]s に未登録の外部モジュールが %[1]d 件あります:\n",
+		"\nrun `go-raph approve <module>` once a module has been reviewed":          "\nレビュー後に `go-raph approve <module>` を実行してください",
+		"ℹ️ %s is already approved\n":                                               "ℹ️ %s は承認済みです\n",
+		"✅ Approved %s\n":                                                           "✅ %s を承認しました\n",
+		"   read-only, expires %s\n":                                                "   読み取り専用、有効期限 %s\n",
+		"⚠️ Saving layout failed: %v":                                               "⚠️ レイアウトの保存に失敗しました: %v",
+		"⚠️ Resetting layout failed: %v":                                            "⚠️ レイアウトのリセットに失敗しました: %v",
+		"⚠️ Saving graph failed: %v":                                                "⚠️ グラフの保存に失敗しました: %v",
+		"⚠️ Saving recording %s failed: %v":                                         "⚠️ 記録 %s の保存に失敗しました: %v",
+		"⚠️ Rendering dashboard failed: %v":                                         "⚠️ ダッシュボードの描画に失敗しました: %v",
+		"⚠️ Reading %s failed: %v":                                                  "⚠️ %s の読み込みに失敗しました: %v",
+		"⚠️ Saving metrics history failed: %v":                                      "⚠️ メトリクス履歴の保存に失敗しました: %v",
+		"⚠️ Reading the theme failed: %v":                                           "⚠️ テーマの読み込みに失敗しました: %v",
+		"⚠️ Reading the label policy failed: %v":                                    "⚠️ ラベルポリシーの読み込みに失敗しました: %v",
+		"⚠️ Opening the browser failed: %v":                                         "⚠️ ブラウザを開けませんでした: %v",
+		"⚠️ Port %s is in use, serving on %s instead\n":                             "⚠️ ポート %s は使用中のため、%s で起動します\n",
+		"🕸️ Merged %d nodes and %d edges into %s\n":                                 "🕸️ %[3]s に %[1]d 個のノードと %[2]d 本のエッジをマージしました\n",
+		"❌ Unknown daemon command '%s'\n":                                           "❌ 不明な daemon コマンド '%s'\n",
+		"ℹ️ %s is already registered as %s\n":                                       "ℹ️ %s は %s として登録済みです\n",
+		"❌ Invalid project name '%s'\n":                                             "❌ 無効なプロジェクト名 '%s'\n",
+		"❌ A project named '%s' is already registered\n":                            "❌ '%s' という名前のプロジェクトは登録済みです\n",
+		"✅ Registered %s as %s\n":                                                   "✅ %s を %s として登録しました\n",
+		"❌ No registered project '%s'\n":                                            "❌ 登録されたプロジェクト '%s' はありません\n",
+		"✅ Removed %s\n":                                                            "✅ %s を削除しました\n",
+		"ℹ️ No projects registered, add one with `go-raph daemon add <path>`":       "ℹ️ 登録されたプロジェクトはありません。`go-raph daemon add <path>` で追加してください",
+		"🗂️ Daemon: %s\n":                                                           "🗂️ デーモン: %s\n",
+		"⚠️ Reading the project registry failed: %v":                                "⚠️ プロジェクト登録簿の読み込みに失敗しました: %v",
+		"⚠️ Skipping invalid registered project '%s' (%s)":                          "⚠️ 無効な登録プロジェクト '%s' (%s) をスキップします",
+		"🛑 Stopped %s":                                                              "🛑 %s を停止しました",
+		"▶️ Serving %s from %s":                                                     "▶️ %[2]s から %[1]s を配信しています",
+		"⚠️ %s exited (%v), restarting in %s":                                       "⚠️ %s が終了しました (%v)。%s 後に再起動します",
+		"⚠️ Using the module cache %s failed: %v":                                   "⚠️ モジュールキャッシュ %s を使用できませんでした: %v",
+		"📦 Downloading %d modules into %s":                                          "📦 %d 個のモジュールを %s にダウンロードしています",
+		"⚠️ Downloading modules failed: %v: %s":                                     "⚠️ モジュールのダウンロードに失敗しました: %v: %s",
+		" (not created)":                                                            "（未作成）",
+		"🚨 %s does not match go.sum: %s":                                            "🚨 %s が go.sum と一致しません: %s",
+	},
+	"zh": {
+		"⚠️ Invalid port '%s', defaulting to 8084\n":                  "⚠️ 端口 '%s' 无效，改用 8084\n",
+		"❌ -watch cannot serve a saved graph":                         "❌ -watch 无法提供已保存的图",
+		"⚠️ Watch mode analyzes a single module, go.work is ignored":  "⚠️ 监视模式只分析单个模块，已忽略 go.work",
+		"📂 Serving saved graph: %s\n":                                 "📂 正在提供已保存的图: %s\n",
+		"🎨 Analyzing: %s\n":                                           "🎨 正在分析: %s\n",
+		"🌐 Visualizer: %s\n":                                          "🌐 可视化: %s\n",
+		"📊 Dashboard: %s\n":                                           "📊 仪表盘: %s\n",
+		"⚠️ Empty path provided, defaulting to current directory":     "⚠️ 路径为空，改用当前目录",
+		"❌ Path '%s' does not exist\n":                                "❌ 路径 '%s' 不存在\n",
+		"❌ Analysis failed: %v\n":                                     "❌ 分析失败: %v\n",
+		"⚠️ Re-analysis failed: %v":                                   "⚠️ 重新分析失败: %v",
+		"❌ Cannot watch '%s': %v\n":                                   "❌ 无法监视 '%s': %v\n",
+		"👀 Watching for changes":                                      "👀 正在监视变更",
+		"❌ Unknown format '%s'\n":                                     "❌ 未知格式 '%s'\n",
+		"❌ Export failed: %v\n":                                       "❌ 导出失败: %v\n",
+		"❌ Cannot profile: %v\n":                                      "❌ 无法进行性能分析: %v\n",
+		"🔎 Validating: %s\n":                                          "🔎 正在验证: %s\n",
+		"❌ The target cannot be analyzed with the requested features": "❌ 无法使用所请求的功能分析目标",
+		"✅ Ready to analyze":                                          "✅ 可以开始分析",
+		"❌ Looking up the release failed: %v\n":                       "❌ 查询发布版本失败: %v\n",
+		"ℹ️ This is a development build, the release is %s\n":         "ℹ️ 这是开发版本，最新发布版本为 %s\n",
+		" (modified)":                                                 " (已修改)",
+		"✅ %s is up to date\n":                                        "✅ %s 已是最新\n",
+		"⬆️ %s is available, this is %s\n":                            "⬆️ 有可用的 %s，当前为 %s\n",
+		"❌ Update failed: %v\n":                                       "❌ 更新失败: %v\n",
+		"✅ Installed %s to %s\n":                                      "✅ 已将 %[1]s 安装到 %[2]s\n",
+		"missing, not a Go module":                                    "缺失，不是 Go 模块",
+		"module %s, %d packages":                                      "模块 %s，%d 个包",
+		"the graph will be incomplete, %d problems, the first: %s":    "图将不完整，共 %d 个问题，第一个: %s",
+		"not found, -symbols, -tidy and /api/mvs need it":             "未找到，-symbols、-tidy 和 /api/mvs 需要它",
+		"%d of %d modules downloaded to %s":                           "%[2]d 个模块中有 %[1]d 个已下载到 %[3]s",
+		", run `go mod download` for the licenses of the others":      "，运行 `go mod download` 以获取其余模块的许可证",
+		"module cache":                                                "模块缓存",
+		"GOPROXY names no proxy":                                      "GOPROXY 未指定代理",
+		"skipped with -offline":                                       "因 -offline 跳过",
+		"⚠️ The graph is incomplete, %d problems:":                    "⚠️ 图不完整，共有 %d 个问题:",
+		"   … and %d more":                                            "   … 另有 %d 个",
+		"⚠️ Repaired %d problems in the graph:":                       "⚠️ 已修复图中的 %d 个问题:",
+		"⚠️ Listing the module graph failed, showing the imported modules only: %v": "⚠️ 获取模块图失败，仅显示被导入的模块: %v",
+		"❌ -internal-only and -external-only cannot be combined":                    "❌ -internal-only 与 -external-only 不能同时使用",
+		"🆕 %d nodes and %d edges are not in the baseline":                           "🆕 基线中没有的节点 %d 个、边 %d 条",
+		"❌ Inventory failed: %v\n":                                                  "❌ 生成清单失败: %v\n",
+		"❌ Publish failed: %v\n":                                                    "❌ 发布失败: %v\n",
+		"📦 Published %d module pages to %s\n":                                       "📦 已将 %d 个模块页面发布到 %s\n",
+		"⚠️ Vulnerability lookup failed: %v\n":                                      "⚠️ 漏洞查询失败: %v\n",
+		"🏢 Analyzing %d repositories\n":                                             "🏢 正在分析 %d 个仓库\n",
+		"❌ Failed to load plugin '%s': %v\n":                                        "❌ 无法加载插件 '%s': %v\n",
+		"❌ '%s' is not imported by %s\n":                                            "❌ %[2]s 没有导入 '%[1]s'\n",
+		"❌ '%s' is not in the module graph of %s\n":                                 "❌ '%s' 不在 %s 的模块图中\n",
+		"✅ All external modules are on %s\n":                                        "✅ 所有外部模块都在 %s 中\n",
+		"🚫 %d external modules are not on %s:\n":                                    "🚫 %d 个外部模块不在 %s 中:\n",
+		"\nrun `go-raph approve <module>` once a module has been reviewed":          "\n模块审查通过后请运行 `go-raph approve <module>`",
+		"ℹ️ %s is already approved\n":                                               "ℹ️ %s 已获批准\n",
+		"✅ Approved %s\n":                                                           "✅ 已批准 %s\n",
+		"   read-only, expires %s\n":                                                "   只读，过期时间 %s\n",
+		"⚠️ Saving layout failed: %v":                                               "⚠️ 保存布局失败: %v",
+		"⚠️ Resetting layout failed: %v":                                            "⚠️ 重置布局失败: %v",
+		"⚠️ Saving graph failed: %v":                                                "⚠️ 保存图失败: %v",
+		"⚠️ Saving recording %s failed: %v":                                         "⚠️ 保存录制 %s 失败: %v",
+		"⚠️ Rendering dashboard failed: %v":                                         "⚠️ 渲染仪表盘失败: %v",
+		"⚠️ Reading %s failed: %v":                                                  "⚠️ 读取 %s 失败: %v",
+		"⚠️ Saving metrics history failed: %v":                                      "⚠️ 保存指标历史失败: %v",
+		"⚠️ Reading the theme failed: %v":                                           "⚠️ 读取主题失败: %v",
+		"⚠️ Reading the label policy failed: %v":                                    "⚠️ 读取标签策略失败: %v",
+		"⚠️ Opening the browser failed: %v":                                         "⚠️ 打开浏览器失败: %v",
+		"⚠️ Port %s is in use, serving on %s instead\n":                             "⚠️ 端口 %s 已被占用，改用 %s\n",
+		"🕸️ Merged %d nodes and %d edges into %s\n":                                 "🕸️ 已将 %d 个节点和 %d 条边合并到 %s\n",
+		"❌ Unknown daemon command '%s'\n":                                           "❌ 未知的 daemon 命令 '%s'\n",
+		"ℹ️ %s is already registered as %s\n":                                       "ℹ️ %s 已注册为 %s\n",
+		"❌ Invalid project name '%s'\n":                                             "❌ 无效的项目名 '%s'\n",
+		"❌ A project named '%s' is already registered\n":                            "❌ 名为 '%s' 的项目已注册\n",
+		"✅ Registered %s as %s\n":                                                   "✅ 已将 %s 注册为 %s\n",
+		"❌ No registered project '%s'\n":                                            "❌ 没有已注册的项目 '%s'\n",
+		"✅ Removed %s\n":                                                            "✅ 已移除 %s\n",
+		"ℹ️ No projects registered, add one with `go-raph daemon add <path>`":       "ℹ️ 没有已注册的项目，请用 `go-raph daemon add <path>` 添加",
+		"🗂️ Daemon: %s\n":                                                           "🗂️ 守护进程: %s\n",
+		"⚠️ Reading the project registry failed: %v":                                "⚠️ 读取项目注册表失败: %v",
+		"⚠️ Skipping invalid registered project '%s' (%s)":                          "⚠️ 跳过无效的注册项目 '%s' (%s)",
+		"🛑 Stopped %s":                                                              "🛑 已停止 %s",
+		"▶️ Serving %s from %s":                                                     "▶️ 正在从 %[2]s 提供 %[1]s",
+		"⚠️ %s exited (%v), restarting in %s":                                       "⚠️ %s 已退出 (%v)，%s 后重启",
+		"⚠️ Using the module cache %s failed: %v":                                   "⚠️ 使用模块缓存 %s 失败: %v",
+		"📦 Downloading %d modules into %s":                                          "📦 正在将 %d 个模块下载到 %s",
+		"⚠️ Downloading modules failed: %v: %s":                                     "⚠️ 下载模块失败: %v: %s",
+		" (not created)":                                                            "（未创建）",
+		"🚨 %s does not match go.sum: %s":                                            "🚨 %s 与 go.sum 不匹配：%s",
+	},
+}
+
+// tr returns the format of a console message in the selected language.
+func tr(format string) string {
+	if translated := messages[lang][format]; translated != "" {
+		return translated
+	}
+	return format
+}
+
+// localeLang picks a supported language from the POSIX locale variables,
+// e.g. ja from LANG=ja_JP.UTF-8.
+func localeLang() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		code := strings.ToLower(locale[:min(2, len(locale))])
+		if messages[code] != nil {
+			return code
+		}
+		return "en"
+	}
+	return "en"
+}
+
+// addLangFlag registers -lang on a flag set.
+func addLangFlag(fs *flag.FlagSet) {
+	fs.Func("lang", "Language of console messages: en, ja or zh (default from the locale)", func(code string) error {
+		if code != "en" && messages[code] == nil {
+			return fmt.Errorf("unsupported language %q", code)
+		}
+		lang = code
+		return nil
+	})
+}
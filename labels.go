@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"go-raph/depgraph"
+)
+
+// labelConfig is the labels section of goraph.yaml, the policy shortening
+// long import path labels. Clients show the full paths from the fullPath
+// field.
+type labelConfig struct {
+	MaxLength *int   `yaml:"max-length"` // 0 keeps labels whole, 40 by default
+	Elide     string `yaml:"elide"`      // middle (default) or end
+}
+
+// labelPolicy returns the configured labeling policy, or the default one
+// when the configuration is invalid.
+func labelPolicy() depgraph.LabelPolicy {
+	policy := depgraph.DefaultLabelPolicy
+	cfg, err := readConfig()
+	if err == nil {
+		if cfg.Labels.MaxLength != nil {
+			policy.MaxLength = *cfg.Labels.MaxLength
+		}
+		switch cfg.Labels.Elide {
+		case "":
+		case "middle", "end":
+			policy.Elide = cfg.Labels.Elide
+		default:
+			err = fmt.Errorf("unknown label elision %q", cfg.Labels.Elide)
+		}
+	}
+	if err != nil {
+		log.Printf(tr("⚠️ Reading the label policy failed: %v"), err)
+		return depgraph.DefaultLabelPolicy
+	}
+	return policy
+}
+
+// applyLabels labels the graph with the configured policy.
+func applyLabels(graph *depgraph.Graph) {
+	graph.ApplyLabels(labelPolicy())
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+type position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// layouts holds node positions users pinned by dragging, per project, and
+// is persisted so untangled layouts survive restarts.
+var layouts = struct {
+	sync.Mutex
+	loaded   bool
+	projects map[string]map[string]position
+}{projects: make(map[string]map[string]position)}
+
+func init() {
+	depgraph.Register(layoutAnalyzer{})
+}
+
+// layoutAnalyzer places pinned nodes at their saved positions.
+type layoutAnalyzer struct{}
+
+func (layoutAnalyzer) Name() string { return "layout" }
+
+func (layoutAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	layouts.Lock()
+	defer layouts.Unlock()
+	loadLayouts()
+	saved := layouts.projects[projectKey()]
+	for i := range graph.Nodes {
+		if pos, ok := saved[graph.Nodes[i].ID]; ok {
+			graph.Nodes[i].X, graph.Nodes[i].Y = pos.X, pos.Y
+			graph.Nodes[i].Pinned = true
+		}
+	}
+	return nil
+}
+
+// loadLayouts reads the layouts file once; callers hold the lock.
+func loadLayouts() {
+	if layouts.loaded {
+		return
+	}
+	layouts.loaded = true
+	if err := readState("layouts.json", &layouts.projects); err != nil {
+		log.Printf(tr("⚠️ Reading %s failed: %v"), "layouts.json", err)
+	}
+	if layouts.projects == nil {
+		layouts.projects = make(map[string]map[string]position)
+	}
+}
+
+// saveLayout merges position updates into the project's layout and writes
+// the file. A nil updates map resets the layout.
+func saveLayout(updates map[string]position) error {
+	layouts.Lock()
+	defer layouts.Unlock()
+	loadLayouts()
+
+	key := projectKey()
+	if updates == nil {
+		delete(layouts.projects, key)
+	} else {
+		if layouts.projects[key] == nil {
+			layouts.projects[key] = make(map[string]position)
+		}
+		for id, pos := range updates {
+			layouts.projects[key][id] = pos
+		}
+	}
+
+	return writeState("layouts.json", layouts.projects)
+}
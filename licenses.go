@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(licenseAnalyzer{})
+}
+
+// licenseAnalyzer sets the License of external modules found in the module
+// cache by classifying their license files.
+type licenseAnalyzer struct{}
+
+func (licenseAnalyzer) Name() string { return "license" }
+
+func (licenseAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if !isExternal(node) || node.Version == "" {
+			continue
+		}
+		if dir, ok := moduleDir(node.ID, node.Version); ok {
+			node.License = detectLicense(dir)
+		}
+	}
+	return nil
+}
+
+// licenseMarkers identify common licenses by distinctive phrases, checked in
+// order so that e.g. the LGPL is not mistaken for the GPL.
+var licenseMarkers = []struct{ id, phrase string }{
+	{"AGPL-3.0", "GNU AFFERO GENERAL PUBLIC LICENSE"},
+	{"LGPL", "GNU LESSER GENERAL PUBLIC LICENSE"},
+	{"GPL", "GNU GENERAL PUBLIC LICENSE"},
+	{"MPL-2.0", "Mozilla Public License"},
+	{"EPL-2.0", "Eclipse Public License"},
+	{"Apache-2.0", "Apache License"},
+	{"MIT", "Permission is hereby granted, free of charge"},
+	{"BSD-3-Clause", "Neither the name"},
+	{"BSD-2-Clause", "Redistribution and use in source and binary forms"},
+	{"ISC", "Permission to use, copy, modify, and/or distribute"},
+	{"Unlicense", "This is free and unencumbered software released into the public domain"},
+}
+
+func detectLicense(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		name := strings.ToUpper(entry.Name())
+		if entry.IsDir() || !(strings.HasPrefix(name, "LICENSE") || strings.HasPrefix(name, "LICENCE") || strings.HasPrefix(name, "COPYING")) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		text := string(data)
+		for _, m := range licenseMarkers {
+			if !strings.Contains(text, m.phrase) {
+				continue
+			}
+			if m.id == "GPL" && strings.Contains(text, "Version 3,") {
+				return "GPL-3.0"
+			} else if m.id == "GPL" && strings.Contains(text, "Version 2,") {
+				return "GPL-2.0"
+			}
+			return m.id
+		}
+		return "unknown"
+	}
+	return ""
+}
+
+// isCopyleft reports whether a license detected by detectLicense requires
+// derived works to be shared under the same terms.
+func isCopyleft(license string) bool {
+	for _, prefix := range []string{"AGPL", "LGPL", "GPL", "MPL", "EPL"} {
+		if strings.HasPrefix(license, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limits keeping a server exposed on a network from being overwhelmed.
+var (
+	rateLimit      = 20.0    // requests per second per client IP, 0 for unlimited
+	rateBurst      = 40      // requests a client IP may make at once
+	maxMessageSize = 4 << 20 // bytes of an HTTP request body or WebSocket message
+	maxAnalyses    = 2       // analyses running at once, the rest wait
+)
+
+// addLimitFlags registers the limits of the servers.
+func addLimitFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&rateLimit, "rate-limit", rateLimit, "Requests per second allowed per client IP (0 for unlimited)")
+	fs.IntVar(&rateBurst, "rate-burst", rateBurst, "Requests a client IP may make at once above -rate-limit")
+	fs.IntVar(&maxMessageSize, "max-message-size", maxMessageSize, "Largest HTTP request body or WebSocket message accepted, in bytes")
+	fs.IntVar(&maxAnalyses, "max-analyses", maxAnalyses, "Analyses running at once; further requests wait (0 for unlimited)")
+	fs.BoolVar(&sandboxAnalysis, "sandbox", false, "Analyze in a separate process limited by -sandbox-memory, -sandbox-cpu and -sandbox-timeout")
+	fs.IntVar(&sandboxMemory, "sandbox-memory", sandboxMemory, "Address space of a sandboxed analysis, in MiB (0 for unlimited)")
+	fs.IntVar(&sandboxCPU, "sandbox-cpu", sandboxCPU, "CPU seconds of a sandboxed analysis (0 for unlimited)")
+	fs.DurationVar(&sandboxTimeout, "sandbox-timeout", sandboxTimeout, "Wall time of a sandboxed analysis (0 for unlimited)")
+}
+
+// analysisSlots bounds concurrent analyses, sized on first use once flags
+// are parsed.
+var analysisSlots = sync.OnceValue(func() chan struct{} { return make(chan struct{}, maxAnalyses) })
+
+// acquireAnalysis waits for an analysis slot, giving up when ctx is done.
+// The returned function releases the slot.
+func acquireAnalysis(ctx context.Context) (func(), error) {
+	if maxAnalyses <= 0 {
+		return func() {}, nil
+	}
+	slots := analysisSlots()
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ipLimiters holds a token bucket per client IP, forgetting IPs idle for
+// a few minutes.
+var ipLimiters = struct {
+	sync.Mutex
+	byIP  map[string]*ipLimiter
+	swept time.Time
+}{byIP: make(map[string]*ipLimiter)}
+
+type ipLimiter struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+const limiterIdle = 5 * time.Minute
+
+func allowRequest(ip string) bool {
+	ipLimiters.Lock()
+	defer ipLimiters.Unlock()
+	now := time.Now()
+	if now.Sub(ipLimiters.swept) > limiterIdle {
+		for key, l := range ipLimiters.byIP {
+			if now.Sub(l.seen) > limiterIdle {
+				delete(ipLimiters.byIP, key)
+			}
+		}
+		ipLimiters.swept = now
+	}
+	l := ipLimiters.byIP[ip]
+	if l == nil {
+		l = &ipLimiter{limiter: rate.NewLimiter(rate.Limit(rateLimit), rateBurst)}
+		ipLimiters.byIP[ip] = l
+	}
+	l.seen = now
+	return l.limiter.Allow()
+}
+
+// limitRequests rejects clients exceeding the request rate with 429 and
+// caps request bodies at maxMessageSize. Clients are told apart by their
+// address, the forwarded one behind a trusted proxy (see fromProxy).
+func limitRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rateLimit > 0 {
+			if !allowRequest(clientIP(r)) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(1/rateLimit))))
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+		}
+		if maxMessageSize > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, int64(maxMessageSize))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,54 +1,285 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"go/parser"
-	"go/token"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
-	"golang.org/x/mod/modfile"
-)
-
-type Node struct {
-	ID    string  `json:"id"`
-	Label string  `json:"label"`
-	X     float64 `json:"x"`
-	Y     float64 `json:"y"`
-	VX    float64 `json:"vx"`
-	VY    float64 `json:"vy"`
-	Type  string  `json:"type"`
-	Depth int     `json:"depth"`
-}
-
-type Edge struct {
-	Source string `json:"source"`
-	Target string `json:"target"`
-}
 
-type Graph struct {
-	Nodes []Node `json:"nodes"`
-	Edges []Edge `json:"edges"`
-}
+	"go-raph/depgraph"
+)
 
 var (
-	upgrader   = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
-	targetPath string
+	upgrader = websocket.Upgrader{
+		CheckOrigin:       func(r *http.Request) bool { return true },
+		EnableCompression: true,
+		Subprotocols:      []string{msgpackProtocol},
+	}
+	targetPath    string
+	trackGenerics bool
+	trackEmbeds   bool
+	trackProtos   bool
+	trackDocker   bool
+	trackServices bool
+	trackGenerate bool
+	fileNodes     bool // -granularity file
+	internalOnly  bool
+	externalOnly  bool
+	pluginPaths   []string
+	baseRef       string
+	watchMode     bool
 )
 
+// defaultTarget is the path analyzed without -path or a path argument:
+// $GORAPH_PATH, which the container image sets to its mount point, or the
+// current directory.
+var defaultTarget = cmp.Or(os.Getenv("GORAPH_PATH"), ".")
+
 func main() {
-	flag.StringVar(&targetPath, "path", ".", "Path to analyze")
+	// Subcommands have their own flag sets
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			// The server is the default; the name reads better with -from
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "publish":
+			publishCommand(os.Args[2:])
+			return
+		case "why":
+			whyCommand(os.Args[2:])
+			return
+		case "merge":
+			mergeCommand(os.Args[2:])
+			return
+		case "org":
+			orgCommand(os.Args[2:])
+			return
+		case "inventory":
+			inventoryCommand(os.Args[2:])
+			return
+		case "migrate-report":
+			migrateCommand(os.Args[2:])
+			return
+		case "mvs":
+			mvsCommand(os.Args[2:])
+			return
+		case "check":
+			checkCommand(os.Args[2:])
+			return
+		case "approve":
+			approveCommand(os.Args[2:])
+			return
+		case "share":
+			shareCommand(os.Args[2:])
+			return
+		case "tui":
+			tuiCommand(os.Args[2:])
+			return
+		case "validate":
+			validateCommand(os.Args[2:])
+			return
+		case "self-update":
+			selfUpdateCommand(os.Args[2:])
+			return
+		case "version":
+			versionCommand(os.Args[2:])
+			return
+		case "daemon":
+			daemonCommand(os.Args[2:])
+			return
+		case "config":
+			configCommand(os.Args[2:])
+			return
+		case "openapi":
+			openAPICommand()
+			return
+		case "analyze-worker":
+			analyzeWorkerCommand()
+			return
+		}
+	}
+
+	flag.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
 	port := flag.String("port", "8080", "Server port")
-	flag.Parse()
+	format := flag.String("format", "", "Write the graph in this format instead of serving it (json, markdown-summary, split-markdown, split-json, duplicates-markdown, duplicates-json, skew-markdown, skew-json, text-tree, cypher, parquet, sqlite)")
+	output := flag.String("o", "", "Output file for -format (default stdout), directory for parquet")
+	flag.Func("ids", "Node IDs of json, cypher, parquet, sqlite and -neo4j exports: compact (default), or structured kind/scope/path IDs that never collide", func(scheme string) error {
+		switch scheme {
+		case "compact", "structured":
+			structuredIDs = scheme == "structured"
+			return nil
+		}
+		return fmt.Errorf("unknown ID scheme %q", scheme)
+	})
+	flag.StringVar(&neo4jURL, "neo4j", "", "Merge the graph into this Neo4j server (bolt://host:7687) instead of serving it; credentials come from NEO4J_USERNAME and NEO4J_PASSWORD")
+	flag.StringVar(&baseRef, "base", "", "Git ref to compare against in reports, e.g. origin/main")
+	flag.StringVar(&subgraphRoot, "root", "", "Only export the neighborhood of this node ID, e.g. pkg:internal/auth")
+	flag.IntVar(&subgraphDepth, "depth", subgraphDepth, "Edges to follow from -root (negative for unlimited)")
+	flag.StringVar(&subgraphDirection, "direction", subgraphDirection, "Follow edges from -root: out (dependencies), in (dependents) or both")
+	flag.BoolVar(&watchMode, "watch", false, "Re-analyze and push updates to clients when files change")
+	flag.DurationVar(&watchDebounce, "debounce", watchDebounce, "Wait for changes to settle this long before re-analyzing in watch mode")
+	flag.DurationVar(&watchMinInterval, "min-interval", watchMinInterval, "Minimum time between re-analyses in watch mode")
+	flag.BoolVar(&collapseImports, "collapse-imports", false, "Fold the import path nodes of external modules into the module nodes; viewers can expand modules one by one")
+	flag.IntVar(&chunkSize, "chunk-size", chunkSize, "Send graphs with more nodes and edges than this in chunks over the WebSocket (0 disables)")
+	flag.IntVar(&maxDirectDeps, "max-direct-deps", 0, "Alert when direct dependencies exceed this count in watch mode")
+	flag.IntVar(&maxDepth, "max-depth", 0, "Alert when the longest import chain exceeds this length in watch mode")
+	flag.BoolVar(&noNewCopyleft, "no-new-copyleft", false, "Alert when a copyleft-licensed module is added in watch mode")
+	flag.BoolVar(&pprofEnabled, "pprof", false, "Serve net/http/pprof profiles at /debug/pprof/")
+	flag.StringVar(&cpuProfile, "cpuprofile", "", "Write a CPU profile of -format or -neo4j runs to this file")
+	flag.StringVar(&memProfile, "memprofile", "", "Write a heap profile at the end of -format or -neo4j runs to this file")
+	flag.StringVar(&listenAddr, "listen", "", "Listen on this address instead of -port: host:port, or unix:/path/to/socket behind a reverse proxy")
+	flag.Func("trusted-proxy", "Trust the X-Forwarded-* headers of reverse proxies at this address or CIDR, may be repeated; unix socket and loopback peers are always trusted", addTrustedProxy)
+	flag.Func("base-path", "Serve every route under this path, e.g. /raph/ behind a reverse proxy (default /)", setBasePath)
+	flag.BoolVar(&strictPort, "strict-port", false, "Exit when the port is in use instead of serving on a free one")
+	flag.BoolVar(&openBrowser, "open", openBrowser, "Open the visualizer in the default browser once the server listens, by default when run in a terminal")
+	flag.BoolVar(&allowWrite, "allow-write", false, "Let the browser edit go.mod: remove unused requirements and upgrade modules to their latest version")
+	flag.BoolVar(&shareOnly, "share-only", false, "Only serve /shared/{token} links, for exposing the server to people outside the team")
+	flag.StringVar(&editor, "editor", editor, "How the browser's jump to definition opens files: vscode, vscode-remote, editor (editor:// links) or a command run on this machine, e.g. \"idea {file}\"")
+	flag.Func("webhook", "POST threshold alerts to this URL (Slack or generic), may be repeated", func(url string) error {
+		webhookURLs = append(webhookURLs, url)
+		return nil
+	})
+	addLimitFlags(flag.CommandLine)
+	addAnalysisFlags(flag.CommandLine)
+	parseFlags(flag.CommandLine, os.Args[1:])
+
+	goOffline()
+	loadPlugins()
+	resolveTarget(flag.CommandLine)
+	loadSavedGraph()
+
+	if *format != "" {
+		defer startProfiles()()
+		exportGraph(*format, *output)
+		return
+	}
+	if neo4jURL != "" {
+		defer startProfiles()()
+		uploadGraph()
+		return
+	}
+
+	// Validate port
+	if portNum, err := strconv.Atoi(*port); err != nil || portNum < 1 || portNum > 65535 {
+		fmt.Printf(tr("⚠️ Invalid port '%s', defaulting to 8084\n"), *port)
+		*port = "8084"
+	}
+
+	if watchMode && graphFrom != "" {
+		fmt.Println(tr("❌ -watch cannot serve a saved graph"))
+		os.Exit(1)
+	}
+	if watchMode && workspaceMode {
+		fmt.Println(tr("⚠️ Watch mode analyzes a single module, go.work is ignored"))
+	}
+	if watchMode {
+		startWatch()
+	} else if graphFrom == "" {
+		go func() {
+			if graph, err := analyzeGraph(context.Background()); err == nil {
+				recordMetrics(context.Background(), graph)
+			}
+		}()
+	}
+
+	registerHandlers()
+
+	if graphFrom != "" {
+		fmt.Printf(tr("📂 Serving saved graph: %s\n"), graphFrom)
+	} else {
+		fmt.Printf(tr("🎨 Analyzing: %s\n"), targetPath)
+	}
+	ln, url := listen(*port)
+	fmt.Printf(tr("🌐 Visualizer: %s\n"), url)
+
+	serve(ln, url)
+}
+
+// registerHandlers sets up the visualizer and its API on the default mux.
+func registerHandlers() {
+	if shareOnly {
+		http.HandleFunc("/shared/{token}", gzipped(sharedHandler))
+		return
+	}
+	http.HandleFunc("/", indexHandler)
+	http.HandleFunc("/ws", websocketHandler)
+	http.HandleFunc("/badge/", badgeHandler)
+	for _, e := range apiEndpoints() {
+		http.HandleFunc(e.Path, e.Handler)
+	}
+	http.HandleFunc("/api/openapi.json", openAPIHandler)
+	http.HandleFunc("/graphql", gzipped(graphqlHandler))
+	http.HandleFunc("/replay/{id}", replayHandler)
+	http.HandleFunc("/shared/{token}", gzipped(sharedHandler))
+}
+
+// addAnalysisFlags registers the flags shared by the server and subcommands.
+func addAnalysisFlags(fs *flag.FlagSet) {
+	before := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) { before[f.Name] = true })
+	defer recordFlags(fs, before)
+	fs.BoolVar(&trackGenerics, "generics", false, "Parse full files to add edges for generic instantiations")
+	fs.BoolVar(&trackEmbeds, "embeds", false, "Add asset nodes for //go:embed directives")
+	fs.BoolVar(&trackProtos, "protos", false, "Add nodes for .proto files linked to the Go packages generated from them")
+	fs.BoolVar(&trackDocker, "docker", false, "Add nodes for Dockerfile images, their base images and the binaries copied into them")
+	fs.BoolVar(&trackGenerate, "generate", false, "Add tool nodes for the code generators //go:generate directives run")
+	fs.BoolVar(&trackServices, "k8s", false, "Add Kubernetes services from manifests and Helm charts, linked to the binaries calling and serving them")
+	fs.Func("granularity", "Node granularity: package, or file to add a node per Go file (default package)", func(granularity string) error {
+		switch granularity {
+		case "package", "file":
+			fileNodes = granularity == "file"
+			return nil
+		}
+		return fmt.Errorf("unknown granularity %q", granularity)
+	})
+	fs.BoolVar(&internalOnly, "internal-only", false, "Only show the main module's packages and the imports between them, hiding third-party modules")
+	fs.BoolVar(&externalOnly, "external-only", false, "Only show the module dependency graph, direct and transitive, hiding packages and import paths")
+	fs.Func("plugin", "Load an analyzer plugin (.so), may be repeated", func(path string) error {
+		pluginPaths = append(pluginPaths, path)
+		return nil
+	})
+	fs.Func("symbols", "List the identifiers of this module each import edge uses, may be repeated", func(module string) error {
+		symbolModules = append(symbolModules, module)
+		return nil
+	})
+	fs.BoolVar(&workspaceMode, "workspace", false, "Analyze every module of the target's go.work file and merge them, naming packages after their module")
+	fs.BoolVar(&previewTidy, "tidy", false, "Annotate the requirements `go mod tidy` would add, remove or mark indirect, without changing go.mod")
+	fs.StringVar(&graphFrom, "from", "", "Use the graph saved in this JSON file instead of analyzing the target")
+	fs.StringVar(&graphSave, "save", "", "Write the graph to this JSON file after every analysis")
+	fs.Func("baseline", "Mark the nodes and edges not in the graph saved in this JSON file as new", loadBaseline)
+	fs.StringVar(&vulnDB, "vulndb", vulnDB, "Vulnerability database URL")
+	fs.Func("modcache", "Module cache to use and download missing modules into, e.g. a container volume (default $GORAPH_MODCACHE, else the go command's)", setModCache)
+	addOfflineFlag(fs)
+	fs.BoolVar(&verifySums, "verify", false, "Check the modules in the module cache against go.sum like `go mod verify`, adding a security-alert node for each mismatch")
+	fs.BoolVar(&trackReleases, "releases", false, "Annotate external modules with their last release and release cadence from the module proxy")
+	fs.IntVar(&abandonedYears, "abandoned-after", abandonedYears, "With -releases, flag modules without a release in this many years as abandoned (0 disables)")
+	fs.BoolVar(&trackScorecard, "scorecard", false, "Annotate external modules hosted on GitHub or GitLab with their OpenSSF Scorecard score")
+	fs.BoolVar(&trackMaintainers, "maintainers", false, "Annotate external modules hosted on GitHub with their bus factor and flag single-maintainer direct dependencies")
+	fs.Func("categories", "Add module categories for duplicate detection from a JSON file of category names to module paths", loadCategories)
+	addLangFlag(fs)
+	fs.StringVar(&configPath, "config", "", "Configuration file, e.g. for the theme (default goraph.yaml in the analyzed directory)")
+	fs.Func("color-by", "Color nodes by type, owner, cluster, license, sumdb (checksum database coverage), staleness or size (default type)", func(dimension string) error {
+		if _, ok := colorDimensions[dimension]; !ok {
+			return fmt.Errorf("unknown dimension %q", dimension)
+		}
+		colorBy = dimension
+		return nil
+	})
+}
 
+// resolveTarget applies the positional path argument and makes sure the
+// target exists, exiting otherwise.
+func resolveTarget(fs *flag.FlagSet) {
 	// Process positional arguments (overrides flags)
-	args := flag.Args()
+	args := fs.Args()
 	if len(args) > 0 {
 		targetPath = args[0]
 	}
@@ -56,32 +287,29 @@ func main() {
 	// Validate and fix empty path
 	if targetPath == "" {
 		targetPath = "."
-		fmt.Println("⚠️ Empty path provided, defaulting to current directory")
+		fmt.Println(tr("⚠️ Empty path provided, defaulting to current directory"))
 	}
 
-	// Validate port
-	if portNum, err := strconv.Atoi(*port); err != nil || portNum < 1 || portNum > 65535 {
-		fmt.Printf("⚠️ Invalid port '%s', defaulting to 8084\n", *port)
-		*port = "8084"
+	// Package patterns scope the analysis of their module
+	if root, patterns, ok := packagePattern(targetPath); ok {
+		targetPath, packagePatterns = root, patterns
+	}
+
+	if internalOnly && externalOnly {
+		fmt.Println(tr("❌ -internal-only and -external-only cannot be combined"))
+		os.Exit(1)
 	}
 
 	// Check if target path exists
 	if _, err := os.Stat(targetPath); os.IsNotExist(err) {
-		fmt.Printf("❌ Path '%s' does not exist\n", targetPath)
+		fmt.Printf(tr("❌ Path '%s' does not exist\n"), targetPath)
 		os.Exit(1)
 	}
-
-	http.HandleFunc("/", indexHandler)
-	http.HandleFunc("/ws", websocketHandler)
-
-	fmt.Printf("🎨 Analyzing: %s\n", targetPath)
-	fmt.Printf("🌐 Visualizer: http://localhost:%s\n", *port)
-
-	log.Fatal(http.ListenAndServe(":"+*port, nil))
 }
 
 func indexHandler(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, "index.html")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(visualizerPage(r))
 }
 
 func websocketHandler(w http.ResponseWriter, r *http.Request) {
@@ -90,206 +318,232 @@ func websocketHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer conn.Close()
+	if maxMessageSize > 0 {
+		conn.SetReadLimit(int64(maxMessageSize))
+	}
+
+	c := &client{conn: conn, binary: conn.Subprotocol() == msgpackProtocol, id: nextClientID(), base: pageBase(r), trusted: sameOrigin(r)}
+	register(c)
+	defer unregister(c)
+	defer leavePresence(c)
+	defer saveUnfinished(c)
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	done := make(chan struct{})
+	defer close(done)
+	go c.keepAlive(done)
 
 	// Send initial graph on connection
-	graph, err := analyzeProject(targetPath)
+	graph, err := currentGraph(r.Context())
 	if err != nil {
-		conn.WriteJSON(map[string]interface{}{"error": err.Error()})
+		c.send(map[string]interface{}{"error": err.Error()})
 		return
 	}
 
-	conn.WriteJSON(map[string]interface{}{"graph": graph})
+	sendGraph(c, graph)
+	c.send(map[string]interface{}{"notes": projectNotes()})
+	sendPresences(c)
 
-	// Keep connection alive
+	// Handle client messages until the connection closes
 	for {
-		if _, _, err := conn.ReadMessage(); err != nil {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
 			break
 		}
+		var msg clientMessage
+		if json.Unmarshal(data, &msg) == nil {
+			c.recordCommand(msg)
+			handleMessage(c, msg)
+		}
 	}
 }
 
-func analyzeProject(projectPath string) (*Graph, error) {
-	graph := &Graph{Nodes: []Node{}, Edges: []Edge{}}
-	nodeMap := make(map[string]*Node)
-	moduleToImporter := make(map[string][]string) // track which packages import each module
-	directModules := make(map[string]bool)        // track direct vs indirect modules
-	usedModules := make(map[string]bool)          // track modules that are actually imported
-
-	// Parse go.mod if exists
-	modPath := filepath.Join(projectPath, "go.mod")
-	var mainModule string
-	availableModules := make(map[string]bool)
-
-	if data, err := os.ReadFile(modPath); err == nil {
-		if modFile, err := modfile.Parse("go.mod", data, nil); err == nil {
-			mainModule = modFile.Module.Mod.Path
-
-			// Add main module
-			addNode(graph, nodeMap, mainModule, mainModule, "main", 0)
-
-			// Track available external modules
-			for _, req := range modFile.Require {
-				availableModules[req.Mod.Path] = true
-				directModules[req.Mod.Path] = !req.Indirect
-			}
-		}
-	}
+// clientMessage is a command sent by the browser over the WebSocket.
+type clientMessage struct {
+	Type      string              `json:"type"`
+	Positions map[string]position `json:"positions,omitempty"`
+	IDs       []string            `json:"ids,omitempty"`
+	Module    string              `json:"module,omitempty"`
+	Node      string              `json:"node,omitempty"`
+	Text      string              `json:"text,omitempty"`
+	Author    string              `json:"author,omitempty"`
+	NoteID    string              `json:"noteId,omitempty"`
+	Hovered   string              `json:"hovered,omitempty"`
+	Limit     int                 `json:"limit,omitempty"`
+}
 
-	// Parse Go files
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		// Skip vendor folder unless explicitly included
-		skipVendor := strings.Contains(path, "vendor/")
-		if err != nil || !strings.HasSuffix(path, ".go") || skipVendor {
-			return err
+func handleMessage(c *client, msg clientMessage) {
+	switch msg.Type {
+	case "positions":
+		// Nodes dragged by a user are pinned for everyone and across
+		// restarts, so other sites must not move them
+		if !c.trusted {
+			c.send(map[string]interface{}{"error": "the layout can only be changed from go-raph's own page"})
+			return
 		}
-
-		fset := token.NewFileSet()
-		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err := saveLayout(msg.Positions); err != nil {
+			log.Printf(tr("⚠️ Saving layout failed: %v"), err)
+		}
+		repinSnapshot(context.Background())
+		broadcastExcept(c, map[string]interface{}{"positions": msg.Positions})
+	case "reset-layout":
+		if !c.trusted {
+			c.send(map[string]interface{}{"error": "the layout can only be changed from go-raph's own page"})
+			return
+		}
+		if err := saveLayout(nil); err != nil {
+			log.Printf(tr("⚠️ Resetting layout failed: %v"), err)
+		}
+		repinSnapshot(context.Background())
+		broadcastExcept(c, map[string]interface{}{"resetLayout": true})
+	case "simulate-remove":
+		// What-if answers only go to the client exploring the refactor
+		graph, err := currentGraph(context.Background())
 		if err != nil {
-			return nil
+			c.send(map[string]interface{}{"error": err.Error()})
+			return
 		}
-
-		relPath := strings.TrimPrefix(filepath.Dir(path), projectPath+"/")
-		if relPath == "." || relPath == "" {
-			relPath = "root"
+		c.send(map[string]interface{}{"simulation": map[string]interface{}{
+			"removed":     msg.IDs,
+			"unreachable": graph.Unreachable(msg.IDs),
+		}})
+	case "hot-paths":
+		subscribeHotPaths(c, msg.Limit)
+	case "record":
+		toggleRecording(c)
+	case "presence":
+		updatePresence(c, msg)
+	case "add-note", "delete-note":
+		// Notes are shared by everyone reviewing the graph and persisted,
+		// so other sites must not write them
+		if !c.trusted {
+			c.send(map[string]interface{}{"error": "notes can only be changed from go-raph's own page"})
+			return
 		}
-
-		packageID := "pkg:" + relPath
-		// Use directory name for label to avoid confusion with main module
-		displayName := filepath.Base(relPath)
-		if displayName == "root" {
-			displayName = "main"
+		list, err := changeNotes(msg)
+		if err != nil {
+			c.send(map[string]interface{}{"error": err.Error()})
+			return
 		}
-		addNode(graph, nodeMap, packageID, displayName, "package", 0)
-
-		// Process imports
-		for _, imp := range file.Imports {
-			importPath := strings.Trim(imp.Path.Value, `"`)
+		broadcast(map[string]interface{}{"notes": list})
+	case "remove-requirement", "upgrade-module":
+		// Other sites can open the WebSocket too, but must not edit go.mod
+		if !c.trusted {
+			c.send(map[string]interface{}{"edit": map[string]interface{}{"error": "go.mod can only be edited from go-raph's own page"}})
+			return
+		}
+		// Edits run go list and go mod download; keep reading messages
+		go applyGoModEdit(c, msg.Type, msg.Module)
+	}
+}
 
-			// Skip standard library (packages without dots that aren't internal imports)
-			if !strings.Contains(importPath, ".") && !strings.HasPrefix(importPath, mainModule) {
-				continue
-			}
+// startWatch re-analyzes the target on every change, checks alert
+// thresholds and pushes the new graph to all connected clients. Only the
+// changed packages are parsed again. The latest graph is kept as the
+// snapshot served to new connections and requests.
+func startWatch() {
+	ctx := context.Background()
+	inc := depgraph.NewIncremental(os.DirFS(targetPath), analyzeOptions())
+	graph, err := inc.Update()
+	if err != nil {
+		fmt.Printf(tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+	graph = enrichGraph(ctx, graph)
+	setSnapshot(graph)
+	alerts := newAlerter(graph)
+	go recordMetrics(ctx, graph)
 
-			if strings.HasPrefix(importPath, mainModule) {
-				// Internal import - connect packages directly, no separate import nodes
-				// Find or create the target package
-				targetRelPath := strings.TrimPrefix(importPath, mainModule+"/")
-				if targetRelPath == importPath {
-					// This is importing the main module itself, skip
-					continue
-				}
-
-				targetPackageID := "pkg:" + targetRelPath
-				targetDisplayName := filepath.Base(targetRelPath)
-				addNode(graph, nodeMap, targetPackageID, targetDisplayName, "package", 0)
-				addEdge(graph, packageID, targetPackageID)
-			} else {
-				// External import - find the best matching module (longest prefix)
-				var rootModule string
-				var maxLength int
-				for modulePath := range availableModules {
-					if strings.HasPrefix(importPath, modulePath) && len(modulePath) > maxLength {
-						rootModule = modulePath
-						maxLength = len(modulePath)
-					}
-				}
-
-				if rootModule != "" {
-					// Mark this module as actually used
-					usedModules[rootModule] = true
-
-					// Add module node if not exists
-					addNode(graph, nodeMap, rootModule, rootModule, "external", 2)
-
-					// Track that this package imports this module
-					moduleToImporter[rootModule] = append(moduleToImporter[rootModule], packageID)
-
-					// If import path exactly matches the module root, connect directly to module
-					if importPath == rootModule {
-						addEdge(graph, packageID, rootModule)
-					} else {
-						// Create separate import node for sub-packages
-						importID := "import:" + importPath
-						// Use full import path for external dependencies, not just base name
-						importLabel := importPath
-						// If it's too long, show module + last part
-						if len(importPath) > 40 {
-							parts := strings.Split(importPath, "/")
-							if len(parts) > 2 {
-								// Show first part (module) + last part
-								importLabel = parts[0] + "/.../" + parts[len(parts)-1]
-							}
-						}
-						addNode(graph, nodeMap, importID, importLabel, "external", 1)
-						addEdge(graph, packageID, importID)
-
-						// Connect import to its root module
-						addEdge(graph, importID, rootModule)
-					}
-				}
-			}
+	err = watchProject(targetPath, func(dirs []string) {
+		graph, err := inc.Update(dirs...)
+		if err != nil {
+			log.Printf(tr("⚠️ Re-analysis failed: %v"), err)
+			return
 		}
-
-		return nil
+		graph = enrichGraph(ctx, graph)
+		setSnapshot(graph)
+		alerts.check(graph)
+		broadcastGraph(graph)
+		recordMetrics(ctx, graph)
 	})
+	if err != nil {
+		fmt.Printf(tr("❌ Cannot watch '%s': %v\n"), targetPath, err)
+		os.Exit(1)
+	}
+	fmt.Println(tr("👀 Watching for changes"))
+}
 
-	// ONLY connect modules that are actually used in imports
-	for modulePath := range usedModules {
-		if directModules[modulePath] {
-			// Direct dependency that's actually imported - connect to main
-			addEdge(graph, mainModule, modulePath)
-		} else {
-			// Indirect dependency that's actually imported - find best parent
-			connected := false
-			for directModule := range directModules {
-				if directModules[directModule] && usedModules[directModule] {
-					// Check if this indirect module is likely a sub-dependency
-					if strings.HasPrefix(modulePath, strings.Split(directModule, "/")[0]) ||
-						strings.Contains(modulePath, strings.Split(directModule, "/")[0]) {
-						addEdge(graph, directModule, modulePath)
-						connected = true
-						break
-					}
-				}
-			}
-			// If we can't find a good parent, don't connect it to avoid orphans
-			if !connected {
-				// Remove the orphaned module to avoid yellow dots
-				for i, node := range graph.Nodes {
-					if node.ID == modulePath {
-						// Remove node
-						graph.Nodes = append(graph.Nodes[:i], graph.Nodes[i+1:]...)
-						delete(nodeMap, modulePath)
-						break
-					}
-				}
-			}
-		}
+// currentGraph returns a graph of the target the caller may modify: a copy
+// of the watch mode snapshot, or a fresh analysis otherwise.
+func currentGraph(ctx context.Context) (*depgraph.Graph, error) {
+	if graph := getSnapshot(); graph != nil {
+		return graph.Clone(), nil
 	}
+	return analyzeGraph(ctx)
+}
 
-	return graph, err
+// analyzeGraph analyzes the target and lets the registered analyzers enrich
+// the result.
+func analyzeGraph(ctx context.Context) (*depgraph.Graph, error) {
+	release, err := acquireAnalysis(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	var graph *depgraph.Graph
+	if sandboxAnalysis {
+		graph, err = analyzeSandboxed(ctx, targetPath)
+	} else {
+		graph, err = analyzeProject(targetPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return enrichGraph(ctx, graph), nil
 }
 
-func addNode(graph *Graph, nodeMap map[string]*Node, id, label, nodeType string, depth int) {
-	if _, exists := nodeMap[id]; !exists {
-		node := Node{
-			ID:    id,
-			Label: label,
-			Type:  nodeType,
-			Depth: depth,
-		}
-		graph.Nodes = append(graph.Nodes, node)
-		nodeMap[id] = &graph.Nodes[len(graph.Nodes)-1]
+// enrichGraph runs the registered analyzers and colors the graph.
+func enrichGraph(ctx context.Context, graph *depgraph.Graph) *depgraph.Graph {
+	if externalOnly {
+		graph = moduleOverview(ctx, graph)
 	}
+	reportDiagnostics(graph)
+	applyLabels(graph)
+	markPrivate(graph)
+	downloadMissing(ctx, graph)
+	runAnalyzers(ctx, graph)
+	graph.Sort() // analyzers may have added nodes or edges
+	repairGraph(graph)
+	markNew(graph)
+	applyColors(graph, colorBy)
+	stampProvenance(graph)
+	saveGraph(graph)
+	return graph
 }
 
-func addEdge(graph *Graph, source, target string) {
-	// Prevent duplicate edges
-	for _, edge := range graph.Edges {
-		if edge.Source == source && edge.Target == target {
-			return
+func analyzeProject(projectPath string) (*depgraph.Graph, error) {
+	if workspaceMode {
+		if dirs := workspaceModules(projectPath); dirs != nil {
+			return analyzeWorkspace(dirs)
 		}
 	}
-	graph.Edges = append(graph.Edges, Edge{Source: source, Target: target})
+	return depgraph.Analyze(os.DirFS(projectPath), analyzeOptions())
+}
+
+func analyzeOptions() depgraph.Options {
+	return depgraph.Options{
+		Generics:     trackGenerics,
+		Embeds:       trackEmbeds,
+		Protos:       trackProtos,
+		Docker:       trackDocker,
+		Services:     trackServices,
+		Generate:     trackGenerate,
+		Files:        fileNodes,
+		Excludes:     gitExcludes(),
+		Packages:     packagePatterns,
+		InternalOnly: internalOnly,
+	}
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+var trackMaintainers bool // -maintainers
+
+var (
+	depsDevAPI = "https://api.deps.dev"
+	githubAPI  = "https://api.github.com"
+)
+
+func init() {
+	depgraph.Register(maintainersAnalyzer{})
+}
+
+// maintainersAnalyzer annotates external modules hosted on GitHub with their
+// bus factor: the fewest contributors together authoring half the commits.
+// Direct dependencies with a bus factor of one are flagged as single
+// maintainer risks. It only runs with -maintainers.
+type maintainersAnalyzer struct{}
+
+func (maintainersAnalyzer) Name() string { return "maintainers" }
+
+func (maintainersAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if !trackMaintainers {
+		return nil
+	}
+	versions := make(map[string]string)
+	mainModules := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		versions[node.ID] = node.Version
+		mainModules[node.ID] = node.Type == "main"
+	}
+	direct := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		if mainModules[edge.Source] {
+			direct[edge.Target] = true
+		}
+	}
+
+	factors := lookupModules(ctx, graph, func(ctx context.Context, path string) int {
+		return busFactor(ctx, path, versions[path])
+	})
+	for path, factor := range factors {
+		if factor <= 0 {
+			continue
+		}
+		graph.Annotate(path, "bus-factor", strconv.Itoa(factor))
+		if factor == 1 && direct[path] {
+			graph.Annotate(path, "risk", "single maintainer")
+		}
+	}
+	return nil
+}
+
+// busFactors caches answers for the life of the process.
+var busFactors sync.Map // repository -> int
+
+// busFactor estimates the bus factor of the repository a module version was
+// built from, or returns 0 when it is unknown. The repository comes from
+// deps.dev, or is guessed from the module path.
+func busFactor(ctx context.Context, path, version string) int {
+	repo, ok := sourceRepo(ctx, path, version)
+	if !ok || !strings.HasPrefix(repo, "github.com/") {
+		return 0
+	}
+	if v, ok := busFactors.Load(repo); ok {
+		return v.(int)
+	}
+	var contributors []struct{ Contributions int }
+	if err := githubJSON(ctx, githubAPI+"/repos/"+strings.TrimPrefix(repo, "github.com/")+"/contributors?per_page=100", &contributors); err != nil {
+		return 0
+	}
+	total := 0
+	for _, c := range contributors {
+		total += c.Contributions
+	}
+	// Contributors are listed by commit count, most first
+	factor, sum := 0, 0
+	for _, c := range contributors {
+		if 2*sum >= total {
+			break
+		}
+		sum += c.Contributions
+		factor++
+	}
+	busFactors.Store(repo, factor)
+	return factor
+}
+
+// sourceRepo returns the repository deps.dev links a module version to,
+// falling back to moduleRepo.
+func sourceRepo(ctx context.Context, path, version string) (string, bool) {
+	var info struct {
+		RelatedProjects []struct {
+			ProjectKey   struct{ ID string }
+			RelationType string
+		}
+	}
+	u := depsDevAPI + "/v3/systems/go/packages/" + url.PathEscape(path) + "/versions/" + url.PathEscape(version)
+	if version != "" && fetchJSON(ctx, u, &info) == nil {
+		for _, p := range info.RelatedProjects {
+			if p.RelationType == "SOURCE_REPO" {
+				return p.ProjectKey.ID, true
+			}
+		}
+	}
+	return moduleRepo(path)
+}
+
+// githubJSON GETs a GitHub API document, authenticated with $GITHUB_TOKEN
+// when set to get past the low anonymous rate limit.
+func githubJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// writeMarkdownSummary writes a compact report meant to be posted as a pull
+// request comment: direct dependencies, module changes against -base, import
+// cycles, modules the checksum database does not cover and known
+// vulnerabilities.
+func writeMarkdownSummary(w io.Writer, graph *depgraph.Graph) error {
+	metrics := computeMetrics(graph)
+	importers, _ := moduleImporters(graph)
+	modules := moduleVersions(graph)
+
+	var mainModule string
+	for _, node := range graph.Nodes {
+		if node.Type == "main" {
+			mainModule = node.ID
+		}
+	}
+
+	fmt.Fprintf(w, "## go-raph dependency summary\n\n")
+	fmt.Fprintf(w, "`%s` · %d packages · %d external modules (%d direct)\n\n",
+		mainModule, metrics.Packages, metrics.Modules, metrics.DirectDeps)
+
+	fmt.Fprintf(w, "### direct dependencies\n\n")
+	var direct []string
+	for _, edge := range graph.Edges {
+		if edge.Source == mainModule && edge.Kind == "" {
+			direct = append(direct, edge.Target)
+		}
+	}
+	sort.Strings(direct)
+	if len(direct) == 0 {
+		fmt.Fprintf(w, "none\n\n")
+	} else {
+		fmt.Fprintf(w, "| module | version | imported by packages |\n|---|---|---|\n")
+		for _, module := range direct {
+			fmt.Fprintf(w, "| `%s` | %s | %d |\n", module, modules[module], len(importers[module]))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if baseRef != "" {
+		fmt.Fprintf(w, "### changes since `%s`\n\n", baseRef)
+		base, err := analyzeRef(targetPath, baseRef)
+		if err != nil {
+			fmt.Fprintf(w, "⚠️ could not analyze base: %v\n\n", err)
+		} else {
+			writeModuleChanges(w, moduleVersions(base), modules)
+		}
+	}
+
+	fmt.Fprintf(w, "### import cycles\n\n")
+	cycles := graph.Cycles()
+	if len(cycles) == 0 {
+		fmt.Fprintf(w, "none\n\n")
+	}
+	for _, cycle := range cycles {
+		fmt.Fprintf(w, "- `%s`\n", strings.Join(cycle, "` ↔ `"))
+	}
+	if len(cycles) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "### checksum database\n\n")
+	var unchecked []*depgraph.Node
+	for i := range graph.Nodes {
+		if unverified(&graph.Nodes[i]) {
+			unchecked = append(unchecked, &graph.Nodes[i])
+		}
+	}
+	if len(unchecked) == 0 {
+		fmt.Fprintf(w, "every module is verified by %s\n\n", cmp.Or(sumdbName(), "a checksum database"))
+	} else {
+		fmt.Fprintf(w, "| unverified module | version | excluded by |\n|---|---|---|\n")
+		for _, node := range unchecked {
+			fmt.Fprintf(w, "| `%s` | %s | %s |\n", node.ID, node.Version, strings.TrimPrefix(node.Annotations["sumdb"], "unverified: "))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "### vulnerabilities\n\n")
+	vulns, err := scanVulns(context.Background(), graph)
+	switch {
+	case err != nil:
+		fmt.Fprintf(w, "⚠️ lookup failed: %v\n", err)
+	case len(vulns) == 0:
+		fmt.Fprintf(w, "none known\n")
+	default:
+		fmt.Fprintf(w, "| module | id | summary | fixed in |\n|---|---|---|---|\n")
+		var affected []string
+		for module := range vulns {
+			affected = append(affected, module)
+		}
+		sort.Strings(affected)
+		for _, module := range affected {
+			for _, v := range vulns[module] {
+				fmt.Fprintf(w, "| `%s` %s | %s | %s | %s |\n", module, modules[module], v.ID, v.Summary, v.Fixed)
+			}
+		}
+	}
+	return nil
+}
+
+// moduleVersions maps external module paths in graph to their versions.
+func moduleVersions(graph *depgraph.Graph) map[string]string {
+	versions := make(map[string]string)
+	for _, node := range graph.Nodes {
+		if isExternal(&node) && !strings.HasPrefix(node.ID, "import:") {
+			versions[node.ID] = node.Version
+		}
+	}
+	return versions
+}
+
+func writeModuleChanges(w io.Writer, base, head map[string]string) {
+	changes := make(map[string]string) // module -> line
+	for module, version := range head {
+		baseVersion, ok := base[module]
+		switch {
+		case !ok:
+			changes[module] = fmt.Sprintf("- ➕ `%s` %s", module, version)
+		case baseVersion != version:
+			changes[module] = fmt.Sprintf("- 🔄 `%s` %s → %s", module, baseVersion, version)
+		}
+	}
+	for module, version := range base {
+		if _, ok := head[module]; !ok {
+			changes[module] = fmt.Sprintf("- ➖ `%s` %s", module, version)
+		}
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "no module changes\n\n")
+		return
+	}
+	var changed []string
+	for module := range changes {
+		changed = append(changed, module)
+	}
+	sort.Strings(changed)
+	for _, module := range changed {
+		fmt.Fprintln(w, changes[module])
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+// mergeCommand implements `go-raph merge a.json b.json ...`, the union of
+// graphs saved from several projects.
+func mergeCommand(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	output := fs.String("o", "", "Output file (default stdout)")
+	addLangFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: go-raph merge [-o merged.json] <graph.json>...")
+		fs.PrintDefaults()
+	}
+	// Flags may follow the files, as in merge a.json b.json -o merged.json
+	var files []string
+	for parseFlags(fs, args); fs.NArg() > 0; fs.Parse(args) {
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var graphs []*depgraph.Graph
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		var graph depgraph.Graph
+		if err := json.Unmarshal(data, &graph); err != nil {
+			fmt.Printf("❌ %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		graphs = append(graphs, &graph)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeJSON(w, mergeGraphs(graphs)); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// isSharedNode reports whether a node stands for the same thing in every
+// project: a module, an external package, a tool or a base image. Other
+// nodes are the project's own.
+func isSharedNode(node *depgraph.Node) bool {
+	return isExternal(node) || node.Type == "tool" || strings.HasPrefix(node.ID, "image:")
+}
+
+// mergeGraphs unions graphs of different projects. Nodes a project owns are
+// renamed after its main module, so pkg:internal/db of example.com/a becomes
+// pkg:example.com/a/internal/db, while shared nodes appear once, annotated
+// with the projects using them as "used-by" and "projects". Shared modules
+// required at different versions keep the highest, list every project's
+// under "versions", and get "skew" edges from the projects requiring an
+// older one. Layouts are dropped, as they do not combine.
+func mergeGraphs(graphs []*depgraph.Graph) *depgraph.Graph {
+	merged := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}}
+	index := make(map[string]int)                  // node ID -> position in merged
+	usedBy := make(map[string][]string)            // shared node -> projects
+	versions := make(map[string]map[string]string) // shared node -> project -> version
+	edges := make(map[[3]string]int)               // source, target, kind -> position in merged
+	annotate := func(id, key, value string) {
+		node := &merged.Nodes[index[id]]
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[key] = value
+	}
+
+	for _, graph := range graphs {
+		project := mainModulePath(graph)
+		rename := make(map[string]string)
+		for _, node := range graph.Nodes {
+			if isSharedNode(&node) || node.Type == "main" {
+				rename[node.ID] = node.ID
+			} else {
+				rename[node.ID] = projectNodeID(project, node.ID)
+			}
+		}
+
+		for _, node := range graph.Nodes {
+			if node.ID == "pkg:root" {
+				node.Label = path.Base(project)
+			}
+			node.ID = rename[node.ID]
+			node.X, node.Y, node.VX, node.VY, node.Pinned = 0, 0, 0, 0, false
+			if isSharedNode(&node) {
+				if !slices.Contains(usedBy[node.ID], project) {
+					usedBy[node.ID] = append(usedBy[node.ID], project)
+				}
+				if node.Version != "" {
+					if versions[node.ID] == nil {
+						versions[node.ID] = make(map[string]string)
+					}
+					versions[node.ID][project] = node.Version
+				}
+			}
+			i, seen := index[node.ID]
+			if !seen {
+				index[node.ID] = len(merged.Nodes)
+				merged.Nodes = append(merged.Nodes, node)
+				continue
+			}
+			existing := &merged.Nodes[i]
+			if semver.Compare(node.Version, existing.Version) > 0 {
+				existing.Version = node.Version
+			}
+			for k, v := range node.Annotations {
+				if _, ok := existing.Annotations[k]; !ok {
+					annotate(existing.ID, k, v)
+				}
+			}
+		}
+
+		for _, edge := range graph.Edges {
+			edge.Source, edge.Target = rename[edge.Source], rename[edge.Target]
+			key := [3]string{edge.Source, edge.Target, edge.Kind}
+			if i, seen := edges[key]; seen {
+				for _, symbol := range edge.Symbols {
+					if !slices.Contains(merged.Edges[i].Symbols, symbol) {
+						merged.Edges[i].Symbols = append(merged.Edges[i].Symbols, symbol)
+					}
+				}
+				slices.Sort(merged.Edges[i].Symbols)
+				continue
+			}
+			edges[key] = len(merged.Edges)
+			merged.Edges = append(merged.Edges, edge)
+		}
+	}
+
+	for id, projects := range usedBy {
+		slices.Sort(projects)
+		annotate(id, "used-by", strings.Join(projects, ", "))
+		annotate(id, "projects", strconv.Itoa(len(projects)))
+	}
+	for id, byProject := range versions {
+		distinct := make(map[string]bool)
+		for _, v := range byProject {
+			distinct[v] = true
+		}
+		if len(distinct) < 2 {
+			continue
+		}
+		var list []string
+		for _, project := range sortedSet(byProject) {
+			list = append(list, byProject[project]+" ("+project+")")
+			// Projects behind the version a workspace would select are skewed
+			if byProject[project] != merged.Nodes[index[id]].Version {
+				merged.Edges = append(merged.Edges, depgraph.Edge{Source: project, Target: id, Kind: "skew"})
+			}
+		}
+		annotate(id, "versions", strings.Join(list, ", "))
+		annotate(id, "skew", strconv.Itoa(len(distinct))+" versions")
+	}
+	merged.Sort()
+	return merged
+}
+
+// projectNodeID renames a node a project owns after the project's main
+// module, keeping the node kind prefix.
+func projectNodeID(project, id string) string {
+	if dir, ok := depgraph.PackageDir(id); ok {
+		return "pkg:" + path.Join(project, dir)
+	}
+	kind, rest, ok := strings.Cut(id, ":")
+	if !ok {
+		return project + "/" + id
+	}
+	return kind + ":" + project + "/" + rest
+}
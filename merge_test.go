@@ -0,0 +1,102 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+func TestMergeGraphs(t *testing.T) {
+	api := &depgraph.Graph{
+		Nodes: []depgraph.Node{
+			{ID: "example.com/api", Type: "main"},
+			{ID: "pkg:root", Type: "package", Label: "root"},
+			{ID: "pkg:handlers", Type: "package"},
+			{ID: "github.com/pkg/errors", Type: "external", Version: "v0.9.1"},
+			{ID: "golang.org/x/sync", Type: "external", Version: "v0.8.0"},
+		},
+		Edges: []depgraph.Edge{
+			{Source: "pkg:root", Target: "pkg:handlers"},
+			{Source: "pkg:handlers", Target: "github.com/pkg/errors"},
+			{Source: "example.com/api", Target: "golang.org/x/sync"},
+		},
+	}
+	worker := &depgraph.Graph{
+		Nodes: []depgraph.Node{
+			{ID: "example.com/worker", Type: "main"},
+			{ID: "pkg:root", Type: "package", Label: "root", X: 10, Pinned: true},
+			{ID: "github.com/pkg/errors", Type: "external", Version: "v0.8.0"},
+			{ID: "golang.org/x/sync", Type: "external", Version: "v0.8.0"},
+		},
+		Edges: []depgraph.Edge{
+			{Source: "pkg:root", Target: "github.com/pkg/errors"},
+			{Source: "example.com/worker", Target: "golang.org/x/sync"},
+		},
+	}
+	merged := mergeGraphs([]*depgraph.Graph{api, worker})
+
+	var ids []string
+	for _, node := range merged.Nodes {
+		ids = append(ids, node.ID)
+	}
+	want := []string{
+		"example.com/api", "example.com/worker", "github.com/pkg/errors", "golang.org/x/sync",
+		"pkg:example.com/api", "pkg:example.com/api/handlers", "pkg:example.com/worker",
+	}
+	for _, id := range want {
+		if merged.Node(id) == nil {
+			t.Errorf("no node %s in %v", id, ids)
+		}
+	}
+	if len(merged.Nodes) != len(want) {
+		t.Errorf("nodes = %v, want %v", ids, want)
+	}
+
+	root := merged.Node("pkg:example.com/worker")
+	if root == nil || root.Label != "worker" || root.Pinned || root.X != 0 {
+		t.Errorf("worker root = %+v, want label worker and no layout", root)
+	}
+	errors := merged.Node("github.com/pkg/errors")
+	if errors.Version != "v0.9.1" {
+		t.Errorf("errors version = %s, want the highest, v0.9.1", errors.Version)
+	}
+	wantAnnotations := map[string]string{
+		"used-by":  "example.com/api, example.com/worker",
+		"projects": "2",
+		"versions": "v0.9.1 (example.com/api), v0.8.0 (example.com/worker)",
+		"skew":     "2 versions",
+	}
+	if !reflect.DeepEqual(errors.Annotations, wantAnnotations) {
+		t.Errorf("errors annotations = %v, want %v", errors.Annotations, wantAnnotations)
+	}
+	if sync := merged.Node("golang.org/x/sync"); sync.Annotations["skew"] != "" || sync.Annotations["projects"] != "2" {
+		t.Errorf("sync annotations = %v", sync.Annotations)
+	}
+
+	edges := make(map[[3]string]bool)
+	for _, e := range merged.Edges {
+		edges[[3]string{e.Source, e.Target, e.Kind}] = true
+	}
+	for _, e := range [][3]string{
+		{"pkg:example.com/api", "pkg:example.com/api/handlers", ""},
+		{"pkg:example.com/api/handlers", "github.com/pkg/errors", ""},
+		{"pkg:example.com/worker", "github.com/pkg/errors", ""},
+		{"example.com/worker", "github.com/pkg/errors", "skew"},
+	} {
+		if !edges[e] {
+			t.Errorf("no edge %v", e)
+		}
+	}
+	if edges[[3]string{"example.com/api", "github.com/pkg/errors", "skew"}] {
+		t.Error("skew edge from the module requiring the highest version")
+	}
+	if len(merged.Edges) != 6 {
+		t.Errorf("edges = %+v", merged.Edges)
+	}
+
+	skewed := skewedModules(merged)
+	if len(skewed) != 1 || skewed[0].Align != "v0.9.1" || skewed[0].Versions["example.com/worker"] != "v0.8.0" {
+		t.Errorf("skewedModules = %+v", skewed)
+	}
+}
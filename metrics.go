@@ -0,0 +1,109 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// nodeMetrics holds the degree of a node in the import graph.
+type nodeMetrics struct {
+	ID     string
+	Label  string
+	Type   string
+	FanIn  int // nodes importing this node
+	FanOut int // nodes this node imports
+}
+
+type graphMetrics struct {
+	Packages   int
+	Modules    int
+	Imports    int // external sub-package import nodes
+	DirectDeps int
+	Edges      int
+	Nodes      []nodeMetrics // sorted by fan-in, highest first
+}
+
+// computeMetrics summarizes the import edges of a graph; edges of other
+// kinds (instantiations, embeds) are not counted.
+func computeMetrics(graph *depgraph.Graph) graphMetrics {
+	var m graphMetrics
+	fanIn := make(map[string]int)
+	fanOut := make(map[string]int)
+	var mainModule string
+
+	for _, node := range graph.Nodes {
+		switch {
+		case node.Type == "main":
+			mainModule = node.ID
+		case node.Type == "package":
+			m.Packages++
+		case isExternal(&node) && strings.HasPrefix(node.ID, "import:"):
+			m.Imports++
+		case isExternal(&node):
+			m.Modules++
+		}
+	}
+	for _, edge := range graph.Edges {
+		if edge.Kind != "" {
+			continue
+		}
+		m.Edges++
+		fanOut[edge.Source]++
+		fanIn[edge.Target]++
+		if edge.Source == mainModule {
+			m.DirectDeps++
+		}
+	}
+
+	for _, node := range graph.Nodes {
+		m.Nodes = append(m.Nodes, nodeMetrics{
+			ID:     node.ID,
+			Label:  node.Label,
+			Type:   node.Type,
+			FanIn:  fanIn[node.ID],
+			FanOut: fanOut[node.ID],
+		})
+	}
+	sort.SliceStable(m.Nodes, func(i, j int) bool {
+		if m.Nodes[i].FanIn != m.Nodes[j].FanIn {
+			return m.Nodes[i].FanIn > m.Nodes[j].FanIn
+		}
+		return m.Nodes[i].ID < m.Nodes[j].ID
+	})
+	return m
+}
+
+// moduleImporters returns, per external module, the sorted internal packages
+// importing it or one of its packages, along with the module owning each
+// external import node.
+func moduleImporters(graph *depgraph.Graph) (importers map[string][]string, owner map[string]string) {
+	owner = make(map[string]string)
+	for _, edge := range graph.Edges {
+		if strings.HasPrefix(edge.Source, "import:") && edge.Kind == "" {
+			owner[edge.Source] = edge.Target
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	importers = make(map[string][]string)
+	for _, edge := range graph.Edges {
+		if !strings.HasPrefix(edge.Source, "pkg:") || edge.Kind != "" {
+			continue
+		}
+		module := edge.Target
+		if m, ok := owner[edge.Target]; ok {
+			module = m
+		}
+		pkg := strings.TrimPrefix(edge.Source, "pkg:")
+		if !seen[[2]string{module, pkg}] {
+			seen[[2]string{module, pkg}] = true
+			importers[module] = append(importers[module], pkg)
+		}
+	}
+	for _, pkgs := range importers {
+		sort.Strings(pkgs)
+	}
+	return importers, owner
+}
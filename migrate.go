@@ -0,0 +1,255 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// migrateCommand implements `go-raph migrate-report --from <module> --to
+// <module>`, listing every package, file and symbol using the module being
+// migrated away from, or writing the graph with them highlighted.
+func migrateCommand(args []string) {
+	fs := flag.NewFlagSet("migrate-report", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	from := fs.String("from", "", "Module or package being migrated away from, e.g. github.com/pkg/errors")
+	to := fs.String("to", "", "Module or package replacing it, e.g. errors")
+	format := fs.String("format", "text", "Output format: text, markdown, or json for the graph with affected nodes highlighted")
+	output := fs.String("o", "", "Output file (default stdout)")
+	addAnalysisFlags(fs)
+	parseFlags(fs, args)
+	if *from == "" {
+		fmt.Fprintln(fs.Output(), "usage: go-raph migrate-report --from <module> [--to <module>] [flags]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+
+	goOffline()
+	loadPlugins()
+	loadSavedGraph()
+	if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+		fmt.Printf(tr("❌ Path '%s' does not exist\n"), targetPath)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	graph, err := currentGraph(ctx)
+	if err != nil {
+		fmt.Printf(tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+	uses, err := symbolUses(ctx, targetPath, []string{*from})
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	report := buildMigrationReport(graph, *from, *to, uses)
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "text":
+		writeMigrationText(w, report)
+	case "markdown":
+		writeMigrationMarkdown(w, report)
+	case "json":
+		highlightMigration(graph, report)
+		err = writeJSON(w, graph)
+	default:
+		fmt.Printf(tr("❌ Unknown format '%s'\n"), *format)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// migrationReport is the scope of replacing one dependency with another.
+type migrationReport struct {
+	From, To  string
+	ToStatus  string        // "in the standard library", "already required" or "a new dependency"
+	Nodes     []string      // graph nodes of the module migrated from
+	Packages  []string      // package nodes using it
+	Files     []string      // files importing or using it
+	Symbols   []symbolCount // by uses, most first
+	Uses      []symbolUse   // in file and line order
+	FileCount map[string]int
+}
+
+// symbolCount counts the uses of one identifier.
+type symbolCount struct {
+	Symbol string // package.Name or package.Type.Name
+	Uses   int
+}
+
+func buildMigrationReport(graph *depgraph.Graph, from, to string, uses []symbolUse) *migrationReport {
+	report := &migrationReport{From: from, To: to, Uses: uses, FileCount: make(map[string]int)}
+	switch {
+	case to == "":
+	case !strings.Contains(strings.Split(to, "/")[0], "."):
+		report.ToStatus = "in the standard library"
+	case graph.Node(to) != nil:
+		report.ToStatus = "already required"
+	default:
+		report.ToStatus = "a new dependency"
+	}
+
+	nodes := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		if id := strings.TrimPrefix(node.ID, "import:"); isExternal(&node) && symbolModule(id, []string{from}) != "" {
+			nodes[node.ID] = true
+		}
+	}
+	packages := make(map[string]bool)
+	files := make(map[string]bool)
+	mainModule := mainModulePath(graph)
+	for _, edge := range graph.Edges {
+		if !nodes[edge.Target] || edge.Kind != "" {
+			continue
+		}
+		packageID := edge.Source
+		if node := graph.Node(edge.Source); node != nil && node.Type == "file" {
+			packageID = depgraph.PackageID(path.Dir(strings.TrimPrefix(edge.Source, "file:")))
+		}
+		if !strings.HasPrefix(packageID, "pkg:") {
+			continue
+		}
+		packages[packageID] = true
+		for _, file := range importingFiles(packageID, nodeImportPath(mainModule, edge.Target)) {
+			files[file] = true
+		}
+	}
+
+	symbols := make(map[string]int)
+	for _, use := range uses {
+		packages[use.packageID] = true
+		files[use.file] = true
+		report.FileCount[use.file]++
+		symbols[path.Base(use.pkgPath)+"."+use.symbol]++
+	}
+	for symbol, n := range symbols {
+		report.Symbols = append(report.Symbols, symbolCount{symbol, n})
+	}
+	slices.SortFunc(report.Symbols, func(a, b symbolCount) int {
+		return cmp.Or(b.Uses-a.Uses, strings.Compare(a.Symbol, b.Symbol))
+	})
+	slices.SortFunc(report.Uses, func(a, b symbolUse) int {
+		return cmp.Or(strings.Compare(a.file, b.file), a.line-b.line, strings.Compare(a.symbol, b.symbol))
+	})
+	report.Nodes = sortedSet(nodes)
+	report.Packages = sortedSet(packages)
+	report.Files = sortedSet(files)
+	return report
+}
+
+// summary estimates the scope of the migration in one line.
+func (r *migrationReport) summary() string {
+	s := fmt.Sprintf("%d uses of %d symbols in %d files across %d packages", len(r.Uses), len(r.Symbols), len(r.Files), len(r.Packages))
+	if r.ToStatus != "" {
+		s += fmt.Sprintf("; %s is %s", r.To, r.ToStatus)
+	}
+	return s
+}
+
+func (r *migrationReport) title() string {
+	if r.To == "" {
+		return "migrating away from " + r.From
+	}
+	return "migrating " + r.From + " to " + r.To
+}
+
+func writeMigrationText(w io.Writer, r *migrationReport) {
+	fmt.Fprintln(w, r.title())
+	fmt.Fprintln(w, r.summary())
+	if len(r.Files) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n# symbols")
+	for _, s := range r.Symbols {
+		fmt.Fprintf(w, "%6d  %s\n", s.Uses, s.Symbol)
+	}
+	fmt.Fprintln(w, "\n# packages")
+	for _, p := range r.Packages {
+		fmt.Fprintln(w, p)
+	}
+	fmt.Fprintln(w, "\n# files")
+	for _, file := range r.Files {
+		fmt.Fprintf(w, "%s (%d uses)\n", file, r.FileCount[file])
+	}
+	fmt.Fprintln(w, "\n# uses")
+	for _, use := range r.Uses {
+		fmt.Fprintf(w, "%s:%d  %s.%s\n", use.file, use.line, path.Base(use.pkgPath), use.symbol)
+	}
+}
+
+func writeMigrationMarkdown(w io.Writer, r *migrationReport) {
+	fmt.Fprintf(w, "## %s\n\n%s.\n", r.title(), r.summary())
+	if len(r.Files) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n| symbol | uses |\n| --- | ---: |")
+	for _, s := range r.Symbols {
+		fmt.Fprintf(w, "| `%s` | %d |\n", s.Symbol, s.Uses)
+	}
+	fmt.Fprintln(w, "\n| file | uses |\n| --- | ---: |")
+	for _, file := range r.Files {
+		fmt.Fprintf(w, "| `%s` | %d |\n", file, r.FileCount[file])
+	}
+	fmt.Fprintf(w, "\npackages: %s\n", strings.Join(r.Packages, ", "))
+}
+
+// Colors of the migration highlight.
+const (
+	migrationFromColor     = "rgba(255, 100, 100, 1)"
+	migrationAffectedColor = "rgba(255, 200, 100, 1)"
+)
+
+// highlightMigration colors the module being migrated away from and the
+// packages and files affected, dims everything else, and annotates the
+// affected nodes with their number of uses.
+func highlightMigration(graph *depgraph.Graph, r *migrationReport) {
+	uses := make(map[string]int)
+	for _, use := range r.Uses {
+		uses[use.packageID]++
+		uses["file:"+use.file]++
+	}
+	for _, id := range r.Packages {
+		uses[id] += 0
+	}
+	for _, id := range r.Files {
+		uses["file:"+id] += 0
+	}
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		node.Color = noValueColor
+		if slices.Contains(r.Nodes, node.ID) {
+			node.Color = migrationFromColor
+		} else if n, ok := uses[node.ID]; ok {
+			node.Color = migrationAffectedColor
+			graph.Annotate(node.ID, "migration-uses", strconv.Itoa(n))
+		}
+	}
+	graph.ColorBy = "migration"
+	graph.Legend = []depgraph.LegendEntry{
+		{Label: r.From, Color: migrationFromColor},
+		{Label: "affected", Color: migrationAffectedColor},
+	}
+}
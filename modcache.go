@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"go-raph/depgraph"
+)
+
+// modCache is the module cache to use instead of the go command's
+// (-modcache, or $GORAPH_MODCACHE), such as a volume of a container whose
+// GOPATH is missing or read-only. Modules of a graph missing from it are
+// downloaded into it.
+var modCache string
+
+func init() {
+	if dir := os.Getenv("GORAPH_MODCACHE"); dir != "" {
+		if err := setModCache(dir); err != nil {
+			log.Printf(tr("⚠️ Using the module cache %s failed: %v"), dir, err)
+		}
+	}
+}
+
+// setModCache makes dir the module cache of go-raph and the go commands it
+// runs, creating it if needed.
+func setModCache(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return err
+	}
+	modCache = abs
+	return os.Setenv("GOMODCACHE", abs)
+}
+
+// modCacheDir returns the root of the local module cache.
+var modCacheDir = sync.OnceValue(func() string {
+	if dir := goEnv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, "go", "pkg", "mod")
+})
+
+// moduleDir returns the extracted source directory of a module version in
+// the cache, if it has been downloaded.
+func moduleDir(path, version string) (string, bool) {
+	escPath, err := module.EscapePath(path)
+	if err != nil {
+		return "", false
+	}
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", false
+	}
+	dir := filepath.Join(modCacheDir(), filepath.FromSlash(escPath)+"@"+escVersion)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// moduleInfo is the .info file the go command keeps in the module cache
+// download directory for a module version. Origin is recorded when the
+// version was fetched from its repository, or by a proxy that passes it on.
+type moduleInfo struct {
+	Version string
+	Time    time.Time
+	Origin  *struct {
+		VCS    string
+		URL    string
+		Subdir string
+		Ref    string
+		Hash   string
+	}
+}
+
+// readModuleInfo reads the .info file of a module version.
+func readModuleInfo(path, version string) (*moduleInfo, bool) {
+	escPath, err := module.EscapePath(path)
+	if err != nil {
+		return nil, false
+	}
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(modCacheDir(), "cache", "download", filepath.FromSlash(escPath), "@v", escVersion+".info"))
+	if err != nil {
+		return nil, false
+	}
+	var info moduleInfo
+	if json.Unmarshal(data, &info) != nil {
+		return nil, false
+	}
+	return &info, true
+}
+
+// moduleTime returns when a module version was published, according to its
+// .info file.
+func moduleTime(path, version string) (time.Time, bool) {
+	info, ok := readModuleInfo(path, version)
+	if !ok || info.Time.IsZero() {
+		return time.Time{}, false
+	}
+	return info.Time, true
+}
+
+// downloadMissing downloads the external modules of the graph that are
+// missing from -modcache, so licenses can be read from it. Without
+// -modcache, or with -offline, the cache is left as it is.
+func downloadMissing(ctx context.Context, graph *depgraph.Graph) {
+	if modCache == "" || offline {
+		return
+	}
+	var missing []string
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if !isExternal(node) || strings.HasPrefix(node.ID, "import:") || node.Version == "" {
+			continue
+		}
+		if _, ok := moduleDir(node.ID, node.Version); !ok {
+			missing = append(missing, node.ID+"@"+node.Version)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	log.Printf(tr("📦 Downloading %d modules into %s"), len(missing), modCache)
+	for len(missing) > 0 {
+		batch := missing[:min(len(missing), 100)]
+		missing = missing[len(batch):]
+		cmd := exec.CommandContext(ctx, "go", append([]string{"mod", "download"}, batch...)...)
+		cmd.Dir = os.TempDir() // outside the target, which may be mounted read-only
+		cmd.Env = append(os.Environ(), "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			log.Printf(tr("⚠️ Downloading modules failed: %v: %s"), err, strings.TrimSpace(string(out)))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+var allowWrite bool // -allow-write
+
+// goModMu serializes go.mod edits from different clients.
+var goModMu sync.Mutex
+
+// applyGoModEdit edits go.mod for a client, answers it with the outcome and
+// sends everyone the graph of the edited module.
+func applyGoModEdit(c *client, action, module string) {
+	message, err := editGoMod(context.Background(), action, module)
+	if err != nil {
+		c.send(map[string]interface{}{"edit": map[string]interface{}{"error": err.Error()}})
+		return
+	}
+	log.Print(message)
+	c.send(map[string]interface{}{"edit": map[string]interface{}{"message": message}})
+	// Watch mode picks up the go.mod change by itself
+	if !watchMode {
+		graph, err := analyzeGraph(context.Background())
+		if err != nil {
+			log.Printf(tr("⚠️ Re-analysis failed: %v"), err)
+			return
+		}
+		broadcastGraph(graph)
+	}
+}
+
+// editGoMod applies a go.mod editing action from the browser and returns a
+// message describing what changed: "remove-requirement" drops a requirement
+// `go mod tidy` would drop too, "upgrade-module" requires the latest
+// version of a module.
+func editGoMod(ctx context.Context, action, module string) (string, error) {
+	if !allowWrite {
+		return "", fmt.Errorf("go.mod is read-only, start go-raph with -allow-write to edit it")
+	}
+	if graphFrom != "" {
+		return "", fmt.Errorf("a saved graph has no go.mod to edit")
+	}
+	goModMu.Lock()
+	defer goModMu.Unlock()
+
+	name := filepath.Join(targetPath, "go.mod")
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	modFile, err := modfile.Parse(name, data, nil)
+	if err != nil {
+		return "", err
+	}
+	var current string
+	for _, req := range modFile.Require {
+		if req.Mod.Path == module {
+			current = req.Mod.Version
+		}
+	}
+	if current == "" {
+		return "", fmt.Errorf("%s is not required by go.mod", module)
+	}
+
+	var message string
+	switch action {
+	case "remove-requirement":
+		// Only requirements nothing imports, or the build would break
+		p, err := predictTidy(ctx, targetPath, modFile)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(p.remove, module) {
+			return "", fmt.Errorf("%s is still needed", module)
+		}
+		if err := modFile.DropRequire(module); err != nil {
+			return "", err
+		}
+		message = fmt.Sprintf("🗑️ Removed %s %s from go.mod", module, current)
+	case "upgrade-module":
+		latest, ok := latestVersion(ctx, module)
+		if !ok {
+			return "", fmt.Errorf("no version of %s found", module)
+		}
+		if semver.Compare(latest, current) <= 0 {
+			return fmt.Sprintf("✅ %s %s is the latest version", module, current), nil
+		}
+		if err := modFile.AddRequire(module, latest); err != nil {
+			return "", err
+		}
+		message = fmt.Sprintf("⬆️ Upgraded %s from %s to %s", module, current, latest)
+	default:
+		return "", fmt.Errorf("unknown action %q", action)
+	}
+
+	modFile.Cleanup()
+	out, err := modFile.Format()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(name, out, 0o644); err != nil {
+		return "", err
+	}
+	if action == "upgrade-module" {
+		// Record the checksums of the new version in go.sum. Dependencies
+		// the new version needs at higher versions are left to go mod tidy.
+		cmd := exec.CommandContext(ctx, "go", "mod", "download", module)
+		cmd.Dir = targetPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			message += fmt.Sprintf(", but go.sum was not updated: %v: %s", err, out)
+		}
+	}
+	return message, nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+// versionedModuleGraph returns the requirement graph of the target from
+// `go mod graph`, keyed by module@version, the main module having no
+// version. The first return value is the main module.
+func versionedModuleGraph(ctx context.Context) (string, map[string][]string, error) {
+	cmd := exec.CommandContext(ctx, "go", "mod", "graph")
+	cmd.Dir = targetPath
+	out, err := cmd.Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("go mod graph: %w", err)
+	}
+	var mainModule string
+	reqs := make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		from, to, ok := strings.Cut(line, " ")
+		if !ok || strings.HasPrefix(to, "go@") || strings.HasPrefix(to, "toolchain@") {
+			continue
+		}
+		if mainModule == "" {
+			mainModule = from
+		}
+		reqs[from] = append(reqs[from], to)
+	}
+	return mainModule, reqs, nil
+}
+
+// mvsRequest is a requirement on the explained module.
+type mvsRequest struct {
+	By      string   `json:"by"` // module@version, or the main module
+	Version string   `json:"version"`
+	Chain   []string `json:"chain"` // shortest requirement chain from the main module to By
+}
+
+// mvsExplanation tells why minimal version selection picked a version: the
+// highest of all versions required anywhere in the module graph wins.
+type mvsExplanation struct {
+	Module   string       `json:"module"`
+	Selected string       `json:"selected"`
+	Requests []mvsRequest `json:"requests"` // highest version first
+}
+
+// explainSelection reconstructs minimal version selection for one module
+// from the requirement graph.
+func explainSelection(mainModule string, reqs map[string][]string, module string) (*mvsExplanation, bool) {
+	// Shortest chains from the main module, in a fixed order
+	parent := map[string]string{mainModule: ""}
+	queue := []string{mainModule}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for _, to := range reqs[from] {
+			if _, seen := parent[to]; !seen {
+				parent[to] = from
+				queue = append(queue, to)
+			}
+		}
+	}
+	chain := func(m string) []string {
+		var c []string
+		for ; m != ""; m = parent[m] {
+			c = append([]string{m}, c...)
+		}
+		return c
+	}
+
+	e := &mvsExplanation{Module: module}
+	for _, from := range sortedSet(reqs) {
+		if _, reachable := parent[from]; !reachable {
+			continue
+		}
+		for _, to := range reqs[from] {
+			path, version, _ := strings.Cut(to, "@")
+			if path != module {
+				continue
+			}
+			e.Requests = append(e.Requests, mvsRequest{By: from, Version: version, Chain: chain(from)})
+			if semver.Compare(version, e.Selected) > 0 {
+				e.Selected = version
+			}
+		}
+	}
+	sort.SliceStable(e.Requests, func(i, j int) bool {
+		return semver.Compare(e.Requests[i].Version, e.Requests[j].Version) > 0
+	})
+	return e, len(e.Requests) > 0
+}
+
+// requirementModule finds the module of the graph a user means by a module
+// path, import path or node ID.
+func requirementModule(reqs map[string][]string, name string) string {
+	name = strings.TrimPrefix(name, "import:")
+	best := ""
+	for _, tos := range reqs {
+		for _, to := range tos {
+			path, _, _ := strings.Cut(to, "@")
+			if (name == path || strings.HasPrefix(name, path+"/")) && len(path) > len(best) {
+				best = path
+			}
+		}
+	}
+	return best
+}
+
+// graph returns the requirement chains of an explanation as a graph, with
+// the requirers of the selected version annotated as forcing it.
+func (e *mvsExplanation) graph() *depgraph.Graph {
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}}
+	seen := make(map[string]bool)
+	target := e.Module + "@" + e.Selected
+	addNode := func(id, nodeType string) {
+		if !seen[id] {
+			seen[id] = true
+			graph.Nodes = append(graph.Nodes, depgraph.Node{ID: id, Label: id, FullPath: id, Type: nodeType})
+		}
+	}
+	edges := make(map[[2]string]bool)
+	addEdge := func(from, to, kind string) {
+		if !edges[[2]string{from, to}] {
+			edges[[2]string{from, to}] = true
+			graph.Edges = append(graph.Edges, depgraph.Edge{Source: from, Target: to, Kind: kind})
+		}
+	}
+	addNode(target, "external")
+	graph.Annotate(target, "selected", e.Selected)
+	for _, r := range e.Requests {
+		for i, m := range r.Chain {
+			nodeType := "external"
+			if i == 0 {
+				nodeType = "main"
+			}
+			addNode(m, nodeType)
+			if i > 0 {
+				addEdge(r.Chain[i-1], m, "requires")
+			}
+		}
+		addEdge(r.By, target, "requires")
+		graph.Annotate(r.By, "requires", r.Version)
+		if r.Version == e.Selected {
+			graph.Annotate(r.By, "mvs", "forces "+e.Selected)
+		}
+	}
+	graph.Sort()
+	return graph
+}
+
+// mvsCommand implements `go-raph mvs <module>`, explaining why the go command
+// selected the version of a module it did.
+func mvsCommand(args []string) {
+	fs := flag.NewFlagSet("mvs", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	format := fs.String("format", "text", "Output format: text, or json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: go-raph mvs [flags] <module or import path>")
+		fs.PrintDefaults()
+	}
+	addOfflineFlag(fs)
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	goOffline()
+
+	mainModule, reqs, err := versionedModuleGraph(context.Background())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	e, ok := explainSelection(mainModule, reqs, requirementModule(reqs, fs.Arg(0)))
+	if !ok {
+		fmt.Printf(tr("❌ '%s' is not in the module graph of %s\n"), fs.Arg(0), targetPath)
+		os.Exit(1)
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(e)
+	case "text":
+		writeExplanation(os.Stdout, e)
+	default:
+		fmt.Printf(tr("❌ Unknown format '%s'\n"), *format)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func writeExplanation(w io.Writer, e *mvsExplanation) {
+	fmt.Fprintf(w, "%s %s is selected: the highest version required anywhere in the module graph\n\n", e.Module, e.Selected)
+	for _, r := range e.Requests {
+		mark := " "
+		if r.Version == e.Selected {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s required by %s\n", mark, r.Version, r.By)
+		if len(r.Chain) > 1 {
+			fmt.Fprintf(w, "    %s\n", strings.Join(r.Chain, " → "))
+		}
+	}
+}
+
+// mvsHandler serves the requirement chains that decide the version of
+// ?target=, a module, import path or node ID, as a graph.
+func mvsHandler(w http.ResponseWriter, r *http.Request) {
+	mainModule, reqs, err := versionedModuleGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	e, ok := explainSelection(mainModule, reqs, requirementModule(reqs, r.URL.Query().Get("target")))
+	if !ok {
+		http.Error(w, "target not found", http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("format") == "explanation" {
+		respondJSON(w, e)
+		return
+	}
+	graph := e.graph()
+	applyColors(graph, "type")
+	respondJSON(w, graph)
+}
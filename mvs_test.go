@@ -0,0 +1,94 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRequirements is a `go mod graph` where example.com/app and its
+// dependencies require different versions of github.com/x/log.
+var testRequirements = map[string][]string{
+	"example.com/app":          {"github.com/a/http@v1.2.0", "github.com/b/db@v0.4.0", "github.com/x/log@v1.1.0"},
+	"github.com/a/http@v1.2.0": {"github.com/x/log@v1.3.0", "github.com/c/util@v2.0.0+incompatible"},
+	"github.com/b/db@v0.4.0":   {"github.com/x/log@v1.3.0-rc.1", "github.com/c/util@v1.9.0"},
+	"github.com/c/util@v1.9.0": {"github.com/x/log@v1.0.0"},
+	// Not reachable from the main module, e.g. an older version pruned by MVS
+	"github.com/a/http@v1.0.0": {"github.com/x/log@v2.0.0"},
+}
+
+func TestExplainSelection(t *testing.T) {
+	for _, tt := range []struct {
+		module   string
+		selected string
+		requests []mvsRequest
+	}{
+		{"github.com/x/log", "v1.3.0", []mvsRequest{
+			{By: "github.com/a/http@v1.2.0", Version: "v1.3.0", Chain: []string{"example.com/app", "github.com/a/http@v1.2.0"}},
+			{By: "github.com/b/db@v0.4.0", Version: "v1.3.0-rc.1", Chain: []string{"example.com/app", "github.com/b/db@v0.4.0"}},
+			{By: "example.com/app", Version: "v1.1.0", Chain: []string{"example.com/app"}},
+			{By: "github.com/c/util@v1.9.0", Version: "v1.0.0", Chain: []string{"example.com/app", "github.com/b/db@v0.4.0", "github.com/c/util@v1.9.0"}},
+		}},
+		{"github.com/c/util", "v2.0.0+incompatible", []mvsRequest{
+			{By: "github.com/a/http@v1.2.0", Version: "v2.0.0+incompatible", Chain: []string{"example.com/app", "github.com/a/http@v1.2.0"}},
+			{By: "github.com/b/db@v0.4.0", Version: "v1.9.0", Chain: []string{"example.com/app", "github.com/b/db@v0.4.0"}},
+		}},
+		{"github.com/a/http", "v1.2.0", []mvsRequest{
+			{By: "example.com/app", Version: "v1.2.0", Chain: []string{"example.com/app"}},
+		}},
+	} {
+		e, ok := explainSelection("example.com/app", testRequirements, tt.module)
+		if !ok {
+			t.Errorf("explainSelection(%s) found no requirements", tt.module)
+			continue
+		}
+		if e.Selected != tt.selected {
+			t.Errorf("explainSelection(%s) selected %s, want %s", tt.module, e.Selected, tt.selected)
+		}
+		if !reflect.DeepEqual(e.Requests, tt.requests) {
+			t.Errorf("explainSelection(%s) requests = %+v, want %+v", tt.module, e.Requests, tt.requests)
+		}
+	}
+	if _, ok := explainSelection("example.com/app", testRequirements, "github.com/nope"); ok {
+		t.Error("explainSelection found a module that is not required")
+	}
+}
+
+func TestRequirementModule(t *testing.T) {
+	for name, want := range map[string]string{
+		"github.com/x/log":              "github.com/x/log",
+		"github.com/x/log/slog":         "github.com/x/log",
+		"import:github.com/c/util/text": "github.com/c/util",
+		"github.com/x/logger":           "",
+		"example.com/app":               "",
+	} {
+		if got := requirementModule(testRequirements, name); got != want {
+			t.Errorf("requirementModule(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestExplanationGraph(t *testing.T) {
+	e, _ := explainSelection("example.com/app", testRequirements, "github.com/x/log")
+	graph := e.graph()
+	target := graph.Node("github.com/x/log@v1.3.0")
+	if target == nil || target.Annotations["selected"] != "v1.3.0" {
+		t.Fatalf("selected node = %+v", target)
+	}
+	if n := graph.Node("github.com/a/http@v1.2.0"); n == nil || n.Annotations["mvs"] != "forces v1.3.0" {
+		t.Errorf("forcing requirer = %+v", n)
+	}
+	if n := graph.Node("example.com/app"); n == nil || n.Type != "main" || n.Annotations["mvs"] != "" || n.Annotations["requires"] != "v1.1.0" {
+		t.Errorf("main module = %+v", n)
+	}
+	if graph.Node("github.com/a/http@v1.0.0") != nil {
+		t.Error("unreachable requirer in the graph")
+	}
+
+	var out strings.Builder
+	writeExplanation(&out, e)
+	if !strings.Contains(out.String(), "* v1.3.0 required by github.com/a/http@v1.2.0") ||
+		!strings.Contains(out.String(), "  v1.0.0 required by github.com/c/util@v1.9.0\n    example.com/app → github.com/b/db@v0.4.0 → github.com/c/util@v1.9.0") {
+		t.Errorf("explanation:\n%s", out.String())
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// note is a freeform comment a user attached to a node, e.g. during an
+// architecture review over the shared graph.
+type note struct {
+	ID     string `json:"id"`
+	Author string `json:"author,omitempty"`
+	Text   string `json:"text"`
+	Time   string `json:"time"` // RFC 3339, the browser's msgpack decoder has no timestamps
+}
+
+// Limits of notes so clients cannot bloat the state file.
+const (
+	maxNoteLength   = 4000 // bytes of note text
+	maxAuthorLength = 100  // bytes of the author's name
+	maxNotes        = 1000 // notes of a project
+)
+
+// notes holds the notes of every node per project, persisted like layouts
+// and pushed to every viewer as they change.
+var notes = struct {
+	sync.Mutex
+	loaded   bool
+	projects map[string]map[string][]note
+}{}
+
+// loadNotes reads the notes file once; callers hold the lock.
+func loadNotes() {
+	if notes.loaded {
+		return
+	}
+	notes.loaded = true
+	if err := readState("notes.json", &notes.projects); err != nil {
+		log.Printf(tr("⚠️ Reading %s failed: %v"), "notes.json", err)
+	}
+	if notes.projects == nil {
+		notes.projects = make(map[string]map[string][]note)
+	}
+}
+
+// projectNotes returns the notes of the project by node ID.
+func projectNotes() map[string][]note {
+	notes.Lock()
+	defer notes.Unlock()
+	loadNotes()
+	return notesCopy()
+}
+
+// notesCopy returns the project's notes for sending while they may change;
+// callers hold the lock. Lists are only ever replaced, never modified in
+// place, so they can be shared.
+func notesCopy() map[string][]note {
+	list := maps.Clone(notes.projects[projectKey()])
+	if list == nil {
+		list = make(map[string][]note)
+	}
+	return list
+}
+
+// changeNotes applies an add-note or delete-note message and returns the
+// project's notes. Notes are only attached to nodes of the graph.
+func changeNotes(msg clientMessage) (map[string][]note, error) {
+	if msg.Type == "delete-note" {
+		return deleteNote(msg.Node, msg.NoteID)
+	}
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if graph.Node(msg.Node) == nil {
+		return nil, fmt.Errorf("no node %s in the graph", msg.Node)
+	}
+	return addNote(msg.Node, msg.Author, msg.Text)
+}
+
+// addNote attaches a note to a node and returns the project's notes.
+func addNote(node, author, text string) (map[string][]note, error) {
+	text = strings.TrimSpace(text)
+	if node == "" || text == "" {
+		return nil, fmt.Errorf("a note needs a node and text")
+	}
+	if len(text) > maxNoteLength {
+		return nil, fmt.Errorf("notes are limited to %d bytes", maxNoteLength)
+	}
+	if len(author) > maxAuthorLength {
+		return nil, fmt.Errorf("author names are limited to %d bytes", maxAuthorLength)
+	}
+	notes.Lock()
+	defer notes.Unlock()
+	loadNotes()
+	key := projectKey()
+	count := 0
+	for _, list := range notes.projects[key] {
+		count += len(list)
+	}
+	if count >= maxNotes {
+		return nil, fmt.Errorf("a project keeps at most %d notes, delete some first", maxNotes)
+	}
+	if notes.projects[key] == nil {
+		notes.projects[key] = make(map[string][]note)
+	}
+	now := time.Now()
+	list := notes.projects[key][node]
+	notes.projects[key][node] = append(list[:len(list):len(list)], note{
+		ID:     strconv.FormatInt(now.UnixNano(), 36),
+		Author: author,
+		Text:   text,
+		Time:   now.UTC().Format(time.RFC3339),
+	})
+	return notesCopy(), writeState("notes.json", notes.projects)
+}
+
+// deleteNote removes a note of a node and returns the project's notes.
+func deleteNote(node, id string) (map[string][]note, error) {
+	notes.Lock()
+	defer notes.Unlock()
+	loadNotes()
+	key := projectKey()
+	list := notes.projects[key][node]
+	for i, n := range list {
+		if n.ID == id {
+			list = append(list[:i:i], list[i+1:]...)
+			if len(list) == 0 {
+				delete(notes.projects[key], node)
+			} else {
+				notes.projects[key][node] = list
+			}
+			return notesCopy(), writeState("notes.json", notes.projects)
+		}
+	}
+	return nil, fmt.Errorf("no note %s on %s", id, node)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+func TestChangeNotes(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	notes.Lock()
+	notes.loaded, notes.projects = false, nil
+	notes.Unlock()
+	setSnapshot(&depgraph.Graph{Nodes: []depgraph.Node{{ID: "pkg:api", Type: "package"}}})
+	t.Cleanup(func() { setSnapshot(nil) })
+
+	tests := []struct {
+		msg clientMessage
+		err string
+	}{
+		{clientMessage{Type: "add-note", Node: "pkg:api", Author: "ana", Text: "split this"}, ""},
+		{clientMessage{Type: "add-note", Node: "pkg:gone", Text: "hello"}, "no node"},
+		{clientMessage{Type: "add-note", Node: "pkg:api", Text: "   "}, "needs a node and text"},
+		{clientMessage{Type: "add-note", Node: "pkg:api", Text: strings.Repeat("x", maxNoteLength+1)}, "limited to"},
+		{clientMessage{Type: "add-note", Node: "pkg:api", Author: strings.Repeat("x", maxAuthorLength+1), Text: "hi"}, "limited to"},
+		{clientMessage{Type: "delete-note", Node: "pkg:api", NoteID: "nope"}, "no note"},
+	}
+	for _, test := range tests {
+		_, err := changeNotes(test.msg)
+		if test.err == "" && err != nil || test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%+v: err %v, want %q", test.msg, err, test.err)
+		}
+	}
+	if list := projectNotes(); len(list["pkg:api"]) != 1 || len(list["pkg:gone"]) != 0 {
+		t.Fatalf("notes = %v", list)
+	}
+
+	// A project full of notes takes no more
+	notes.Lock()
+	full := make([]note, maxNotes)
+	notes.projects[projectKey()]["pkg:api"] = full
+	notes.Unlock()
+	if _, err := changeNotes(clientMessage{Type: "add-note", Node: "pkg:api", Text: "one more"}); err == nil || !strings.Contains(err.Error(), "at most") {
+		t.Errorf("note beyond maxNotes: err %v", err)
+	}
+}
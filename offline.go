@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+var offline bool // -offline
+
+// addOfflineFlag adds -offline to a command's flags, the same for every
+// command that analyzes or runs the go command.
+func addOfflineFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&offline, "offline", false, "Never touch the network: module versions come from the local module cache and other lookups are skipped")
+}
+
+// goOffline makes sure nothing reaches the network: HTTP requests other
+// than file:// URLs fail, module versions come from the module cache through
+// a file:// GOPROXY, and go commands run for the analysis neither download
+// modules nor toolchains nor look up checksums in the checksum database;
+// go.sum is still checked. Every command that analyzes or runs the go
+// command calls it once flags are parsed.
+func goOffline() {
+	if !offline {
+		return
+	}
+	sumdbName() // the sumdb annotations describe the user's settings, not these
+	cache := "file://" + filepath.ToSlash(filepath.Join(modCacheDir(), "cache", "download"))
+	os.Setenv("GOPROXY", cache)
+	os.Setenv("GOSUMDB", "off")
+	os.Setenv("GOTOOLCHAIN", "local")
+	http.DefaultTransport = localTransport{http.NewFileTransport(http.Dir("/"))}
+}
+
+// localTransport serves file:// URLs and refuses everything else.
+type localTransport struct {
+	files http.RoundTripper
+}
+
+func (t localTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "file" {
+		return nil, fmt.Errorf("%s %s: network access disabled by -offline", req.Method, req.URL)
+	}
+	return t.files.RoundTrip(req)
+}
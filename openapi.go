@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"go-raph/depgraph"
+)
+
+// apiEndpoint is a path of the REST API with the operations it serves. The
+// endpoints are registered and described in /api/openapi.json from the same
+// definitions, so the spec cannot drift from the server.
+type apiEndpoint struct {
+	Path    string
+	Handler http.HandlerFunc
+	Ops     []apiOperation
+}
+
+type apiOperation struct {
+	Method   string
+	ID       string // operationId, the method name in the generated client
+	Summary  string
+	Params   []apiParam
+	Body     interface{} // a value of the request body type
+	Response interface{} // a value of the response type, nil for none
+}
+
+type apiParam struct {
+	Name        string
+	Integer     bool
+	Description string
+}
+
+// graphParams are the parameters requestedGraph applies.
+var graphParams = []apiParam{
+	{Name: "view", Description: "Saved view narrowing the graph"},
+	{Name: "filter", Description: "Filter expression, e.g. type:external"},
+	{Name: "collapse", Description: "Fold leaf import nodes into their modules (imports) or keep them (none), defaulting to -collapse-imports"},
+	{Name: "expand", Description: "Comma-separated modules whose import nodes stay unfolded"},
+	{Name: "colorBy", Description: "Dimension to color nodes by, defaulting to the view's"},
+}
+
+func apiEndpoints() []apiEndpoint {
+	nameParam := []apiParam{{Name: "name", Description: "View name"}}
+	return []apiEndpoint{
+		{"/api/graph", gzipped(graphHandler), []apiOperation{{
+			Method: "GET", ID: "Graph", Summary: "The analyzed graph",
+			Params: graphParams, Response: depgraph.Graph{},
+		}}},
+		{"/api/subgraph", gzipped(subgraphHandler), []apiOperation{{
+			Method: "GET", ID: "Subgraph", Summary: "The neighborhood of a node",
+			Params: append([]apiParam{
+				{Name: "root", Description: "Node ID"},
+				{Name: "depth", Integer: true, Description: "Edges to follow, unlimited by default"},
+				{Name: "direction", Description: "out (default), in or both"},
+			}, graphParams...),
+			Response: depgraph.Graph{},
+		}}},
+		{"/api/why", gzipped(whyHandler), []apiOperation{{
+			Method: "GET", ID: "Why", Summary: "The import chains leading to a module, import path, package or node",
+			Params: append([]apiParam{
+				{Name: "target", Description: "Module, import path, package or node ID"},
+				{Name: "max", Integer: true, Description: "Stop after this many chains, 100 by default and at most 1000"},
+			}, graphParams...),
+			Response: depgraph.Graph{},
+		}}},
+		{"/api/mvs", gzipped(mvsHandler), []apiOperation{{
+			Method: "GET", ID: "MVS", Summary: "The requirement chains deciding the version of a module",
+			Params:   []apiParam{{Name: "target", Description: "Module, import path or node ID"}},
+			Response: depgraph.Graph{},
+		}}},
+		{"/api/exclusive-deps", gzipped(exclusiveDepsHandler), []apiOperation{{
+			Method: "GET", ID: "ExclusiveDeps", Summary: "The modules only reachable through each requirement of go.mod",
+			Response: []exclusiveDeps{},
+		}}},
+		{"/api/views", gzipped(viewsHandler), []apiOperation{{
+			Method: "GET", ID: "Views", Summary: "The project's saved views", Response: []view{},
+		}}},
+		{"/api/views/{name}", gzipped(viewsHandler), []apiOperation{
+			{Method: "GET", ID: "View", Summary: "A saved view", Params: nameParam, Response: view{}},
+			{Method: "PUT", ID: "PutView", Summary: "Create or replace a view", Params: nameParam, Body: view{}, Response: view{}},
+			{Method: "DELETE", ID: "DeleteView", Summary: "Delete a view", Params: nameParam},
+		}},
+		{"/api/timeseries", gzipped(timeseriesHandler), []apiOperation{{
+			Method: "GET", ID: "Timeseries", Summary: "The project's metrics history",
+			Params:   []apiParam{{Name: "since", Description: "Only points after this RFC 3339 time"}},
+			Response: []metricsPoint{},
+		}}},
+		{"/api/theme", themeHandler, []apiOperation{{
+			Method: "GET", ID: "Theme", Summary: "The theme the web UI draws with", Response: theme{},
+		}}},
+		{"/api/schema", gzipped(schemaHandler), []apiOperation{{
+			Method: "GET", ID: "Schema", Summary: "The node types and edge kinds of the graph",
+			Params: graphParams, Response: graphSchema{},
+		}}},
+		{"/api/diagnostics", gzipped(diagnosticsHandler), []apiOperation{{
+			Method: "GET", ID: "Diagnostics", Summary: "The nodes with warnings, such as packages with files that failed to parse or unresolved imports",
+			Response: []depgraph.Diagnostic{},
+		}}},
+		{"/api/annotations", gzipped(annotationsHandler), []apiOperation{
+			{Method: "GET", ID: "Annotations", Summary: "The project's annotations by node ID", Response: map[string]map[string]string{}},
+			{Method: "POST", ID: "Annotate", Summary: "Set annotations of a node; an empty value removes the key",
+				Body: annotationUpdate{}, Response: map[string]string{}},
+		}},
+		{"/api/recordings", gzipped(recordingsHandler), []apiOperation{{
+			Method: "GET", ID: "Recordings", Summary: "The saved recordings", Response: []recordingSummary{},
+		}}},
+		{"/api/recordings/{id}", gzipped(recordingsHandler), []apiOperation{{
+			Method: "GET", ID: "Recording", Summary: "A recording with its events",
+			Params: []apiParam{{Name: "id", Description: "Recording ID"}}, Response: recording{},
+		}}},
+		{"/api/source", gzipped(sourceHandler), []apiOperation{{
+			Method: "GET", ID: "Source", Summary: "A directory listing or file of an external module, from the module cache",
+			Params: []apiParam{
+				{Name: "module", Description: "Module path of an external node"},
+				{Name: "path", Description: "Directory or file relative to the module root, the root by default"},
+			},
+			Response: moduleSource{},
+		}}},
+		{"/api/open", openHandler, []apiOperation{{
+			Method: "POST", ID: "Open", Summary: "Open the main file of a package in the editor of -editor",
+			Params:   []apiParam{{Name: "node", Description: "Package node ID"}},
+			Response: editorLink{},
+		}}},
+		{"/api/hot-paths", gzipped(hotPathsHandler), []apiOperation{{
+			Method: "GET", ID: "HotPaths", Summary: "The import edges most paths from cmd/ packages to external modules run along",
+			Params:   []apiParam{{Name: "limit", Integer: true, Description: "Edges to return, hottest first, 50 by default (0 or less for all)"}},
+			Response: []depgraph.HotEdge{},
+		}}},
+		{"/api/share", shareHandler, []apiOperation{{
+			Method: "POST", ID: "Share", Summary: "Mint a read-only share link",
+			Params:   []apiParam{{Name: "expires", Description: "Validity as a Go duration, 7 days by default"}},
+			Response: shareLink{},
+		}}},
+	}
+}
+
+// openAPISpec builds the OpenAPI 3 description of the endpoints, with the
+// JSON schemas of their bodies derived from the Go types.
+func openAPISpec() map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+	for _, e := range apiEndpoints() {
+		ops := make(map[string]interface{})
+		for _, op := range e.Ops {
+			params := []interface{}{}
+			for _, p := range op.Params {
+				param := map[string]interface{}{"name": p.Name, "in": "query", "schema": map[string]interface{}{"type": "string"}}
+				if p.Integer {
+					param["schema"] = map[string]interface{}{"type": "integer"}
+				}
+				if strings.Contains(e.Path, "{"+p.Name+"}") {
+					param["in"], param["required"] = "path", true
+				}
+				if p.Description != "" {
+					param["description"] = p.Description
+				}
+				params = append(params, param)
+			}
+			responses := map[string]interface{}{"default": map[string]interface{}{"description": "Error message"}}
+			if op.Response == nil {
+				responses["204"] = map[string]interface{}{"description": "No content"}
+			} else {
+				responses["200"] = map[string]interface{}{
+					"description": op.Summary,
+					"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": jsonSchema(reflect.TypeOf(op.Response), schemas)}},
+				}
+			}
+			operation := map[string]interface{}{"operationId": op.ID, "summary": op.Summary, "parameters": params, "responses": responses}
+			if op.Body != nil {
+				operation["requestBody"] = map[string]interface{}{
+					"required": true,
+					"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": jsonSchema(reflect.TypeOf(op.Body), schemas)}},
+				}
+			}
+			ops[strings.ToLower(op.Method)] = operation
+		}
+		paths[e.Path] = ops
+	}
+	return map[string]interface{}{
+		"openapi":    "3.0.3",
+		"info":       map[string]interface{}{"title": "go-raph", "version": "1", "description": "Dependency graph of a Go project"},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// jsonSchema returns the schema of values of t as encoding/json writes
+// them. Named structs become components referenced by their exported name.
+func jsonSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchema(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32, reflect.Uint, reflect.Uint64, reflect.Uint32:
+		schema := map[string]interface{}{"type": "integer"}
+		if t.Kind() != reflect.Int32 && t.Kind() != reflect.Uint32 {
+			schema["format"] = "int64"
+		}
+		return schema
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = nil // recursive types refer to themselves
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = jsonSchema(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// openAPIHandler serves /api/openapi.json.
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, openAPISpec())
+}
+
+// openAPICommand implements `go-raph openapi`, writing the spec the client
+// package is generated from.
+func openAPICommand() {
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	out.Encode(openAPISpec())
+}
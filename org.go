@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+// orgCommand implements `go-raph org <repo>...`, analyzing many repositories,
+// local paths or git URLs, and serving their merged graph along with a
+// dashboard of the modules they share.
+func orgCommand(args []string) {
+	fs := flag.NewFlagSet("org", flag.ExitOnError)
+	port := fs.String("port", "8080", "Server port")
+	reposFile := fs.String("repos", "", "File listing repository paths or git URLs, one per line")
+	jobs := fs.Int("jobs", 4, "Repositories analyzed at once")
+	output := fs.String("o", "", "Write the dashboard data as JSON to this file instead of serving it")
+	fs.StringVar(&listenAddr, "listen", "", "Listen on this address instead of -port: host:port, or unix:/path/to/socket behind a reverse proxy")
+	fs.Func("base-path", "Serve every route under this path, e.g. /raph/ behind a reverse proxy (default /)", setBasePath)
+	fs.BoolVar(&strictPort, "strict-port", false, "Exit when the port is in use instead of serving on a free one")
+	fs.BoolVar(&openBrowser, "open", openBrowser, "Open the dashboard in the default browser once the server listens, by default when run in a terminal")
+	addLimitFlags(fs)
+	addAnalysisFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: go-raph org [flags] <path or git URL>...")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+
+	repos := fs.Args()
+	if *reposFile != "" {
+		listed, err := readRepoList(*reposFile)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		repos = append(repos, listed...)
+	}
+	if len(repos) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	goOffline()
+	loadPlugins()
+
+	// Every flag set shapes the analysis, so cached graphs are only reused
+	// with the same ones
+	var flags []string
+	fs.Visit(func(f *flag.Flag) { flags = append(flags, f.Name+"="+f.Value.String()) })
+
+	ctx := context.Background()
+	fmt.Printf(tr("🏢 Analyzing %d repositories\n"), len(repos))
+	results := analyzeRepos(ctx, repos, strings.Join(flags, " "), max(*jobs, 1))
+	var graphs []*depgraph.Graph
+	for _, r := range results {
+		if r.graph != nil {
+			graphs = append(graphs, r.graph)
+		}
+	}
+	merged := mergeGraphs(graphs)
+	saveGraph(merged)
+	report := buildOrgReport(results)
+
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err == nil {
+			defer f.Close()
+			enc := json.NewEncoder(f)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(report)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	setSnapshot(merged)
+	registerHandlers()
+	http.HandleFunc("/org", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page := struct {
+			*orgReport
+			Style template.CSS
+		}{report, currentTheme().pageCSS()}
+		if err := orgTemplate.Execute(w, page); err != nil {
+			log.Printf(tr("⚠️ Rendering dashboard failed: %v"), err)
+		}
+	})
+	http.HandleFunc("/api/org", gzipped(func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, report)
+	}))
+	ln, url := listen(*port)
+	fmt.Printf(tr("🌐 Visualizer: %s\n"), url)
+	fmt.Printf(tr("📊 Dashboard: %s\n"), url+"org")
+	serve(ln, url+"org")
+}
+
+// readRepoList reads repository paths or URLs, one per line, skipping blank
+// lines and # comments.
+func readRepoList(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var repos []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			repos = append(repos, line)
+		}
+	}
+	return repos, scanner.Err()
+}
+
+// repoResult is the analysis of one repository of an org.
+type repoResult struct {
+	repo   string
+	graph  *depgraph.Graph
+	cached bool
+	err    error
+}
+
+// enrichMu serializes enrichment, as analyzers read the global targetPath.
+var enrichMu sync.Mutex
+
+// analyzeRepos analyzes repositories, jobs at a time. Remote repositories
+// are cloned into the cache directory, and graphs of clean checkouts are
+// cached by commit and flags.
+func analyzeRepos(ctx context.Context, repos []string, flags string, jobs int) []repoResult {
+	results := make([]repoResult, len(repos))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = analyzeRepo(ctx, repo, flags)
+			if err := results[i].err; err != nil {
+				fmt.Printf("⚠️ %s: %v\n", repo, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func analyzeRepo(ctx context.Context, repo, flags string) repoResult {
+	dir := repo
+	if isRepoURL(repo) {
+		var err error
+		if dir, err = syncClone(ctx, repo); err != nil {
+			return repoResult{repo: repo, err: err}
+		}
+	}
+
+	cacheFile := ""
+	if head, err := gitOutput(dir, "rev-parse", "HEAD"); err == nil {
+		if status, err := gitOutput(dir, "status", "--porcelain"); err == nil && strings.TrimSpace(status) == "" {
+			sum := sha256.Sum256([]byte(repo + "\n" + strings.TrimSpace(head) + "\n" + flags))
+			cacheFile = filepath.Join(cacheDir(), "graphs", hex.EncodeToString(sum[:])+".json")
+		}
+	}
+	if data, err := os.ReadFile(cacheFile); cacheFile != "" && err == nil {
+		var graph depgraph.Graph
+		if json.Unmarshal(data, &graph) == nil {
+			return repoResult{repo: repo, graph: &graph, cached: true}
+		}
+	}
+
+	graph, err := depgraph.Analyze(os.DirFS(dir), analyzeOptions())
+	if err != nil {
+		return repoResult{repo: repo, err: err}
+	}
+	enrichMu.Lock()
+	previous, save := targetPath, graphSave
+	targetPath, graphSave = dir, ""
+	graph = enrichGraph(ctx, graph)
+	targetPath, graphSave = previous, save
+	enrichMu.Unlock()
+
+	if cacheFile != "" {
+		if data, err := json.Marshal(graph); err == nil {
+			os.MkdirAll(filepath.Dir(cacheFile), 0o755)
+			os.WriteFile(cacheFile, data, 0o644)
+		}
+	}
+	return repoResult{repo: repo, graph: graph}
+}
+
+func isRepoURL(repo string) bool {
+	return strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@")
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// syncClone makes a shallow clone of a repository in the cache directory,
+// or updates it to the remote HEAD. Offline, an existing clone is used as is.
+// URLs starting with a dash are rejected, as git would take them for an
+// option like --upload-pack running a command.
+func syncClone(ctx context.Context, url string) (string, error) {
+	if strings.HasPrefix(url, "-") {
+		return "", fmt.Errorf("invalid repository URL %q", url)
+	}
+	dir := filepath.Join(cacheDir(), "repos", unsafePathChars.ReplaceAllString(url, "_"))
+	var cmds [][]string
+	switch _, err := os.Stat(filepath.Join(dir, ".git")); {
+	case err != nil && offline:
+		return "", fmt.Errorf("not cloned yet and -offline is set")
+	case err != nil:
+		cmds = [][]string{{"clone", "--depth", "1", "--", url, dir}}
+	case !offline:
+		cmds = [][]string{{"-C", dir, "fetch", "--depth", "1", "origin", "HEAD"}, {"-C", dir, "reset", "--hard", "FETCH_HEAD"}}
+	}
+	for _, args := range cmds {
+		if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+		}
+	}
+	return dir, nil
+}
+
+// orgReport is what the org dashboard shows.
+type orgReport struct {
+	Repos   []orgRepo   `json:"repos"`
+	Modules []orgModule `json:"modules"` // by repositories using them, most first
+	Shared  []orgModule `json:"shared"`  // modules of the analyzed repositories used by others
+}
+
+type orgRepo struct {
+	Repo     string `json:"repo"`
+	Module   string `json:"module,omitempty"`
+	Packages int    `json:"packages"`
+	Modules  int    `json:"modules"`
+	Cached   bool   `json:"cached,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type orgModule struct {
+	Module   string            `json:"module"`
+	Versions map[string]string `json:"versions"` // required version by repository module
+	Skew     bool              `json:"skew,omitempty"`
+}
+
+func buildOrgReport(results []repoResult) *orgReport {
+	report := &orgReport{Repos: []orgRepo{}, Modules: []orgModule{}, Shared: []orgModule{}}
+	modules := make(map[string]map[string]string) // module -> repository module -> version
+	repoModules := make(map[string]bool)
+	for _, r := range results {
+		repo := orgRepo{Repo: r.repo, Cached: r.cached}
+		if r.err != nil {
+			repo.Error = r.err.Error()
+		}
+		if r.graph != nil {
+			repo.Module = mainModulePath(r.graph)
+			repoModules[repo.Module] = true
+			for _, node := range r.graph.Nodes {
+				switch {
+				case node.Type == "package":
+					repo.Packages++
+				case isExternal(&node) && !strings.HasPrefix(node.ID, "import:"):
+					repo.Modules++
+					if modules[node.ID] == nil {
+						modules[node.ID] = make(map[string]string)
+					}
+					modules[node.ID][repo.Module] = node.Version
+				}
+			}
+		}
+		report.Repos = append(report.Repos, repo)
+	}
+
+	for module, versions := range modules {
+		m := orgModule{Module: module, Versions: versions}
+		distinct := make(map[string]bool)
+		for _, v := range versions {
+			distinct[v] = true
+		}
+		m.Skew = len(distinct) > 1
+		report.Modules = append(report.Modules, m)
+		if repoModules[module] {
+			report.Shared = append(report.Shared, m)
+		}
+	}
+	for _, list := range [][]orgModule{report.Modules, report.Shared} {
+		sort.Slice(list, func(i, j int) bool {
+			if len(list[i].Versions) != len(list[j].Versions) {
+				return len(list[i].Versions) > len(list[j].Versions)
+			}
+			return list[i].Module < list[j].Module
+		})
+	}
+	return report
+}
+
+var orgTemplate = template.Must(template.New("org").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>go-raph org</title>
+<style>
+{{.Style}}
+body { font-size: 12px; margin: 40px; }
+table { border-collapse: collapse; margin-top: 12px; }
+td, th { padding: 4px 12px; text-align: left; border-bottom: 1px solid #222; vertical-align: top; }
+.skew, .error { color: rgba(255,100,100,1); }
+</style>
+</head>
+<body>
+<p><a href="./">graph</a> · <a href="api/org">json</a></p>
+<h2>repositories</h2>
+<table>
+<tr><th>repository</th><th>module</th><th>packages</th><th>modules</th></tr>
+{{range .Repos}}<tr><td>{{.Repo}}{{if .Cached}} (cached){{end}}</td>{{if .Error}}<td colspan="3" class="error">{{.Error}}</td>{{else}}<td>{{.Module}}</td><td>{{.Packages}}</td><td>{{.Modules}}</td>{{end}}</tr>
+{{end}}</table>
+<h2>shared internal libraries</h2>
+<table>
+<tr><th>module</th><th>used by</th></tr>
+{{range .Shared}}<tr><td>{{.Module}}</td><td>{{range $repo, $v := .Versions}}{{$repo}} {{$v}}<br>{{end}}</td></tr>
+{{else}}<tr><td colspan="2">no analyzed module is used by another</td></tr>
+{{end}}</table>
+<h2>external modules</h2>
+<table>
+<tr><th>module</th><th>repositories</th><th>versions</th></tr>
+{{range .Modules}}<tr><td>{{.Module}}</td><td>{{len .Versions}}</td><td{{if .Skew}} class="skew"{{end}}>{{range $repo, $v := .Versions}}{{$v}} <span style="opacity: 0.5">{{$repo}}</span><br>{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
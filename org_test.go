@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncCloneRejectsOptions(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	marker := filepath.Join(t.TempDir(), "ran")
+	for _, url := range []string{
+		"--upload-pack=touch " + marker + ";://example.com/repo",
+		"-uhttps://example.com/repo",
+	} {
+		if _, err := syncClone(context.Background(), url); err == nil {
+			t.Errorf("syncClone(%q) succeeded", url)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("a repository URL ran a command")
+	}
+}
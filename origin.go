@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(originAnalyzer{})
+}
+
+// originAnalyzer annotates external modules with where their version came
+// from, as recorded in the module cache: "vcs", "origin" (the repository
+// URL, with "origin-subdir" for modules in a subdirectory), "ref" and
+// "commit". Pseudo-versions name their commit even without an origin, so
+// for those "commit" is at least the abbreviated hash.
+type originAnalyzer struct{}
+
+func (originAnalyzer) Name() string { return "origin" }
+
+func (originAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if !isExternal(node) || strings.HasPrefix(node.ID, "import:") || node.Version == "" {
+			continue
+		}
+		if info, ok := readModuleInfo(node.ID, node.Version); ok && info.Origin != nil {
+			for key, value := range map[string]string{
+				"vcs":           info.Origin.VCS,
+				"origin":        info.Origin.URL,
+				"origin-subdir": info.Origin.Subdir,
+				"ref":           info.Origin.Ref,
+				"commit":        info.Origin.Hash,
+			} {
+				if value != "" {
+					graph.Annotate(node.ID, key, value)
+				}
+			}
+		}
+		if node.Annotations["commit"] == "" && module.IsPseudoVersion(node.Version) {
+			if rev, err := module.PseudoVersionRev(node.Version); err == nil {
+				graph.Annotate(node.ID, "commit", rev)
+			}
+		}
+	}
+	return nil
+}
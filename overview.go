@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+// moduleOverview reduces a graph to the module dependency DAG: the main
+// module and every module of the build list, direct and transitive, linked
+// by their requirements at the selected versions, as `go mod graph` reports
+// them. Package and import path nodes are dropped. When the go command
+// cannot list the module graph, the modules the imports reach are kept
+// instead.
+func moduleOverview(ctx context.Context, graph *depgraph.Graph) *depgraph.Graph {
+	mainModule, reqs, err := versionedModuleGraph(ctx)
+	if err != nil {
+		log.Printf(tr("⚠️ Listing the module graph failed, showing the imported modules only: %v"), err)
+		return importedModules(graph)
+	}
+
+	// Minimal version selection picks the highest version required anywhere
+	selected := map[string]string{mainModule: ""}
+	seen := map[string]bool{mainModule: true}
+	queue := []string{mainModule}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for _, to := range reqs[from] {
+			if !seen[to] {
+				seen[to] = true
+				queue = append(queue, to)
+			}
+			path, version, _ := strings.Cut(to, "@")
+			if semver.Compare(version, selected[path]) > 0 {
+				selected[path] = version
+			}
+		}
+	}
+
+	// The DAG is what the selected versions require
+	nodes := make(map[string]depgraph.Node)
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	overview := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}}
+	added := map[string]bool{mainModule: true}
+	queue = []string{mainModule}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		node, ok := nodes[path]
+		if !ok {
+			node = depgraph.Node{ID: path, Label: path, FullPath: path, Type: "external", Depth: 2}
+			if path == mainModule {
+				node.Type, node.Depth = "main", 0
+			}
+		}
+		node.Version = selected[path]
+		overview.Nodes = append(overview.Nodes, node)
+
+		key := path
+		if path != mainModule {
+			key += "@" + selected[path]
+		}
+		for _, to := range reqs[key] {
+			target, _, _ := strings.Cut(to, "@")
+			overview.Edges = append(overview.Edges, depgraph.Edge{Source: path, Target: target})
+			if !added[target] {
+				added[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+	overview.Sort()
+	return overview
+}
+
+// importedModules keeps the main and external module nodes of a graph and
+// the edges between them.
+func importedModules(graph *depgraph.Graph) *depgraph.Graph {
+	kept := make(map[string]bool)
+	overview := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}}
+	for _, node := range graph.Nodes {
+		if node.Type == "main" || isExternal(&node) && !strings.HasPrefix(node.ID, "import:") {
+			kept[node.ID] = true
+			overview.Nodes = append(overview.Nodes, node)
+		}
+	}
+	for _, edge := range graph.Edges {
+		if kept[edge.Source] && kept[edge.Target] {
+			overview.Edges = append(overview.Edges, edge)
+		}
+	}
+	return overview
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(ownersAnalyzer{})
+}
+
+// ownersAnalyzer annotates packages with their owners from a CODEOWNERS
+// file, unless an owner annotation is already present.
+type ownersAnalyzer struct{}
+
+func (ownersAnalyzer) Name() string { return "owners" }
+
+func (ownersAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	rules := readCodeowners(targetPath)
+	if len(rules) == 0 {
+		return nil
+	}
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if node.Type != "package" || node.Annotations["owner"] != "" {
+			continue
+		}
+		dir, _ := depgraph.PackageDir(node.ID)
+		if dir == "." {
+			dir = ""
+		}
+		if owner := ownerOf(rules, dir); owner != "" {
+			graph.Annotate(node.ID, "owner", owner)
+		}
+	}
+	return nil
+}
+
+type ownerRule struct {
+	pattern string
+	owners  string
+}
+
+// readCodeowners reads CODEOWNERS from the locations GitHub and GitLab look in.
+func readCodeowners(root string) []ownerRule {
+	for _, name := range []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"} {
+		f, err := os.Open(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		var rules []ownerRule
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[") {
+				continue
+			}
+			rules = append(rules, ownerRule{fields[0], strings.Join(fields[1:], " ")})
+		}
+		return rules
+	}
+	return nil
+}
+
+// ownerOf returns the owners of a package directory. As in CODEOWNERS, the
+// last matching rule wins. Patterns are matched against directories, so file
+// patterns such as *.go apply to every package.
+func ownerOf(rules []ownerRule, dir string) string {
+	var owners string
+	for _, rule := range rules {
+		if matchOwnerPattern(rule.pattern, dir) {
+			owners = rule.owners
+		}
+	}
+	return owners
+}
+
+func matchOwnerPattern(pattern, dir string) bool {
+	p := strings.TrimSuffix(pattern, "/")
+	p = strings.TrimSuffix(p, "/**")
+	anchored := strings.HasPrefix(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	if p == "*" || p == "**" || (strings.HasPrefix(p, "*.") && !strings.Contains(p, "/")) {
+		return true
+	}
+	if anchored || strings.Contains(p, "/") {
+		if dir == p || strings.HasPrefix(dir, p+"/") {
+			return true
+		}
+		ok, _ := path.Match(p, dir)
+		return ok
+	}
+	// Unanchored names match a directory at any depth
+	for _, segment := range strings.Split(dir, "/") {
+		if ok, _ := path.Match(p, segment); ok {
+			return true
+		}
+	}
+	return false
+}
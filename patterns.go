@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// packagePatterns limits the analysis to matching packages and what they
+// import, set when the target is a package pattern.
+var packagePatterns []string
+
+// packagePattern resolves a target that is a package pattern, like
+// ./cmd/... or example.com/m/internal/..., or a package directory below a
+// module root to the root of its module and the pattern relative to it, so
+// the analysis is scoped the way go tooling scopes it. Patterns matching the
+// whole module, like ./..., resolve to no pattern. It reports false for
+// module roots and workspaces and for anything else it cannot resolve.
+func packagePattern(target string) (string, []string, bool) {
+	target = filepath.ToSlash(filepath.Clean(target))
+	dir := target
+	if before, _, wildcard := strings.Cut(target, "..."); wildcard {
+		dir = path.Dir(before + "x")
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return directoryPattern(target, dir)
+	}
+
+	// An import path of the module in the current directory
+	root, data, ok := enclosingModule(".")
+	if !ok {
+		return "", nil, false
+	}
+	module := modfile.ModulePath(data)
+	if module == "" || target != module && !strings.HasPrefix(target, module+"/") {
+		return "", nil, false
+	}
+	if target == module || target == module+"/..." {
+		return root, nil, true
+	}
+	return root, []string{target}, true
+}
+
+// directoryPattern resolves a pattern rooted at an existing directory.
+func directoryPattern(target, dir string) (string, []string, bool) {
+	if !strings.Contains(target, "...") && (exists(filepath.Join(dir, "go.mod")) || exists(filepath.Join(dir, "go.work"))) {
+		return "", nil, false
+	}
+	root, _, ok := enclosingModule(dir)
+	if !ok {
+		return "", nil, false
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", nil, false
+	}
+	suffix := strings.TrimPrefix(target, dir)
+	if dir == "." && target != "." {
+		suffix = "/" + target
+	}
+	if rel == "." {
+		if suffix == "/..." || suffix == "" {
+			return root, nil, true
+		}
+		return root, []string{"." + suffix}, true
+	}
+	return root, []string{"./" + filepath.ToSlash(rel) + suffix}, true
+}
+
+// enclosingModule finds the go.mod of the module containing dir, returning
+// the module root and the go.mod contents.
+func enclosingModule(dir string) (string, []byte, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, false
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, data, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil, false
+		}
+		dir = parent
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
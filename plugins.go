@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"plugin"
+
+	"go-raph/depgraph"
+)
+
+// loadPlugin opens a Go plugin built with -buildmode=plugin. Plugins either
+// call depgraph.Register from an init function or export an Analyzer symbol;
+// an exported Analyzer that init already registered is not registered again.
+func loadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup("Analyzer")
+	if err != nil {
+		// Registration from init is enough
+		return nil
+	}
+	var a depgraph.Analyzer
+	switch s := sym.(type) {
+	case depgraph.Analyzer:
+		a = s
+	case *depgraph.Analyzer:
+		a = *s
+	default:
+		return fmt.Errorf("symbol Analyzer in %s has type %T, want depgraph.Analyzer", path, sym)
+	}
+	if !depgraph.Registered(a.Name()) {
+		depgraph.Register(a)
+	}
+	return nil
+}
+
+func loadPlugins() {
+	for _, path := range pluginPaths {
+		if err := loadPlugin(path); err != nil {
+			fmt.Printf(tr("❌ Failed to load plugin '%s': %v\n"), path, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// runAnalyzers lets every registered analyzer enrich the graph. A failing
+// analyzer is logged and skipped so the graph itself is still served.
+func runAnalyzers(ctx context.Context, graph *depgraph.Graph) {
+	for _, a := range depgraph.Analyzers() {
+		if err := a.Enrich(ctx, graph); err != nil {
+			log.Printf("⚠️ analyzer %s: %v", a.Name(), err)
+		}
+	}
+}
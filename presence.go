@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// presence is what a viewer is pointing at, shared so people reviewing the
+// graph together see each other's selection and hover.
+type presence struct {
+	Client   string `json:"client"`
+	Name     string `json:"name,omitempty"`
+	Selected string `json:"selected,omitempty"`
+	Hovered  string `json:"hovered,omitempty"`
+	Gone     bool   `json:"gone,omitempty"` // the viewer disconnected
+}
+
+var clientIDs atomic.Int64
+
+// presences holds the latest presence of every connected client.
+var presences = struct {
+	sync.Mutex
+	clients map[*client]presence
+}{clients: make(map[*client]presence)}
+
+func nextClientID() string {
+	return strconv.FormatInt(clientIDs.Add(1), 10)
+}
+
+// sendPresences tells a new client where everyone else is pointing.
+func sendPresences(c *client) {
+	presences.Lock()
+	list := make([]presence, 0, len(presences.clients))
+	for _, p := range presences.clients {
+		list = append(list, p)
+	}
+	presences.Unlock()
+	c.send(map[string]interface{}{"presences": list})
+}
+
+// updatePresence records where a client points and tells the others.
+func updatePresence(c *client, msg clientMessage) {
+	p := presence{Client: c.id, Name: msg.Author, Selected: msg.Node, Hovered: msg.Hovered}
+	presences.Lock()
+	presences.clients[c] = p
+	presences.Unlock()
+	broadcastExcept(c, map[string]interface{}{"presence": p})
+}
+
+// leavePresence forgets a disconnected client and tells the others.
+func leavePresence(c *client) {
+	presences.Lock()
+	_, shared := presences.clients[c]
+	delete(presences.clients, c)
+	presences.Unlock()
+	if shared {
+		broadcastExcept(c, map[string]interface{}{"presence": presence{Client: c.id, Gone: true}})
+	}
+}
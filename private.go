@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+
+	"go-raph/depgraph"
+)
+
+// privateType is the node type of modules the go command keeps away from
+// public services. Only local information, like the module cache, is used
+// for them.
+const privateType = "internal-external"
+
+// goEnv returns a go command setting from the environment, or else from
+// `go env`, which also sees values set with go env -w.
+func goEnv(name string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	out, err := exec.Command("go", "env", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// privatePatterns are the GOPRIVATE, GONOPROXY and GONOSUMDB globs.
+var privatePatterns = sync.OnceValue(func() []string {
+	var patterns []string
+	for _, name := range []string{"GOPRIVATE", "GONOPROXY", "GONOSUMDB"} {
+		if v := goEnv(name); v != "" {
+			patterns = append(patterns, v)
+		}
+	}
+	return patterns
+})
+
+// isPrivate reports whether a module or import path matches GOPRIVATE,
+// GONOPROXY or GONOSUMDB.
+func isPrivate(path string) bool {
+	for _, patterns := range privatePatterns() {
+		if module.MatchPrefixPatterns(patterns, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// markPrivate retypes external modules and packages that are private,
+// tooling included, so that no network lookup sends them to a public API.
+func markPrivate(graph *depgraph.Graph) {
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if isPublic(node) && isPrivate(strings.TrimPrefix(node.ID, "import:")) {
+			node.Type = privateType
+		}
+	}
+}
+
+// isExternal reports whether a node is a dependency outside the main module,
+// public or private, runtime or tooling.
+func isExternal(node *depgraph.Node) bool {
+	return isPublic(node) || node.Type == privateType
+}
+
+// isPublic reports whether a node is a public module or package, which
+// network lookups may be made for.
+func isPublic(node *depgraph.Node) bool {
+	return node.Type == "external" || node.Type == "tooling"
+}
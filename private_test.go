@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+// setPrivatePatterns makes isPrivate use patterns for the rest of a test.
+func setPrivatePatterns(t *testing.T, patterns ...string) {
+	saved := privatePatterns
+	privatePatterns = func() []string { return patterns }
+	t.Cleanup(func() { privatePatterns = saved })
+}
+
+func TestIsPrivate(t *testing.T) {
+	setPrivatePatterns(t, "git.corp.example,*.internal.example/team", "github.com/acme/secret")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"git.corp.example/lib", true},
+		{"git.corp.example", true},
+		{"git.corp.example.evil.com/lib", false},
+		{"a.internal.example/team/x", true},
+		{"a.internal.example/other", false},
+		{"github.com/acme/secret/v2", true},
+		{"github.com/acme/secrets", false},
+		{"github.com/acme/public", false},
+	}
+	for _, test := range tests {
+		if got := isPrivate(test.path); got != test.want {
+			t.Errorf("isPrivate(%s) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestMarkPrivate(t *testing.T) {
+	setPrivatePatterns(t, "git.corp.example")
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{
+		{ID: "example.com/app", Type: "main"},
+		{ID: "git.corp.example/lib", Type: "external"},
+		{ID: "import:git.corp.example/lib/auth", Type: "external"},
+		{ID: "git.corp.example/gen", Type: "tooling"},
+		{ID: "github.com/pkg/errors", Type: "external"},
+	}}
+	markPrivate(graph)
+	want := []string{"main", privateType, privateType, privateType, "external"}
+	for i, node := range graph.Nodes {
+		if node.Type != want[i] {
+			t.Errorf("%s: type %s, want %s", node.ID, node.Type, want[i])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	_ "net/http/pprof" // registers /debug/pprof/ on the default mux
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+)
+
+var (
+	pprofEnabled bool   // -pprof
+	cpuProfile   string // -cpuprofile
+	memProfile   string // -memprofile
+)
+
+// guardPprof hides the /debug/pprof/ handlers net/http/pprof registers
+// unless -pprof is set, as profiles reveal the source tree.
+func guardPprof(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !pprofEnabled && strings.HasPrefix(r.URL.Path, "/debug/pprof") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startProfiles starts the -cpuprofile CPU profile of a headless run; the
+// returned function stops it and writes the -memprofile heap profile.
+func startProfiles() func() {
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err == nil {
+			err = pprof.StartCPUProfile(f)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, tr("❌ Cannot profile: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+	return func() {
+		if cpuProfile != "" {
+			pprof.StopCPUProfile()
+		}
+		if memProfile != "" {
+			f, err := os.Create(memProfile)
+			if err == nil {
+				runtime.GC() // up-to-date statistics
+				err = pprof.WriteHeapProfile(f)
+				f.Close()
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, tr("❌ Cannot profile: %v\n"), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+// moduleProxy is the module proxy asked for versions: the first HTTP(S) or
+// file entry of GOPROXY, or the public proxy when GOPROXY is unset. It is
+// empty when GOPROXY names no proxy, like off or direct, and then no proxy is
+// queried.
+var moduleProxy = sync.OnceValue(func() string {
+	goproxy := goEnv("GOPROXY")
+	if goproxy == "" {
+		return "https://proxy.golang.org"
+	}
+	for _, p := range strings.FieldsFunc(goproxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.HasPrefix(p, "https://") || strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "file://") {
+			return strings.TrimSuffix(p, "/")
+		}
+	}
+	return ""
+})
+
+// latestVersions caches proxy answers for the life of the process.
+var latestVersions sync.Map // module path -> string
+
+// latestVersion returns the latest version of a module known to the proxy:
+// like the go command, the highest release, else the highest pre-release,
+// else whatever @latest resolves to.
+func latestVersion(ctx context.Context, path string) (string, bool) {
+	if v, ok := latestVersions.Load(path); ok {
+		return v.(string), v != ""
+	}
+	escPath, err := module.EscapePath(path)
+	if err != nil || moduleProxy() == "" {
+		return "", false
+	}
+	versions, err := proxyVersions(ctx, escPath)
+	if err != nil {
+		return "", false // not cached, the proxy may be reachable later
+	}
+	var latest string
+	for _, v := range versions {
+		if latest == "" || semver.Prerelease(v) == "" || semver.Prerelease(latest) != "" {
+			latest = v
+		}
+	}
+	if latest == "" {
+		var info struct{ Version string }
+		if err := fetchJSON(ctx, moduleProxy()+"/"+escPath+"/@latest", &info); err != nil {
+			return "", false
+		}
+		latest = info.Version
+	}
+	latestVersions.Store(path, latest)
+	return latest, latest != ""
+}
+
+// proxyVersions returns the tagged versions of a module, escaped with
+// module.EscapePath, in semver order. A file:// proxy like the module cache
+// only has a list for modules once asked for versions, so there the
+// versions with an .info file are taken instead.
+func proxyVersions(ctx context.Context, escPath string) ([]string, error) {
+	var names []string
+	if dir, ok := strings.CutPrefix(moduleProxy(), "file://"); ok {
+		entries, err := os.ReadDir(filepath.FromSlash(dir + "/" + escPath + "/@v"))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if escVersion, ok := strings.CutSuffix(e.Name(), ".info"); ok {
+				if v, err := module.UnescapeVersion(escVersion); err == nil {
+					names = append(names, v)
+				}
+			}
+		}
+	} else {
+		list, err := fetchText(ctx, moduleProxy()+"/"+escPath+"/@v/list")
+		if err != nil {
+			return nil, err
+		}
+		names = strings.Fields(list)
+	}
+
+	var versions []string
+	for _, v := range names {
+		if semver.IsValid(v) && !module.IsPseudoVersion(v) {
+			versions = append(versions, v)
+		}
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// countOutdated returns how many required modules in the graph have a newer
+// version available.
+func countOutdated(ctx context.Context, graph *depgraph.Graph) int {
+	outdated := 0
+	for _, node := range graph.Nodes {
+		if !isPublic(&node) || node.Version == "" {
+			continue
+		}
+		if latest, ok := latestVersion(ctx, node.ID); ok && semver.Compare(node.Version, latest) < 0 {
+			outdated++
+		}
+	}
+	return outdated
+}
+
+// lookupModules calls lookup for every required external module in the
+// graph, a few at a time, and returns the results by module path.
+func lookupModules[T any](ctx context.Context, graph *depgraph.Graph, lookup func(context.Context, string) T) map[string]T {
+	var mu sync.Mutex
+	results := make(map[string]T)
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, 8)
+	for _, node := range graph.Nodes {
+		if !isPublic(&node) || node.Version == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			result := lookup(ctx, path)
+			mu.Lock()
+			results[path] = result
+			mu.Unlock()
+		}(node.ID)
+	}
+	wg.Wait()
+	return results
+}
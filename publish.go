@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"go-raph/depgraph"
+)
+
+// moduleDetail is everything the per-module page shows.
+type moduleDetail struct {
+	Node        depgraph.Node
+	Page        string
+	SubPackages []string
+	Importers   []string
+	Vulns       []vulnerability
+}
+
+type site struct {
+	Module  string
+	Graph   *depgraph.Graph
+	Metrics graphMetrics
+	Modules []moduleDetail
+	Vulns   map[string][]vulnerability
+	VulnErr string
+}
+
+// publishCommand implements `go-raph publish`, writing a static site for
+// GitHub Pages: the interactive overview, per-module pages, metrics and a
+// vulnerability report.
+func publishCommand(args []string) {
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	out := fs.String("o", "docs/deps", "Output directory")
+	withVulns := fs.Bool("vulns", true, "Include a vulnerability report from the Go vulnerability database")
+	addAnalysisFlags(fs)
+	parseFlags(fs, args)
+
+	goOffline()
+	loadPlugins()
+	loadSavedGraph()
+	resolveTarget(fs)
+
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		fmt.Printf(tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+
+	s := &site{Graph: graph, Metrics: computeMetrics(graph)}
+	if *withVulns {
+		if s.Vulns, err = scanVulns(context.Background(), graph); err != nil {
+			s.VulnErr = err.Error()
+			fmt.Printf(tr("⚠️ Vulnerability lookup failed: %v\n"), err)
+		}
+	}
+	s.build()
+
+	if err := s.write(*out); err != nil {
+		fmt.Printf(tr("❌ Publish failed: %v\n"), err)
+		os.Exit(1)
+	}
+	fmt.Printf(tr("📦 Published %d module pages to %s\n"), len(s.Modules), *out)
+}
+
+// build derives the per-module details from the graph.
+func (s *site) build() {
+	importers, owner := moduleImporters(s.Graph)
+
+	for _, node := range s.Graph.Nodes {
+		switch {
+		case node.Type == "main":
+			s.Module = node.ID
+		case isExternal(&node) && !strings.HasPrefix(node.ID, "import:"):
+			detail := moduleDetail{
+				Node:      node,
+				Page:      modulePage(node.ID),
+				Importers: importers[node.ID],
+				Vulns:     s.Vulns[node.ID],
+			}
+			for imp, module := range owner {
+				if module == node.ID {
+					detail.SubPackages = append(detail.SubPackages, strings.TrimPrefix(imp, "import:"))
+				}
+			}
+			sort.Strings(detail.SubPackages)
+			s.Modules = append(s.Modules, detail)
+		}
+	}
+	sort.Slice(s.Modules, func(i, j int) bool { return s.Modules[i].Node.ID < s.Modules[j].Node.ID })
+}
+
+// modulePage is the path of a module's page, which mirrors the module path
+// as the module cache does: example.com/Foo/bar is at
+// modules/example.com/!foo/bar.html. Paths that could not be escaped, and
+// so could not be in go.mod either, get a page named by their hash.
+func modulePage(id string) string {
+	escaped, err := module.EscapePath(id)
+	if err != nil {
+		sum := sha256.Sum256([]byte(id))
+		escaped = "_/" + hex.EncodeToString(sum[:8])
+	}
+	return "modules/" + escaped + ".html"
+}
+
+func (s *site) write(dir string) error {
+	// The overview is the regular visualizer with the graph inlined
+	page, err := inlineGraph(visualizerHTML, s.Graph)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	page = bytes.Replace(page, []byte("<body>"), []byte(`<body>
+    <div style="position: absolute; bottom: 20px; left: 20px; font-size: 11px; z-index: 10;">
+        <a href="modules.html" style="color: #8af;">modules</a> ·
+        <a href="metrics.html" style="color: #8af;">metrics</a> ·
+        <a href="vulns.html" style="color: #8af;">vulnerabilities</a>
+    </div>`), 1)
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), page, 0o644); err != nil {
+		return err
+	}
+
+	pages := map[string]string{
+		"modules.html": "modules",
+		"metrics.html": "metrics",
+		"vulns.html":   "vulns",
+	}
+	for name, tmpl := range pages {
+		if err := renderPage(filepath.Join(dir, name), tmpl, "", s); err != nil {
+			return err
+		}
+	}
+	for _, m := range s.Modules {
+		path := filepath.Join(dir, filepath.FromSlash(m.Page))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		root := strings.Repeat("../", strings.Count(m.Page, "/"))
+		if err := renderPage(path, "module", root, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderPage(path, name, root string, data interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return siteTemplates.ExecuteTemplate(f, name, map[string]interface{}{"Root": root, "Data": data, "Style": currentTheme().pageCSS()})
+}
+
+var siteTemplates = template.Must(template.New("site").Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<title>go-raph</title>
+<style>
+{{.Style}}
+body { font-size: 12px; margin: 40px; }
+table { border-collapse: collapse; margin-top: 12px; }
+td, th { padding: 4px 12px; text-align: left; border-bottom: 1px solid #222; }
+.vuln { color: rgba(255,100,100,1); }
+</style>
+</head>
+<body>
+<p><a href="{{.Root}}index.html">graph</a> · <a href="{{.Root}}modules.html">modules</a> · <a href="{{.Root}}metrics.html">metrics</a> · <a href="{{.Root}}vulns.html">vulnerabilities</a></p>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "modules"}}{{template "header" .}}{{with .Data}}
+<h2>modules of {{.Module}}</h2>
+<table>
+<tr><th>module</th><th>version</th><th>description</th><th>importers</th><th>vulnerabilities</th></tr>
+{{range .Modules}}<tr><td><a href="{{.Page}}">{{.Node.ID}}</a></td><td>{{.Node.Version}}</td><td>{{or .Node.Annotations.synopsis .Node.Annotations.readme}}</td><td>{{len .Importers}}</td><td{{if .Vulns}} class="vuln"{{end}}>{{len .Vulns}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "module"}}{{template "header" .}}{{with .Data}}
+<h2>{{.Node.ID}} {{.Node.Version}}</h2>
+{{with .Node.Annotations.synopsis}}<p>{{.}}</p>{{end}}
+{{with .Node.Annotations.readme}}<blockquote>{{.}}</blockquote>{{end}}
+{{with .Node.Annotations.doc}}<p><a href="{{.}}">documentation</a></p>{{end}}
+{{with .Node.Annotations}}<table>{{range $k, $v := .}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>{{end}}</table>{{end}}
+<h3>imported by</h3>
+<ul>{{range .Importers}}<li>{{.}}</li>{{else}}<li>nothing</li>{{end}}</ul>
+<h3>packages used</h3>
+<ul>{{range .SubPackages}}<li>{{.}}</li>{{else}}<li>module root only</li>{{end}}</ul>
+<h3>vulnerabilities</h3>
+<ul>{{range .Vulns}}<li class="vuln">{{.ID}}: {{.Summary}}{{if .Fixed}} (fixed in {{.Fixed}}){{end}}</li>{{else}}<li>none known</li>{{end}}</ul>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "metrics"}}{{template "header" .}}{{with .Data.Metrics}}
+<h2>metrics</h2>
+<table>
+<tr><td>packages</td><td>{{.Packages}}</td></tr>
+<tr><td>external modules</td><td>{{.Modules}}</td></tr>
+<tr><td>direct dependencies</td><td>{{.DirectDeps}}</td></tr>
+<tr><td>external packages</td><td>{{.Imports}}</td></tr>
+<tr><td>import edges</td><td>{{.Edges}}</td></tr>
+</table>
+<table>
+<tr><th>node</th><th>type</th><th>fan-in</th><th>fan-out</th></tr>
+{{range .Nodes}}<tr><td>{{.ID}}</td><td>{{.Type}}</td><td>{{.FanIn}}</td><td>{{.FanOut}}</td></tr>
+{{end}}</table>
+{{end}}{{template "footer"}}{{end}}
+
+{{define "vulns"}}{{template "header" .}}{{with .Data}}
+<h2>vulnerabilities</h2>
+{{if .VulnErr}}<p class="vuln">lookup failed: {{.VulnErr}}</p>{{end}}
+<table>
+<tr><th>module</th><th>id</th><th>summary</th><th>fixed in</th></tr>
+{{range .Modules}}{{$m := .}}{{range .Vulns}}<tr><td><a href="{{$m.Page}}">{{$m.Node.ID}}</a> {{$m.Node.Version}}</td><td>{{.ID}}</td><td>{{.Summary}}</td><td>{{.Fixed}}</td></tr>
+{{end}}{{end}}</table>
+{{end}}{{template "footer"}}{{end}}
+`))
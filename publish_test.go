@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestModulePage(t *testing.T) {
+	tests := []struct {
+		id, want string
+	}{
+		{"golang.org/x/text", "modules/golang.org/x/text.html"},
+		{"github.com/Azure/go-autorest", "modules/github.com/!azure/go-autorest.html"},
+		{"github.com/azure/go-autorest", "modules/github.com/azure/go-autorest.html"},
+		// once flattened with / as _, these two shared a page
+		{"example.com/a_b", "modules/example.com/a_b.html"},
+		{"example.com/a/b", "modules/example.com/a/b.html"},
+		{"not a module path", "modules/_/a83663140441e7bd.html"},
+	}
+	for _, test := range tests {
+		if got := modulePage(test.id); got != test.want {
+			t.Errorf("modulePage(%q) = %q, want %q", test.id, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(readmeAnalyzer{})
+}
+
+// maxReadmeSummary bounds the "readme" annotation, in characters.
+const maxReadmeSummary = 240
+
+// readmeAnalyzer annotates external modules found in the module cache with
+// "readme", the first paragraph of prose of their README, so reviewers can
+// tell what a module does from the graph.
+type readmeAnalyzer struct{}
+
+func (readmeAnalyzer) Name() string { return "readme" }
+
+func (readmeAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if !isExternal(node) || strings.HasPrefix(node.ID, "import:") || node.Version == "" {
+			continue
+		}
+		dir, ok := moduleDir(node.ID, node.Version)
+		if !ok {
+			continue
+		}
+		if summary := readmeSummary(dir); summary != "" {
+			graph.Annotate(node.ID, "readme", summary)
+		}
+	}
+	return nil
+}
+
+// readmeNames are the README files looked for, in order of preference.
+var readmeNames = []string{"README.md", "README.markdown", "README", "README.txt", "README.rst", "readme.md", "Readme.md"}
+
+// readmeSummary returns the first paragraph of prose of the README in dir,
+// with markdown markup removed, truncated at a word to maxReadmeSummary.
+func readmeSummary(dir string) string {
+	for _, name := range readmeNames {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		return truncateWords(stripMarkdown(firstParagraph(bufio.NewScanner(f))), maxReadmeSummary)
+	}
+	return ""
+}
+
+// firstParagraph joins the lines of the first paragraph that is not a
+// heading, badge, image, HTML, table, list, quote or code block.
+func firstParagraph(scanner *bufio.Scanner) string {
+	var lines []string
+	fenced := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+			continue
+		}
+		if isProse(lines) {
+			break
+		}
+		lines = nil
+	}
+	if !isProse(lines) {
+		return ""
+	}
+	return strings.Join(lines, " ")
+}
+
+// isProse reports whether the lines of a README paragraph are prose rather
+// than a heading, underlined or not, or a block starting with markup.
+func isProse(lines []string) bool {
+	if len(lines) == 0 {
+		return false
+	}
+	for _, prefix := range []string{"#", "[!", "![", "<", "|", ">", "- ", "* ", "+ ", "..", ":"} {
+		if strings.HasPrefix(lines[0], prefix) {
+			return false
+		}
+	}
+	if linkDefinition.MatchString(lines[0]) {
+		return false
+	}
+	for _, line := range lines {
+		if strings.Trim(line, "=") == "" || strings.Trim(line, "-") == "" {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	markdownLink     = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownEmphasis = regexp.MustCompile("(\\*\\*|__|\\*|`)")
+	markdownItalics  = regexp.MustCompile(`\b_([^_]+)_\b`)
+	htmlTag          = regexp.MustCompile(`<[^>]*>`)
+	linkDefinition   = regexp.MustCompile(`^\[[^\]]+\]:`)
+)
+
+// stripMarkdown removes links, images, emphasis, code spans and HTML tags,
+// keeping their text.
+func stripMarkdown(s string) string {
+	s = markdownLink.ReplaceAllString(s, "$1")
+	s = markdownItalics.ReplaceAllString(s, "$1")
+	s = htmlTag.ReplaceAllString(s, "")
+	return markdownEmphasis.ReplaceAllString(s, "")
+}
+
+// truncateWords shortens s to at most n characters, cutting at a word and
+// marking the cut with an ellipsis.
+func truncateWords(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > n/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:") + "…"
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// maxRecordingEvents bounds a recording; later events are dropped.
+const maxRecordingEvents = 20000
+
+// recording is a session of one viewer: every message the server sent it,
+// graphs included, and every command it sent, timed from the start, so the
+// walkthrough can be replayed at /replay/{id}.
+type recording struct {
+	ID      string           `json:"id"`
+	Started time.Time        `json:"started"`
+	Events  []recordingEvent `json:"events"`
+}
+
+type recordingEvent struct {
+	T   int64           `json:"t"`             // milliseconds since the start
+	In  json.RawMessage `json:"in,omitempty"`  // message the server sent
+	Out json.RawMessage `json:"out,omitempty"` // command the viewer sent
+}
+
+var recordingID = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// add appends an event; callers serialize access through the client's lock.
+func (r *recording) add(in bool, v interface{}) {
+	if len(r.Events) >= maxRecordingEvents {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	event := recordingEvent{T: time.Since(r.Started).Milliseconds()}
+	if in {
+		event.In = data
+	} else {
+		event.Out = data
+	}
+	r.Events = append(r.Events, event)
+}
+
+// recordCommand adds a command the client sent to its running recording.
+func (c *client) recordCommand(msg clientMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.recording != nil {
+		c.recording.add(false, msg)
+	}
+}
+
+func recordingsDir() string {
+	return filepath.Join(stateDir(), "recordings")
+}
+
+// toggleRecording starts recording a client's session, beginning with the
+// graph it currently shows, or stops and saves the running recording.
+func toggleRecording(c *client) {
+	c.mu.Lock()
+	r := c.recording
+	started := r == nil
+	if started {
+		id := make([]byte, 8)
+		rand.Read(id)
+		r = &recording{ID: hex.EncodeToString(id), Started: time.Now()}
+		c.recording = r
+	} else {
+		c.recording = nil
+	}
+	c.mu.Unlock()
+
+	if started {
+		if graph, err := currentGraph(context.Background()); err == nil {
+			c.sendAll(graphMessages(graph)) // recorded as the opening graph
+		}
+		c.send(map[string]interface{}{"recording": map[string]interface{}{"id": r.ID, "active": true}})
+		return
+	}
+	if err := saveRecording(r); err != nil {
+		c.send(map[string]interface{}{"error": "saving the recording failed: " + err.Error()})
+		return
+	}
+	c.send(map[string]interface{}{"recording": map[string]interface{}{"id": r.ID, "active": false, "url": c.base + "replay/" + r.ID}})
+}
+
+// saveUnfinished keeps the recording of a client that disconnected while
+// recording.
+func saveUnfinished(c *client) {
+	c.mu.Lock()
+	r := c.recording
+	c.recording = nil
+	c.mu.Unlock()
+	if r != nil {
+		if err := saveRecording(r); err != nil {
+			log.Printf(tr("⚠️ Saving recording %s failed: %v"), r.ID, err)
+		}
+	}
+}
+
+func saveRecording(r *recording) error {
+	if err := os.MkdirAll(recordingsDir(), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(recordingsDir(), r.ID+".json"), data, 0o644)
+}
+
+// recordingSummary describes a saved recording without its events.
+type recordingSummary struct {
+	ID       string    `json:"id"`
+	Started  time.Time `json:"started"`
+	Duration int64     `json:"duration"` // milliseconds
+	Events   int       `json:"events"`
+}
+
+// recordingsHandler serves /api/recordings:
+//
+//	GET /api/recordings       list the saved recordings
+//	GET /api/recordings/{id}  get one recording with its events
+func recordingsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if id != "" {
+		if !recordingID.MatchString(id) {
+			http.Error(w, "recording not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, filepath.Join(recordingsDir(), id+".json"))
+		return
+	}
+
+	list := []recordingSummary{}
+	names, _ := filepath.Glob(filepath.Join(recordingsDir(), "*.json"))
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var rec recording
+		if json.Unmarshal(data, &rec) != nil {
+			continue
+		}
+		s := recordingSummary{ID: rec.ID, Started: rec.Started, Events: len(rec.Events)}
+		if len(rec.Events) > 0 {
+			s.Duration = rec.Events[len(rec.Events)-1].T
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.After(list[j].Started) })
+	respondJSON(w, list)
+}
+
+// replayHandler serves the visualizer replaying the recording /replay/{id}
+// instead of connecting to the live graph.
+func replayHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !recordingID.MatchString(id) {
+		http.Error(w, "recording not found", http.StatusNotFound)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(recordingsDir(), id+".json")); err != nil {
+		http.Error(w, "recording not found", http.StatusNotFound)
+		return
+	}
+	page := bytes.Replace(visualizerPage(r), []byte("</head>"), []byte(`<script>window.goraphReplay = "`+id+`";</script>
+</head>`), 1)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+var (
+	trackReleases  bool // -releases
+	abandonedYears = 2  // -abandoned-after
+)
+
+// releaseWindow is how many of the latest releases the cadence is averaged over.
+const releaseWindow = 6
+
+func init() {
+	depgraph.Register(releaseAnalyzer{})
+}
+
+// releaseAnalyzer annotates external modules with their last release date
+// and release cadence from the module proxy, and flags the ones without a
+// release in -abandoned-after years. It only runs with -releases.
+type releaseAnalyzer struct{}
+
+func (releaseAnalyzer) Name() string { return "releases" }
+
+func (releaseAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if !trackReleases {
+		return nil
+	}
+	history := lookupModules(ctx, graph, releaseTimes)
+	for path, times := range history {
+		if len(times) == 0 {
+			continue
+		}
+		last := times[len(times)-1]
+		graph.Annotate(path, "last-release", last.Format(time.DateOnly))
+		if len(times) > 1 {
+			days := last.Sub(times[0]).Hours() / 24 / float64(len(times)-1)
+			graph.Annotate(path, "release-cadence", fmt.Sprintf("every %.0f days", days))
+		}
+		if abandonedYears > 0 && last.Before(time.Now().AddDate(-abandonedYears, 0, 0)) {
+			graph.Annotate(path, "abandoned", fmt.Sprintf("no release in over %d years", abandonedYears))
+		}
+	}
+	return nil
+}
+
+// releaseHistory caches proxy answers for the life of the process.
+var releaseHistory sync.Map // module path -> []time.Time
+
+// releaseTimes returns when the latest tagged releases of a module were
+// published, in time order. Modules without tags fall back to the version
+// @latest resolves to.
+func releaseTimes(ctx context.Context, path string) []time.Time {
+	if v, ok := releaseHistory.Load(path); ok {
+		return v.([]time.Time)
+	}
+	escPath, err := module.EscapePath(path)
+	if err != nil || moduleProxy() == "" {
+		return nil
+	}
+	base := moduleProxy() + "/" + escPath + "/@v/"
+
+	tagged, err := proxyVersions(ctx, escPath)
+	if err != nil {
+		return nil // not cached, the proxy may be reachable later
+	}
+	var versions []string
+	for _, v := range tagged {
+		if semver.Prerelease(v) == "" {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) > releaseWindow {
+		versions = versions[len(versions)-releaseWindow:]
+	}
+
+	var infos []string
+	for _, v := range versions {
+		if esc, err := module.EscapeVersion(v); err == nil {
+			infos = append(infos, base+esc+".info")
+		}
+	}
+	if len(infos) == 0 {
+		infos = []string{moduleProxy() + "/" + escPath + "/@latest"}
+	}
+	var times []time.Time
+	for _, url := range infos {
+		var info struct{ Time time.Time }
+		if err := fetchJSON(ctx, url, &info); err == nil {
+			times = append(times, info.Time)
+		}
+	}
+	if len(times) == 0 {
+		return nil
+	}
+	// Backports can be published after higher versions
+	slices.SortFunc(times, time.Time.Compare)
+	releaseHistory.Store(path, times)
+	return times
+}
+
+// fetchText GETs a plain text document.
+func fetchText(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	return string(data), err
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"time"
+
+	"go-raph/depgraph"
+)
+
+// Limits of sandboxed analyses, see analyzeSandboxed.
+var (
+	sandboxAnalysis bool
+	sandboxMemory   = 4096             // MiB of address space
+	sandboxCPU      = 300              // seconds of CPU time
+	sandboxTimeout  = 10 * time.Minute // wall time
+)
+
+// sandboxRequest is what the server hands an analysis worker on stdin.
+type sandboxRequest struct {
+	Path      string           `json:"path"`
+	Options   depgraph.Options `json:"options"`
+	Workspace bool             `json:"workspace,omitempty"` // -workspace
+	Offline   bool             `json:"offline,omitempty"`   // -offline
+	Memory    int              `json:"memory"`              // MiB
+	CPU       int              `json:"cpu"`                 // seconds
+}
+
+// analyzeSandboxed analyzes the project in a worker process limited in
+// memory, CPU and wall time, so a pathological repository kills the worker
+// rather than the server.
+func analyzeSandboxed(ctx context.Context, projectPath string) (*depgraph.Graph, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	req, err := json.Marshal(sandboxRequest{Path: projectPath, Options: analyzeOptions(), Workspace: workspaceMode, Offline: offline, Memory: sandboxMemory, CPU: sandboxCPU})
+	if err != nil {
+		return nil, err
+	}
+	if sandboxTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sandboxTimeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, exe, "analyze-worker")
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("analysis took longer than %s", sandboxTimeout)
+		}
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, errors.New(string(firstLine(msg)))
+		}
+		// Exceeding the CPU limit kills the worker with SIGXCPU
+		return nil, fmt.Errorf("analysis crashed: %v", err)
+	}
+	var graph depgraph.Graph
+	if err := json.Unmarshal(stdout.Bytes(), &graph); err != nil {
+		return nil, fmt.Errorf("reading the analysis: %w", err)
+	}
+	return &graph, nil
+}
+
+// analyzeWorkerCommand implements the hidden `go-raph analyze-worker`: it
+// limits its own resources, analyzes the project described on stdin and
+// writes the graph to stdout.
+func analyzeWorkerCommand() {
+	var req sandboxRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := limitResources(req.Memory, req.CPU); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if req.Memory > 0 {
+		// Collect harder before running into the hard limit
+		debug.SetMemoryLimit(int64(req.Memory) << 20 * 3 / 4)
+	}
+	setAnalyzeOptions(req.Options)
+	workspaceMode, offline = req.Workspace, req.Offline
+	goOffline()
+	graph, err := analyzeProject(req.Path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(graph); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// setAnalyzeOptions is the reverse of analyzeOptions.
+func setAnalyzeOptions(opts depgraph.Options) {
+	trackGenerics = opts.Generics
+	trackEmbeds = opts.Embeds
+	trackProtos = opts.Protos
+	trackDocker = opts.Docker
+	trackServices = opts.Services
+	trackGenerate = opts.Generate
+	fileNodes = opts.Files
+	packagePatterns = opts.Packages
+	internalOnly = opts.InternalOnly
+}
+
+// firstLine returns the first line of the worker's output, the error or
+// what the runtime aborted with before its stack traces.
+func firstLine(b []byte) []byte {
+	line, _, _ := bytes.Cut(b, []byte("\n"))
+	return line
+}
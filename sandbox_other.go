@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// limitResources is a no-op without rlimits; the worker still runs apart
+// from the server and within the wall time limit.
+func limitResources(memory, cpu int) error {
+	return nil
+}
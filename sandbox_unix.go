@@ -0,0 +1,23 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// limitResources caps the address space (MiB) and CPU time (seconds) of the
+// process; zero leaves a limit alone.
+func limitResources(memory, cpu int) error {
+	if memory > 0 {
+		limit := uint64(memory) << 20
+		if err := syscall.Setrlimit(syscall.RLIMIT_AS, &syscall.Rlimit{Cur: limit, Max: limit}); err != nil {
+			return err
+		}
+	}
+	if cpu > 0 {
+		limit := uint64(cpu)
+		if err := syscall.Setrlimit(syscall.RLIMIT_CPU, &syscall.Rlimit{Cur: limit, Max: limit}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
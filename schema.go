@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"go-raph/depgraph"
+)
+
+// nodeTypes describes the node types the analyzers produce.
+var nodeTypes = map[string]string{
+	"main":              "main module",
+	"package":           "package of the main module",
+	"file":              "Go file (-granularity file)",
+	"external":          "external module",
+	"internal-external": "private module (GOPRIVATE, GONOPROXY, GONOSUMDB)",
+	"tooling":           "module only used by tools.go, go.mod tool directives or //go:generate",
+	"asset":             "embedded asset (-embeds)",
+	"proto":             "protobuf file (-protos)",
+	"image":             "image built by a Dockerfile, or its base image (-docker)",
+	"artifact":          "file copied into an image (-docker)",
+	"service":           "Kubernetes service (-k8s)",
+	"tool":              "code generator run by //go:generate (-generate)",
+	"security-alert":    "module cache contents that do not match go.sum (-verify)",
+}
+
+// edgeKinds describes the edge kinds; the empty kind is a plain import.
+var edgeKinds = map[string]string{
+	"":               "imports",
+	"contains":       "package contains the file (-granularity file)",
+	"tool":           "main module names the tool in a go.mod tool directive",
+	"uses":           "file uses identifiers of the file (-granularity file)",
+	"instantiates":   "package instantiates generics of the package (-generics)",
+	"embeds":         "package embeds the asset (-embeds)",
+	"proto-import":   "protobuf file imports the file (-protos)",
+	"generated-from": "package is generated from the protobuf file (-protos)",
+	"generate":       "package runs the generator (-generate)",
+	"base-image":     "image is based on the image (-docker)",
+	"build-image":    "image copies from a build stage image (-docker)",
+	"copies":         "image contains the file (-docker)",
+	"copied-from":    "file is copied from the image (-docker)",
+	"built-from":     "file or image is built from the package (-docker)",
+	"routes-to":      "service routes to the binary's package (-k8s)",
+	"calls":          "package calls the service (-k8s)",
+	"skew":           "project requires an older version than another project of the workspace",
+	"requires":       "module requires the module (mvs)",
+	"alerts":         "security alert concerns the module (-verify)",
+}
+
+type schemaNodeType struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Count       int    `json:"count"`
+}
+
+type schemaEdgeKind struct {
+	Kind        string    `json:"kind"`
+	Description string    `json:"description,omitempty"`
+	Style       edgeStyle `json:"style"`
+	Count       int       `json:"count"`
+}
+
+// graphSchema is the response of /api/schema.
+type graphSchema struct {
+	NodeTypes []*schemaNodeType      `json:"nodeTypes"`
+	EdgeKinds []*schemaEdgeKind      `json:"edgeKinds"`
+	ColorBy   string                 `json:"colorBy"`
+	Legend    []depgraph.LegendEntry `json:"legend"`
+}
+
+// schemaHandler serves /api/schema: every node type and edge kind with what
+// it means, how it is drawn and how often the graph has it, and the legend of
+// the color dimension, so generic clients need not know go-raph's taxonomy.
+// The /api/graph parameters apply, e.g. ?colorBy=license.
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	graph, ok := requestedGraph(w, r)
+	if !ok {
+		return
+	}
+	theme := currentTheme()
+
+	types := make(map[string]*schemaNodeType)
+	for t, description := range nodeTypes {
+		types[t] = &schemaNodeType{Type: t, Description: description, Color: theme.NodeColors[t]}
+	}
+	for _, node := range graph.Nodes {
+		if types[node.Type] == nil { // e.g. added by a plugin
+			types[node.Type] = &schemaNodeType{Type: node.Type, Color: theme.NodeColors[node.Type]}
+		}
+		types[node.Type].Count++
+	}
+
+	kinds := make(map[string]*schemaEdgeKind)
+	style := func(kind string) edgeStyle {
+		if s, ok := theme.Edges[kind]; ok && kind != "" {
+			return s
+		}
+		return theme.Edges["default"]
+	}
+	for k, description := range edgeKinds {
+		kinds[k] = &schemaEdgeKind{Kind: k, Description: description, Style: style(k)}
+	}
+	for _, edge := range graph.Edges {
+		if kinds[edge.Kind] == nil {
+			kinds[edge.Kind] = &schemaEdgeKind{Kind: edge.Kind, Style: style(edge.Kind)}
+		}
+		kinds[edge.Kind].Count++
+	}
+
+	schema := graphSchema{ColorBy: graph.ColorBy, Legend: graph.Legend}
+	for _, t := range types {
+		schema.NodeTypes = append(schema.NodeTypes, t)
+	}
+	for _, k := range kinds {
+		schema.EdgeKinds = append(schema.EdgeKinds, k)
+	}
+	sort.Slice(schema.NodeTypes, func(i, j int) bool { return schema.NodeTypes[i].Type < schema.NodeTypes[j].Type })
+	sort.Slice(schema.EdgeKinds, func(i, j int) bool { return schema.EdgeKinds[i].Kind < schema.EdgeKinds[j].Kind })
+	if schema.Legend == nil {
+		schema.Legend = []depgraph.LegendEntry{}
+	}
+	respondJSON(w, schema)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+var trackScorecard bool // -scorecard
+
+// scorecardAPI serves OpenSSF Scorecard results for public repositories.
+var scorecardAPI = "https://api.securityscorecards.dev"
+
+func init() {
+	depgraph.Register(scorecardAnalyzer{})
+}
+
+// scorecardAnalyzer annotates external modules hosted on GitHub or GitLab
+// with their OpenSSF Scorecard score, from 0 to 10. It only runs with
+// -scorecard.
+type scorecardAnalyzer struct{}
+
+func (scorecardAnalyzer) Name() string { return "scorecard" }
+
+func (scorecardAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if !trackScorecard {
+		return nil
+	}
+	for path, score := range lookupModules(ctx, graph, scorecardScore) {
+		if score >= 0 {
+			graph.Annotate(path, "scorecard", fmt.Sprintf("%.1f", score))
+		}
+	}
+	return nil
+}
+
+// scorecards caches API answers for the life of the process.
+var scorecards sync.Map // repository -> float64
+
+// scorecardScore returns the score of the repository a module is hosted in,
+// or -1 when the forge is unsupported or the repository was never scored.
+func scorecardScore(ctx context.Context, path string) float64 {
+	repo, ok := moduleRepo(path)
+	if !ok {
+		return -1
+	}
+	if v, ok := scorecards.Load(repo); ok {
+		return v.(float64)
+	}
+	var result struct{ Score float64 }
+	if err := fetchJSON(ctx, scorecardAPI+"/projects/"+repo, &result); err != nil {
+		return -1
+	}
+	scorecards.Store(repo, result.Score)
+	return result.Score
+}
+
+// moduleRepo guesses the repository a module lives in, e.g. github.com/o/r
+// for github.com/o/r/v2/sub, following the vanity paths of golang.org/x and
+// gopkg.in.
+func moduleRepo(path string) (string, bool) {
+	parts := strings.Split(path, "/")
+	switch {
+	case (parts[0] == "github.com" || parts[0] == "gitlab.com") && len(parts) >= 3:
+		return strings.Join(parts[:3], "/"), true
+	case parts[0] == "golang.org" && len(parts) >= 3 && parts[1] == "x":
+		return "github.com/golang/" + parts[2], true
+	case parts[0] == "gopkg.in" && len(parts) >= 2:
+		// gopkg.in/yaml.v3 is github.com/go-yaml/yaml, gopkg.in/user/pkg.v1 github.com/user/pkg
+		name, _, _ := strings.Cut(parts[len(parts)-1], ".")
+		if len(parts) == 2 {
+			return "github.com/go-" + name + "/" + name, true
+		}
+		return "github.com/" + parts[1] + "/" + name, true
+	}
+	return "", false
+}
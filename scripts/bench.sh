@@ -0,0 +1,32 @@
+#!/bin/sh
+# Benchmarks the analyzer on large public repositories, checked out at pinned
+# tags so results stay comparable. Usage:
+#
+#   scripts/bench.sh                 # fetch the repos and benchmark
+#   scripts/bench.sh -count 6        # extra flags go to go test
+#   benchstat old.txt .bench/bench_output.txt
+#
+# Checkouts go to $GORAPH_BENCH_REPOS (default .bench), results to
+# .bench/bench_output.txt, both ignored by git.
+set -eu
+
+cd "$(dirname "$0")/.."
+repos=${GORAPH_BENCH_REPOS:-$PWD/.bench}
+out=$PWD/.bench/bench_output.txt
+mkdir -p "$repos" "$(dirname "$out")"
+
+fetch() {
+	name=$1 url=$2 tag=$3
+	if [ ! -d "$repos/$name" ]; then
+		echo "fetching $name $tag"
+		git clone --quiet --depth 1 --branch "$tag" "$url" "$repos/$name"
+	fi
+}
+
+fetch kubernetes https://github.com/kubernetes/kubernetes v1.31.0
+fetch prometheus https://github.com/prometheus/prometheus v2.54.0
+fetch hugo https://github.com/gohugoio/hugo v0.133.0
+fetch terraform https://github.com/hashicorp/terraform v1.9.5
+fetch cockroach https://github.com/cockroachdb/cockroach v24.2.0
+
+GORAPH_BENCH_REPOS=$repos go test -run '^$' -bench '^BenchmarkAnalyze$' -benchmem -benchtime 3x -timeout 2h "$@" ./depgraph | tee "$out"
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// releaseRepo is the GitHub repository self-update looks for releases in.
+const releaseRepo = "countermoe/go-raph"
+
+// maxBinarySize bounds downloads of release binaries.
+const maxBinarySize = 256 << 20
+
+// releasePublicKey is the minisign public key the release workflow signs
+// checksums.txt with, set by release builds with
+// -ldflags "-X main.releasePublicKey=RWQ...". Builds without it cannot tell
+// a genuine release from one published with a stolen token, so they do not
+// update themselves.
+var releasePublicKey = ""
+
+// githubRelease is the part of a GitHub release self-update reads.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// asset returns the download URL of the named release asset.
+func (r *githubRelease) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// selfUpdateCommand implements `go-raph self-update`, for installs outside
+// go install: it looks up the latest release on GitHub, downloads the
+// binary for this platform, checks it against the release's checksums.txt,
+// whose minisign signature must verify, and replaces the running
+// executable with it.
+func selfUpdateCommand(args []string) {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	repo := fs.String("repo", releaseRepo, "GitHub repository publishing the releases")
+	tag := fs.String("version", "", "Install this release tag instead of the latest")
+	check := fs.Bool("check", false, "Only report whether a newer release exists")
+	force := fs.Bool("force", false, "Install even when this binary is as new or newer")
+	addLangFlag(fs)
+	parseFlags(fs, args)
+
+	ctx := context.Background()
+	release, err := fetchRelease(ctx, *repo, *tag)
+	if err != nil {
+		fmt.Printf(tr("❌ Looking up the release failed: %v\n"), err)
+		os.Exit(1)
+	}
+	current := currentVersion()
+	if current == "" {
+		fmt.Printf(tr("ℹ️ This is a development build, the release is %s\n"), release.TagName)
+	} else if semver.Compare(current, release.TagName) >= 0 && !*force {
+		fmt.Printf(tr("✅ %s is up to date\n"), current)
+		return
+	} else {
+		fmt.Printf(tr("⬆️ %s is available, this is %s\n"), release.TagName, current)
+	}
+	if *check {
+		return
+	}
+
+	exe, err := installRelease(ctx, release)
+	if err != nil {
+		fmt.Printf(tr("❌ Update failed: %v\n"), err)
+		os.Exit(1)
+	}
+	fmt.Printf(tr("✅ Installed %s to %s\n"), release.TagName, exe)
+}
+
+// fetchRelease gets the latest release of repo, or the one tagged tag.
+func fetchRelease(ctx context.Context, repo, tag string) (*githubRelease, error) {
+	url := githubAPI + "/repos/" + repo + "/releases/latest"
+	if tag != "" {
+		url = githubAPI + "/repos/" + repo + "/releases/tags/" + tag
+	}
+	var release githubRelease
+	if err := githubJSON(ctx, url, &release); err != nil {
+		return nil, err
+	}
+	if !semver.IsValid(release.TagName) {
+		return nil, fmt.Errorf("release tag %q is not a semantic version", release.TagName)
+	}
+	return &release, nil
+}
+
+// releaseBinary is the asset name of the binary for this platform, as the
+// release workflow names them.
+func releaseBinary() string {
+	name := "go-raph_" + runtime.GOOS + "_" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// installRelease downloads and verifies the binary of a release and puts
+// it in place of the running executable, returning the executable's path.
+func installRelease(ctx context.Context, release *githubRelease) (string, error) {
+	if releasePublicKey == "" {
+		return "", errors.New("this binary was not built by the release workflow and has no key to verify releases with, update it with go install")
+	}
+	name := releaseBinary()
+	binaryURL, ok := release.asset(name)
+	if !ok {
+		return "", fmt.Errorf("release %s has no binary %s", release.TagName, name)
+	}
+	sumsURL, ok := release.asset("checksums.txt")
+	if !ok {
+		return "", fmt.Errorf("release %s has no checksums.txt to verify %s with", release.TagName, name)
+	}
+	sigURL, ok := release.asset("checksums.txt.minisig")
+	if !ok {
+		return "", fmt.Errorf("release %s has no signature of its checksums.txt", release.TagName)
+	}
+	sums, err := download(ctx, sumsURL, 1<<20)
+	if err != nil {
+		return "", err
+	}
+	sig, err := download(ctx, sigURL, 1<<10)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyMinisign(releasePublicKey, sums, sig); err != nil {
+		return "", fmt.Errorf("checksums.txt of %s: %v, not installing it", release.TagName, err)
+	}
+	want, ok := releaseChecksum(sums, name)
+	if !ok {
+		return "", fmt.Errorf("checksums.txt of %s lists no %s", release.TagName, name)
+	}
+	binary, err := download(ctx, binaryURL, maxBinarySize)
+	if err != nil {
+		return "", err
+	}
+	if sum := sha256.Sum256(binary); hex.EncodeToString(sum[:]) != want {
+		return "", fmt.Errorf("%s does not match its checksum, not installing it", name)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+	return exe, replaceExecutable(exe, binary)
+}
+
+// verifyMinisign checks a minisign signature of message against a base64
+// minisign public key. It takes the legacy format of `minisign -S -l`,
+// which signs the message itself rather than its BLAKE2b hash, as the
+// standard library has no BLAKE2b. The trusted comment is verified too.
+func verifyMinisign(publicKey string, message, signature []byte) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != 10+ed25519.PublicKeySize || string(key[:2]) != "Ed" {
+		return errors.New("invalid minisign public key")
+	}
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(string(signature), "\r\n", "\n")), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("malformed minisign signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 10+ed25519.SignatureSize {
+		return errors.New("malformed minisign signature")
+	}
+	if string(sig[:2]) != "Ed" {
+		return fmt.Errorf("unsupported minisign signature algorithm %q", sig[:2])
+	}
+	if !bytes.Equal(sig[2:10], key[2:10]) {
+		return errors.New("signed with another key")
+	}
+	if !ed25519.Verify(key[10:], message, sig[10:]) {
+		return errors.New("signature does not match")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	comment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if err != nil || !ed25519.Verify(key[10:], append(slices.Clone(sig[10:]), comment...), global) {
+		return errors.New("trusted comment signature does not match")
+	}
+	return nil
+}
+
+// releaseChecksum finds the SHA-256 of a file in sha256sum output.
+func releaseChecksum(sums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+// replaceExecutable writes binary next to exe and renames it over exe, so
+// the executable is never left half written. Windows cannot replace a
+// running executable, so the old one is moved aside first.
+func replaceExecutable(exe string, binary []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".go-raph-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), exe)
+}
+
+// download GETs a URL, failing when the body exceeds limit bytes. Binaries
+// take longer than httpClient allows API calls.
+func download(ctx context.Context, url string, limit int64) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("GET %s: larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
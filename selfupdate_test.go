@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReleaseChecksum(t *testing.T) {
+	sums := []byte("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  go-raph_linux_amd64\n" +
+		"9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08 *go-raph_windows_amd64.exe\n" +
+		"malformed line\n" +
+		"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae  go-raph_linux_amd64.sbom extra\n")
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"go-raph_linux_amd64", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", true},
+		{"go-raph_windows_amd64.exe", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", true},
+		{"go-raph_linux", "", false},
+		{"go-raph_linux_amd64.sbom", "", false},
+		{"go-raph_darwin_arm64", "", false},
+	}
+	for _, test := range tests {
+		got, ok := releaseChecksum(sums, test.name)
+		if got != test.want || ok != test.ok {
+			t.Errorf("releaseChecksum(%s) = %q, %v, want %q, %v", test.name, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+// minisign returns a minisign public key and signs messages like
+// `minisign -S -l`, with key ID id.
+func minisign(t *testing.T, id string) (string, func(alg string, message []byte, comment string) []byte) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(append([]byte("Ed"+id), public...))
+	return key, func(alg string, message []byte, comment string) []byte {
+		sig := ed25519.Sign(private, message)
+		global := ed25519.Sign(private, append(slices.Clone(sig), comment...))
+		return []byte("untrusted comment: signature from minisign secret key\n" +
+			base64.StdEncoding.EncodeToString(append([]byte(alg+id), sig...)) + "\n" +
+			"trusted comment: " + comment + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n")
+	}
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	key, sign := minisign(t, "goraph01")
+	otherKey, signOther := minisign(t, "goraph02")
+	sums := []byte("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  go-raph_linux_amd64\n")
+	forged := []byte("0000000000000000000000000000000000000000000000000000000000000000  go-raph_linux_amd64\n")
+	good := sign("Ed", sums, "go-raph v1.5.0")
+	lines := strings.Split(string(good), "\n")
+	tests := []struct {
+		name    string
+		key     string
+		message []byte
+		sig     []byte
+		err     string
+	}{
+		{"valid", key, sums, good, ""},
+		{"crlf", key, sums, []byte(strings.ReplaceAll(string(good), "\n", "\r\n")), ""},
+		{"tampered checksums", key, forged, good, "does not match"},
+		{"other key", key, sums, signOther("Ed", sums, "go-raph v1.5.0"), "another key"},
+		{"other key's public key", otherKey, sums, good, "another key"},
+		{"prehashed", key, sums, sign("ED", sums, "go-raph v1.5.0"), "unsupported"},
+		{"tampered comment", key, sums, []byte(strings.Join(append(lines[:2:2], "trusted comment: go-raph v9.9.9", lines[3]), "\n")), "trusted comment"},
+		{"truncated", key, sums, []byte(strings.Join(lines[:2], "\n")), "malformed"},
+		{"no key", "", sums, good, "invalid"},
+	}
+	for _, test := range tests {
+		err := verifyMinisign(test.key, test.message, test.sig)
+		if test.err == "" && err != nil || test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%s: verifyMinisign() = %v, want %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// openBrowser launches the default browser at the visualizer once the
+// server listens (-open), by default when go-raph runs in a terminal
+// rather than under a service manager or CI.
+var openBrowser = interactive()
+
+// interactive reports whether standard output is a terminal.
+func interactive() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// strictPort makes the server exit when its port is taken (-strict-port)
+// instead of serving on a free one.
+var strictPort bool
+
+// listenAddr is where the server listens instead of on -port (-listen): a
+// TCP address like localhost:8080, or unix:/path/to/socket for a reverse
+// proxy on the same host.
+var listenAddr string
+
+// basePath is the path every route is served under (-base-path), with a
+// leading and trailing slash, for reverse proxies forwarding a sub-path
+// like /raph/ without stripping it.
+var basePath = "/"
+
+// setBasePath normalizes a -base-path.
+func setBasePath(p string) error {
+	if strings.ContainsAny(p, "?#\"<>") {
+		return fmt.Errorf("invalid base path %q", p)
+	}
+	basePath = path.Clean("/"+p) + "/"
+	if basePath == "//" {
+		basePath = "/"
+	}
+	return nil
+}
+
+// listen binds -listen, or port on every interface, picking a free port
+// when port is in use. It returns the listener and the URL of the
+// visualizer, which for unix sockets is written the way nginx's proxy_pass
+// takes it.
+func listen(port string) (net.Listener, string) {
+	if socket, ok := strings.CutPrefix(listenAddr, "unix:"); ok {
+		removeStaleSocket(socket)
+		ln, err := net.Listen("unix", socket)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return ln, "unix:" + socket + ":" + basePath
+	}
+	if listenAddr != "" {
+		ln, err := net.Listen("tcp", listenAddr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		host, _, _ := net.SplitHostPort(listenAddr)
+		return ln, localURL(host, ln)
+	}
+
+	ln, err := net.Listen("tcp", ":"+port)
+	if err != nil && !strictPort && errors.Is(err, syscall.EADDRINUSE) {
+		if ln, err = net.Listen("tcp", ":0"); err == nil {
+			fmt.Printf(tr("⚠️ Port %s is in use, serving on %s instead\n"), port, strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ln, localURL("", ln)
+}
+
+// localURL is the URL of the visualizer on a TCP listener bound to host,
+// localhost when it is bound to every interface.
+func localURL(host string, ln net.Listener) string {
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	return "http://" + net.JoinHostPort(host, port) + basePath
+}
+
+// removeStaleSocket removes a unix socket left behind by a server that
+// did not shut down, which would make listening fail. A socket something
+// still accepts on is kept.
+func removeStaleSocket(socket string) {
+	info, err := os.Stat(socket)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return
+	}
+	os.Remove(socket)
+}
+
+// serve serves the default mux on ln under -base-path, opening the page at
+// url in the browser once the port is bound so the first request does not
+// race the listener.
+func serve(ln net.Listener, url string) {
+	if openBrowser && strings.HasPrefix(url, "http:") {
+		if err := launchBrowser(url); err != nil {
+			log.Printf(tr("⚠️ Opening the browser failed: %v"), err)
+		}
+	}
+	log.Fatal(http.Serve(ln, underBasePath(limitRequests(guardPprof(http.DefaultServeMux)))))
+}
+
+// underBasePath serves next under -base-path, stripping it from request
+// paths and redirecting the base path without its trailing slash.
+func underBasePath(next http.Handler) http.Handler {
+	if basePath == "/" {
+		return next
+	}
+	prefix := strings.TrimSuffix(basePath, "/")
+	strip := http.StripPrefix(prefix, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix {
+			http.Redirect(w, r, pageBase(r), http.StatusMovedPermanently)
+			return
+		}
+		strip.ServeHTTP(w, r)
+	})
+}
+
+// trustedProxies are the networks besides loopback whose X-Forwarded-*
+// headers are trusted (-trusted-proxy), such as a proxy container's.
+var trustedProxies []netip.Prefix
+
+// addTrustedProxy parses a -trusted-proxy address or CIDR.
+func addTrustedProxy(s string) error {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		trustedProxies = append(trustedProxies, netip.PrefixFrom(addr, addr.BitLen()))
+		return nil
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return fmt.Errorf("invalid address or CIDR %q", s)
+	}
+	trustedProxies = append(trustedProxies, prefix.Masked())
+	return nil
+}
+
+// fromProxy reports whether the X-Forwarded-* headers of a request are
+// trusted: it came over a unix socket, from a loopback address or from a
+// -trusted-proxy. Anyone else could forge them, like every client on a
+// shared network could.
+func fromProxy(r *http.Request) bool {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr == "" || r.RemoteAddr == "@" // unix socket peers
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() {
+		return true
+	}
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// localPeer reports whether a request was made on this machine: it came
+// over a unix socket or from a loopback address, and no proxy forwarded
+// it from elsewhere.
+func localPeer(r *http.Request) bool {
+	if r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("Forwarded") != "" {
+		return false
+	}
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr == "" || r.RemoteAddr == "@"
+	}
+	return addrPort.Addr().Unmap().IsLoopback()
+}
+
+// clientIP is the address of the client making a request: the last
+// X-Forwarded-For entry, which the proxy added, behind a trusted proxy.
+func clientIP(r *http.Request) string {
+	if fromProxy(r) {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(forwarded[strings.LastIndex(forwarded, ",")+1:])
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// pageBase is the path the browser reaches the base path at: behind a
+// proxy that strips a prefix, X-Forwarded-Prefix tells what it stripped.
+func pageBase(r *http.Request) string {
+	prefix := ""
+	if fromProxy(r) {
+		prefix = strings.TrimSuffix(r.Header.Get("X-Forwarded-Prefix"), "/")
+		if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, "?#\"<>") {
+			prefix = ""
+		}
+	}
+	return prefix + basePath
+}
+
+// externalURL is the URL the browser reaches a path below the base path
+// at, from the X-Forwarded-Proto and X-Forwarded-Host of a trusted proxy
+// or else the request itself.
+func externalURL(r *http.Request, rel string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if fromProxy(r) {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+	}
+	host := externalHost(r)
+	if host == "" {
+		host = "localhost"
+	}
+	return scheme + "://" + host + pageBase(r) + rel
+}
+
+// externalHost is the host the browser sent a request to: the
+// X-Forwarded-Host of a trusted proxy, or else the Host header.
+func externalHost(r *http.Request) string {
+	if fromProxy(r) {
+		if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	return r.Host
+}
+
+// sameOrigin reports whether a request comes from the visualizer's own
+// pages rather than from another site open in the same browser, which can
+// reach a server on localhost too. Browsers send Origin on WebSocket
+// handshakes and cross-origin POSTs; requests with neither it nor
+// Sec-Fetch-Site come from programs other than browsers.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		site := r.Header.Get("Sec-Fetch-Site")
+		return site == "" || site == "same-origin" || site == "none"
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, externalHost(r))
+}
+
+// visualizerHTML is the visualizer, built into the binary so go-raph
+// serves and publishes it from any working directory.
+//
+//go:embed index.html
+var visualizerHTML []byte
+
+// visualizerPage returns the visualizer with a <base> element pointing at
+// the base path, which its API and WebSocket URLs are relative to.
+func visualizerPage(r *http.Request) []byte {
+	base := `<head>
+    <base href="` + html.EscapeString(pageBase(r)) + `">`
+	return bytes.Replace(visualizerHTML, []byte("<head>"), []byte(base), 1)
+}
+
+// launchBrowser opens url with the platform's handler for URLs.
+func launchBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
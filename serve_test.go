@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromProxy(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   bool
+	}{
+		{"127.0.0.1:51234", true},
+		{"[::1]:51234", true},
+		{"", true},  // unix socket
+		{"@", true}, // abstract unix socket
+		{"[::ffff:127.0.0.1]:51234", true},
+		{"10.1.2.3:51234", true},      // -trusted-proxy 10.1.2.0/24
+		{"10.1.3.3:51234", false},     // private, but not trusted
+		{"192.168.1.20:51234", false}, // a client on a shared network
+		{"172.18.0.5:51234", true},    // -trusted-proxy 172.18.0.5
+		{"203.0.113.9:51234", false},
+		{"[2001:db8::1]:51234", false},
+		{"[fd00::7]:51234", true}, // -trusted-proxy fd00::/64
+		{"not an address", false},
+	}
+	saved := trustedProxies
+	t.Cleanup(func() { trustedProxies = saved })
+	trustedProxies = nil
+	for _, proxy := range []string{"10.1.2.0/24", "172.18.0.5", "fd00::1/64"} {
+		if err := addTrustedProxy(proxy); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := addTrustedProxy("10.0.0.0/33"); err == nil {
+		t.Error("invalid -trusted-proxy accepted")
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remote
+		if got := fromProxy(r); got != test.want {
+			t.Errorf("fromProxy(%q) = %v, want %v", test.remote, got, test.want)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"203.0.113.9:51234", "", "203.0.113.9"},
+		{"203.0.113.9:51234", "198.51.100.1", "203.0.113.9"}, // forged
+		{"127.0.0.1:51234", "198.51.100.1", "198.51.100.1"},
+		{"127.0.0.1:51234", "192.0.2.7, 198.51.100.1", "198.51.100.1"},
+		{"127.0.0.1:51234", "", "127.0.0.1"},
+		{"192.168.1.20:51234", "198.51.100.1", "192.168.1.20"}, // untrusted private peer
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = test.remote
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+		if got := clientIP(r); got != test.want {
+			t.Errorf("clientIP(%s, X-Forwarded-For %q) = %s, want %s", test.remote, test.forwarded, got, test.want)
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		origin, fetchSite string
+		want              bool
+	}{
+		{"", "", true}, // not a browser
+		{"http://localhost:8080", "", true},
+		{"http://LOCALHOST:8080", "", true},
+		{"http://localhost:9090", "", false},
+		{"https://evil.example", "", false},
+		{"null", "", false},
+		{"", "same-origin", true},
+		{"", "none", true},
+		{"", "cross-site", false},
+		{"", "same-site", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "http://localhost:8080/api/open", nil)
+		r.RemoteAddr = "127.0.0.1:51234"
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		if test.fetchSite != "" {
+			r.Header.Set("Sec-Fetch-Site", test.fetchSite)
+		}
+		if got := sameOrigin(r); got != test.want {
+			t.Errorf("sameOrigin(Origin %q, Sec-Fetch-Site %q) = %v, want %v", test.origin, test.fetchSite, got, test.want)
+		}
+	}
+}
+
+func TestLocalPeer(t *testing.T) {
+	tests := []struct {
+		remote    string
+		forwarded string
+		want      bool
+	}{
+		{"127.0.0.1:51234", "", true},
+		{"[::1]:51234", "", true},
+		{"", "", true},
+		{"127.0.0.1:51234", "203.0.113.9", false}, // through a local proxy
+		{"", "203.0.113.9", false},
+		{"10.1.2.3:51234", "", false},
+		{"203.0.113.9:51234", "", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/api/open", nil)
+		r.RemoteAddr = test.remote
+		if test.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", test.forwarded)
+		}
+		if got := localPeer(r); got != test.want {
+			t.Errorf("localPeer(%q, X-Forwarded-For %q) = %v, want %v", test.remote, test.forwarded, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-raph/depgraph"
+)
+
+// shareClaims is what a share token grants: read-only access to one
+// project's graph until it expires.
+type shareClaims struct {
+	Project string `json:"p"`
+	Expires int64  `json:"exp"` // Unix seconds
+}
+
+var errShareExpired = errors.New("share link expired")
+
+// shareOnly serves nothing but share links (-share-only), for a server
+// stakeholders reach: the server has no authentication, so the full
+// visualizer and its API stay on a server only the team can reach, where
+// links are minted with `go-raph share` or POST /api/share.
+var shareOnly bool
+
+// shareKey returns the secret share tokens are signed with, created on
+// first use. Deleting the file revokes every link handed out.
+func shareKey() ([]byte, error) {
+	name := filepath.Join(stateDir(), "share.key")
+	if data, err := os.ReadFile(name); err == nil {
+		return hex.DecodeString(strings.TrimSpace(string(data)))
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(stateDir(), 0o755); err != nil {
+		return nil, err
+	}
+	return key, os.WriteFile(name, []byte(hex.EncodeToString(key)+"\n"), 0o600)
+}
+
+// signShare returns a token for the project valid until expires, as
+// base64url claims, a dot and their HMAC-SHA256.
+func signShare(project string, expires time.Time) (string, error) {
+	key, err := shareKey()
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(shareClaims{Project: project, Expires: expires.Unix()})
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verifyShare checks a token's signature and expiry and returns its claims.
+func verifyShare(token string) (shareClaims, error) {
+	var claims shareClaims
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, errors.New("malformed share token")
+	}
+	key, err := shareKey()
+	if err != nil {
+		return claims, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+		return claims, errors.New("invalid share token")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(data, &claims) != nil {
+		return claims, errors.New("malformed share token")
+	}
+	if time.Now().Unix() > claims.Expires {
+		return claims, errShareExpired
+	}
+	return claims, nil
+}
+
+// shareCommand implements `go-raph share`, printing a read-only link to the
+// project's graph on a running server.
+func shareCommand(args []string) {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path of the project the server analyzes")
+	expires := fs.Duration("expires", 7*24*time.Hour, "How long the link stays valid")
+	base := fs.String("url", "http://localhost:8080", "URL stakeholders reach the server at")
+	addLangFlag(fs)
+	parseFlags(fs, args)
+	resolveTarget(fs)
+
+	until := time.Now().Add(*expires)
+	token, err := signShare(projectKey(), until)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("🔗 %s/shared/%s\n", strings.TrimSuffix(*base, "/"), token)
+	fmt.Printf(tr("   read-only, expires %s\n"), until.Format(time.RFC1123))
+}
+
+// shareLink is a share link minted by POST /api/share.
+type shareLink struct {
+	URL     string    `json:"url"` // as the browser reaches the server, behind a proxy too
+	Expires time.Time `json:"expires"`
+}
+
+// shareHandler serves POST /api/share?expires=24h, minting a share link to
+// the served project.
+func shareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	expires := 7 * 24 * time.Hour
+	if s := r.URL.Query().Get("expires"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid expires", http.StatusBadRequest)
+			return
+		}
+		expires = d
+	}
+	until := time.Now().Add(expires)
+	token, err := signShare(projectKey(), until)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, shareLink{URL: externalURL(r, "shared/"+token), Expires: until})
+}
+
+// sharedHandler serves /shared/{token}: the visualizer with the graph
+// inlined, so the page needs no other endpoint and cannot change anything.
+func sharedHandler(w http.ResponseWriter, r *http.Request) {
+	claims, err := verifyShare(r.PathValue("token"))
+	switch {
+	case errors.Is(err, errShareExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case claims.Project != projectKey():
+		http.Error(w, "the link is for another project", http.StatusNotFound)
+		return
+	}
+
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page, err := inlineGraph(visualizerPage(r), graph)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(page)
+}
+
+// inlineGraph embeds a graph and the theme in the visualizer page, which
+// then shows it instead of connecting to a server.
+func inlineGraph(page []byte, graph *depgraph.Graph) ([]byte, error) {
+	data, err := json.Marshal(graph)
+	if err != nil {
+		return nil, err
+	}
+	style, err := json.Marshal(currentTheme())
+	if err != nil {
+		return nil, err
+	}
+	script := "<script>window.goraphGraph = " + string(data) + ";\nwindow.goraphTheme = " + string(style) + ";</script>\n</head>"
+	return bytes.Replace(page, []byte("</head>"), []byte(script), 1), nil
+}
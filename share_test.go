@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyShare(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	valid, err := signShare("/src/app", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := signShare("/src/app", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, sig, _ := strings.Cut(valid, ".")
+	other, _ := signShare("/src/other", time.Now().Add(time.Hour))
+	otherPayload, _, _ := strings.Cut(other, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		project string
+		err     string
+	}{
+		{"valid", valid, "/src/app", ""},
+		{"expired", expired, "", "share link expired"},
+		{"no signature", payload, "", "malformed share token"},
+		{"empty signature", payload + ".", "", "invalid share token"},
+		{"signature of other claims", otherPayload + "." + sig, "", "invalid share token"},
+		{"tampered signature", payload + "." + strings.Repeat("A", len(sig)), "", "invalid share token"},
+		{"not base64", payload + ".!!!", "", "invalid share token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := verifyShare(test.token)
+			if test.err == "" {
+				if err != nil || claims.Project != test.project {
+					t.Errorf("verifyShare = %+v, %v, want project %s", claims, err, test.project)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.err {
+				t.Errorf("verifyShare error = %v, want %s", err, test.err)
+			}
+		})
+	}
+	if _, err := verifyShare(expired); !errors.Is(err, errShareExpired) {
+		t.Errorf("expired token: %v, want errShareExpired", err)
+	}
+}
+
+func TestVerifyShareNewKey(t *testing.T) {
+	// Deleting share.key revokes the links signed with it
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	token, err := signShare("/src/app", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if _, err := verifyShare(token); err == nil {
+		t.Error("token verified with another key")
+	}
+}
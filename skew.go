@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"go-raph/depgraph"
+)
+
+// workspaceMode analyzes the modules of the target's go.work file and
+// merges their graphs (-workspace). It is opt-in, as merged graphs name
+// packages after their module and so change the node IDs layouts, owners
+// and share links refer to.
+var workspaceMode bool
+
+// workspaceModules returns the module directories a go.work file in dir
+// uses, or nil when there is none.
+func workspaceModules(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.work"))
+	if err != nil {
+		return nil
+	}
+	work, err := modfile.ParseWork("go.work", data, nil)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, use := range work.Use {
+		dirs = append(dirs, filepath.Join(dir, filepath.FromSlash(use.Path)))
+	}
+	return dirs
+}
+
+// analyzeWorkspace analyzes every module of a workspace and merges them,
+// like `go-raph merge` does for separate graphs.
+func analyzeWorkspace(dirs []string) (*depgraph.Graph, error) {
+	var graphs []*depgraph.Graph
+	for _, dir := range dirs {
+		graph, err := depgraph.Analyze(os.DirFS(dir), analyzeOptions())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		graphs = append(graphs, graph)
+	}
+	return mergeGraphs(graphs), nil
+}
+
+// skewedModule is a dependency the modules of a workspace or org require at
+// different versions.
+type skewedModule struct {
+	Module   string            `json:"module"`
+	Align    string            `json:"align"`    // highest version, which a workspace selects
+	Versions map[string]string `json:"versions"` // required version by requiring module
+}
+
+// skewedModules lists the dependencies of a merged graph with version skew,
+// from their "versions" annotations.
+func skewedModules(graph *depgraph.Graph) []skewedModule {
+	skewed := []skewedModule{}
+	for _, node := range graph.Nodes {
+		if node.Annotations["skew"] == "" {
+			continue
+		}
+		m := skewedModule{Module: node.ID, Align: node.Version, Versions: make(map[string]string)}
+		for _, entry := range strings.Split(node.Annotations["versions"], ", ") {
+			if version, project, ok := strings.Cut(entry, " ("); ok {
+				m.Versions[strings.TrimSuffix(project, ")")] = version
+			}
+		}
+		skewed = append(skewed, m)
+	}
+	return skewed
+}
+
+// writeSkewMarkdown writes the version skew report.
+func writeSkewMarkdown(w io.Writer, graph *depgraph.Graph) error {
+	skewed := skewedModules(graph)
+	fmt.Fprintf(w, "## go-raph version skew\n\n")
+	if len(skewed) == 0 {
+		fmt.Fprintf(w, "Every module requires the same versions of its dependencies.\n")
+		return nil
+	}
+	fmt.Fprintf(w, "Dependencies required at different versions; align them on the highest, which a workspace build selects anyway.\n\n")
+	fmt.Fprintf(w, "| module | align on | behind |\n| --- | --- | --- |\n")
+	for _, m := range skewed {
+		var behind []string
+		for _, project := range sortedSet(m.Versions) {
+			if m.Versions[project] != m.Align {
+				behind = append(behind, fmt.Sprintf("`%s` %s", project, m.Versions[project]))
+			}
+		}
+		fmt.Fprintf(w, "| `%s` | %s | %s |\n", m.Module, m.Align, strings.Join(behind, ", "))
+	}
+	return nil
+}
+
+// writeSkewJSON writes the version skew report as JSON.
+func writeSkewJSON(w io.Writer, graph *depgraph.Graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(skewedModules(graph))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWorkspaceModules(t *testing.T) {
+	tests := []struct {
+		name   string
+		goWork string // "" for none
+		want   []string
+	}{
+		{"no go.work", "", nil},
+		{"use block", "go 1.24\n\nuse (\n\t./api\n\t./worker\n)\n", []string{"api", "worker"}},
+		{"single use", "go 1.24\nuse .\n", []string{"."}},
+		{"nested", "go 1.24\nuse ./services/billing\n", []string{"services/billing"}},
+		{"malformed", "go 1.24\nuse (\n", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if test.goWork != "" {
+				writeFile(t, filepath.Join(dir, "go.work"), test.goWork)
+			}
+			var want []string
+			for _, rel := range test.want {
+				want = append(want, filepath.Join(dir, filepath.FromSlash(rel)))
+			}
+			if got := workspaceModules(dir); !reflect.DeepEqual(got, want) {
+				t.Errorf("workspaceModules = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+// snapshot is the latest analysis in watch mode, shared by all connections
+// and requests. The graph is replaced, never modified, so readers can keep
+// using a graph they already got.
+var snapshot struct {
+	sync.RWMutex
+	graph *depgraph.Graph
+}
+
+func setSnapshot(graph *depgraph.Graph) {
+	snapshot.Lock()
+	defer snapshot.Unlock()
+	snapshot.graph = graph
+}
+
+func getSnapshot() *depgraph.Graph {
+	snapshot.RLock()
+	defer snapshot.RUnlock()
+	return snapshot.graph
+}
+
+// repinSnapshot reapplies the saved layout to the snapshot after users moved
+// or reset pinned nodes, without re-analyzing the project.
+func repinSnapshot(ctx context.Context) {
+	snapshot.Lock()
+	defer snapshot.Unlock()
+	if snapshot.graph == nil {
+		return
+	}
+	graph := snapshot.graph.Clone()
+	for i := range graph.Nodes {
+		graph.Nodes[i].X, graph.Nodes[i].Y, graph.Nodes[i].Pinned = 0, 0, false
+	}
+	layoutAnalyzer{}.Enrich(ctx, graph)
+	snapshot.graph = graph
+}
+
+// reannotateSnapshot applies annotation updates of one node to the snapshot,
+// an empty value removing the key, and returns the new snapshot, or nil
+// without one.
+func reannotateSnapshot(id string, updates map[string]string) *depgraph.Graph {
+	snapshot.Lock()
+	defer snapshot.Unlock()
+	if snapshot.graph == nil {
+		return nil
+	}
+	graph := snapshot.graph.Clone()
+	for key, value := range updates {
+		if value != "" {
+			graph.Annotate(id, key, value)
+		} else if node := graph.Node(id); node != nil {
+			delete(node.Annotations, key)
+		}
+	}
+	snapshot.graph = graph
+	return graph
+}
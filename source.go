@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxSourceFile bounds the files /api/source returns.
+const maxSourceFile = 1 << 20
+
+// moduleSource is a directory or file of an external module in the module
+// cache: the entries of a directory, or the content of a text file.
+type moduleSource struct {
+	Module  string        `json:"module"`
+	Version string        `json:"version"`
+	Path    string        `json:"path"` // slash-separated, relative to the module root
+	Entries []sourceEntry `json:"entries,omitempty"`
+	Content string        `json:"content,omitempty"`
+	Size    int64         `json:"size,omitempty"`
+	Binary  bool          `json:"binary,omitempty"` // the file is not UTF-8, so its content is left out
+}
+
+type sourceEntry struct {
+	Name string `json:"name"`
+	Dir  bool   `json:"dir,omitempty"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// sourceHandler serves a directory listing or file (?path=, the module root
+// by default) of an external module of the graph (?module=) at the version
+// the graph uses, read-only from the module cache. Modules that have not
+// been downloaded are not found.
+func sourceHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	graph, err := currentGraph(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	node := graph.Node(q.Get("module"))
+	if node == nil || !isExternal(node) || node.Version == "" {
+		http.Error(w, "module not in the graph", http.StatusNotFound)
+		return
+	}
+	dir, ok := moduleDir(node.ID, node.Version)
+	if !ok {
+		http.Error(w, "module not in the module cache", http.StatusNotFound)
+		return
+	}
+
+	// os.Root keeps ../ and symlinks from leaving the module directory
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer root.Close()
+	name := path.Clean("/" + q.Get("path"))[1:]
+	if name == "" {
+		name = "."
+	}
+	f, err := root.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	src := moduleSource{Module: node.ID, Version: node.Version, Path: name}
+	if info.IsDir() {
+		entries, err := f.ReadDir(-1)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Directories first, as file browsers list them
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].IsDir() != entries[j].IsDir() {
+				return entries[i].IsDir()
+			}
+			return entries[i].Name() < entries[j].Name()
+		})
+		src.Entries = []sourceEntry{}
+		for _, e := range entries {
+			entry := sourceEntry{Name: e.Name(), Dir: e.IsDir()}
+			if info, err := e.Info(); err == nil && !e.IsDir() {
+				entry.Size = info.Size()
+			}
+			src.Entries = append(src.Entries, entry)
+		}
+		respondJSON(w, src)
+		return
+	}
+
+	if !info.Mode().IsRegular() {
+		http.Error(w, "not a regular file", http.StatusBadRequest)
+		return
+	}
+	if info.Size() > maxSourceFile {
+		http.Error(w, "file too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxSourceFile))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	src.Size = int64(len(data))
+	if utf8.Valid(data) {
+		src.Content = string(data)
+	} else {
+		src.Binary = true
+	}
+	respondJSON(w, src)
+}
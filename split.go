@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// minSplitCohesion is the share of a cluster's import edges that must stay
+// inside it for the cluster to be suggested as a separate module.
+const minSplitCohesion = 0.5
+
+// splitCandidate is a package cluster that could become its own module.
+type splitCandidate struct {
+	Cluster   int      `json:"cluster"`
+	Module    string   `json:"module"` // suggested module path
+	Packages  []string `json:"packages"`
+	Internal  int      `json:"internal"`  // imports between its packages
+	Afferent  int      `json:"afferent"`  // imports of it from other packages
+	Efferent  int      `json:"efferent"`  // imports of other packages from it
+	Cohesion  float64  `json:"cohesion"`  // internal share of its package imports
+	Requires  []string `json:"requires"`  // external modules it imports
+	Importers []string `json:"importers"` // outside packages that would import the new module
+}
+
+// splitCandidates groups packages into communities and suggests those with
+// at least two packages, high internal cohesion and low coupling to the rest
+// as candidate modules, most cohesive first. The community holding the
+// module root package never is a candidate.
+func splitCandidates(graph *depgraph.Graph) []splitCandidate {
+	communities := graph.Communities()
+	byCluster := make(map[int]*splitCandidate)
+	for id, c := range communities {
+		if byCluster[c] == nil {
+			byCluster[c] = &splitCandidate{Cluster: c}
+		}
+		byCluster[c].Packages = append(byCluster[c].Packages, strings.TrimPrefix(id, "pkg:"))
+	}
+
+	importers := make(map[int]map[string]bool)
+	for _, edge := range graph.Edges {
+		from, ok1 := communities[edge.Source]
+		to, ok2 := communities[edge.Target]
+		if edge.Kind != "" || !ok1 || !ok2 || edge.Source == edge.Target {
+			continue
+		}
+		if from == to {
+			byCluster[from].Internal++
+			continue
+		}
+		byCluster[from].Efferent++
+		byCluster[to].Afferent++
+		if importers[to] == nil {
+			importers[to] = make(map[string]bool)
+		}
+		importers[to][strings.TrimPrefix(edge.Source, "pkg:")] = true
+	}
+
+	requires := make(map[int]map[string]bool)
+	moduleUsers, _ := moduleImporters(graph)
+	for module, pkgs := range moduleUsers {
+		if strings.HasPrefix(module, "pkg:") {
+			continue // internal imports are counted above
+		}
+		for _, pkg := range pkgs {
+			c := communities["pkg:"+pkg]
+			if requires[c] == nil {
+				requires[c] = make(map[string]bool)
+			}
+			requires[c][module] = true
+		}
+	}
+
+	mainModule := mainModulePath(graph)
+	root, hasRoot := communities["pkg:root"]
+	var candidates []splitCandidate
+	for c, cand := range byCluster {
+		if len(cand.Packages) < 2 || hasRoot && c == root {
+			continue
+		}
+		coupling := cand.Afferent + cand.Efferent
+		cand.Cohesion = float64(cand.Internal) / float64(cand.Internal+coupling)
+		if cand.Cohesion < minSplitCohesion {
+			continue
+		}
+		sort.Strings(cand.Packages)
+		cand.Module = path.Join(mainModule, commonDir(cand.Packages))
+		cand.Requires = sortedSet(requires[c])
+		cand.Importers = sortedSet(importers[c])
+		candidates = append(candidates, *cand)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Cohesion != candidates[j].Cohesion {
+			return candidates[i].Cohesion > candidates[j].Cohesion
+		}
+		return candidates[i].Cluster < candidates[j].Cluster
+	})
+	return candidates
+}
+
+// commonDir returns the longest directory shared by all package paths.
+func commonDir(pkgs []string) string {
+	common := strings.Split(pkgs[0], "/")
+	for _, pkg := range pkgs[1:] {
+		parts := strings.Split(pkg, "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	return strings.Join(common, "/")
+}
+
+// sortedSet returns the keys of a map, sorted and never nil.
+func sortedSet[V any](set map[string]V) []string {
+	list := []string{}
+	for k := range set {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// writeSplitMarkdown writes the module split suggestions as a report.
+func writeSplitMarkdown(w io.Writer, graph *depgraph.Graph) error {
+	candidates := splitCandidates(graph)
+	fmt.Fprintf(w, "## go-raph module split suggestions\n\n")
+	if len(candidates) == 0 {
+		fmt.Fprintf(w, "No package cluster is cohesive enough to split out (at least %.0f%% of its imports internal).\n", minSplitCohesion*100)
+		return nil
+	}
+	fmt.Fprintf(w, "Clusters of packages that mostly import each other, most cohesive first.\n\n")
+	for _, c := range candidates {
+		fmt.Fprintf(w, "### `%s`\n\n", c.Module)
+		fmt.Fprintf(w, "cluster %d · %d packages · cohesion %.0f%% · %d internal, %d incoming, %d outgoing imports\n\n",
+			c.Cluster, len(c.Packages), c.Cohesion*100, c.Internal, c.Afferent, c.Efferent)
+		fmt.Fprintf(w, "- packages: %s\n", codeList(c.Packages))
+		fmt.Fprintf(w, "- would require: %s\n", codeList(c.Requires))
+		fmt.Fprintf(w, "- imported from outside by: %s\n\n", codeList(c.Importers))
+	}
+	return nil
+}
+
+func codeList(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return "`" + strings.Join(items, "`, `") + "`"
+}
+
+// writeSplitJSON writes the packages of the suggested modules and the
+// imports between them, annotated with the suggested module and its
+// cohesion.
+func writeSplitJSON(w io.Writer, graph *depgraph.Graph) error {
+	kept := make(map[string]bool)
+	sub := &depgraph.Graph{Nodes: []depgraph.Node{}, Edges: []depgraph.Edge{}}
+	for _, c := range splitCandidates(graph) {
+		for _, pkg := range c.Packages {
+			node := *graph.Node("pkg:" + pkg)
+			sub.Nodes = append(sub.Nodes, node)
+			sub.Annotate(node.ID, "split", c.Module)
+			sub.Annotate(node.ID, "cohesion", fmt.Sprintf("%.2f", c.Cohesion))
+			kept[node.ID] = true
+		}
+	}
+	for _, edge := range graph.Edges {
+		if kept[edge.Source] && kept[edge.Target] {
+			sub.Edges = append(sub.Edges, edge)
+		}
+	}
+	sub.Sort()
+	return writeJSON(w, sub)
+}
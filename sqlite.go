@@ -0,0 +1,154 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"go-raph/depgraph"
+)
+
+// sqliteSchema is the relational form of a graph written by -format sqlite.
+const sqliteSchema = `
+CREATE TABLE metadata (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE nodes (
+	id      TEXT PRIMARY KEY,
+	label   TEXT NOT NULL,
+	type    TEXT NOT NULL,
+	depth   INTEGER NOT NULL,
+	version TEXT,
+	license TEXT,
+	size    INTEGER,
+	color   TEXT
+);
+CREATE TABLE annotations (
+	node_id TEXT NOT NULL REFERENCES nodes(id),
+	key     TEXT NOT NULL,
+	value   TEXT NOT NULL,
+	PRIMARY KEY (node_id, key)
+);
+CREATE TABLE edges (
+	id     INTEGER PRIMARY KEY,
+	source TEXT NOT NULL REFERENCES nodes(id),
+	target TEXT NOT NULL REFERENCES nodes(id),
+	kind   TEXT NOT NULL -- empty for imports
+);
+CREATE TABLE edge_symbols (
+	edge_id INTEGER NOT NULL REFERENCES edges(id),
+	symbol  TEXT NOT NULL
+);
+CREATE TABLE metrics (
+	node_id TEXT PRIMARY KEY REFERENCES nodes(id),
+	fan_in  INTEGER NOT NULL,
+	fan_out INTEGER NOT NULL
+);
+-- Packages importing each node, imports of external packages counting for their module
+CREATE TABLE module_importers (
+	module  TEXT NOT NULL REFERENCES nodes(id),
+	package TEXT NOT NULL,
+	PRIMARY KEY (module, package)
+);
+CREATE INDEX nodes_type ON nodes(type);
+CREATE INDEX annotations_key ON annotations(key, value);
+CREATE INDEX edges_source ON edges(source, kind);
+CREATE INDEX edges_target ON edges(target, kind);
+CREATE INDEX edge_symbols_edge ON edge_symbols(edge_id);
+CREATE INDEX module_importers_package ON module_importers(package);
+`
+
+// writeSQLite writes the graph to a new SQLite database, replacing the file.
+func writeSQLite(path string, graph *depgraph.Graph) error {
+	if path == "" {
+		path = "graph.db"
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	exec := func(query string, args ...interface{}) {
+		if err == nil {
+			_, err = tx.Exec(query, args...)
+		}
+	}
+	null := func(s string) interface{} {
+		if s == "" {
+			return nil
+		}
+		return s
+	}
+
+	for _, n := range graph.Nodes {
+		var size interface{}
+		if n.Size > 0 {
+			size = n.Size
+		}
+		exec(`INSERT INTO nodes VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			n.ID, n.Label, n.Type, n.Depth, null(n.Version), null(n.License), size, null(n.Color))
+		for key, value := range n.Annotations {
+			exec(`INSERT INTO annotations VALUES (?, ?, ?)`, n.ID, key, value)
+		}
+	}
+	for i, e := range graph.Edges {
+		exec(`INSERT INTO edges VALUES (?, ?, ?, ?)`, i+1, e.Source, e.Target, e.Kind)
+		for _, symbol := range e.Symbols {
+			exec(`INSERT INTO edge_symbols VALUES (?, ?)`, i+1, symbol)
+		}
+	}
+
+	m := computeMetrics(graph)
+	for _, n := range m.Nodes {
+		exec(`INSERT INTO metrics VALUES (?, ?, ?)`, n.ID, n.FanIn, n.FanOut)
+	}
+	importers, _ := moduleImporters(graph)
+	for module, pkgs := range importers {
+		for _, pkg := range pkgs {
+			exec(`INSERT INTO module_importers VALUES (?, ?)`, module, pkg)
+		}
+	}
+
+	metadata := map[string]string{
+		"project":     graphProject(graph),
+		"analyzed_at": time.Now().UTC().Format(time.RFC3339),
+		"color_by":    graph.ColorBy,
+		"packages":    strconv.Itoa(m.Packages),
+		"modules":     strconv.Itoa(m.Modules),
+		"imports":     strconv.Itoa(m.Imports),
+		"direct_deps": strconv.Itoa(m.DirectDeps),
+		"edges":       strconv.Itoa(m.Edges),
+	}
+	if p := graph.Provenance; p != nil {
+		metadata["analyzed_at"] = p.AnalyzedAt.Format(time.RFC3339)
+		metadata["tool"] = p.Tool
+		metadata["flags"] = strings.Join(p.Flags, " ")
+		metadata["module_version"] = p.ModuleVersion
+	}
+	for key, value := range metadata {
+		exec(`INSERT INTO metadata VALUES (?, ?)`, key, value)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
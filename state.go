@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// configDir holds the user's config.yaml: $XDG_CONFIG_HOME/go-raph when it
+// is set, on every platform, and otherwise the platform's configuration
+// directory, ~/.config on Linux, ~/Library/Application Support on macOS
+// and %AppData% on Windows. Until the XDG directory exists, the platform's
+// one stays in use when it does, as the state of macOS and Windows users
+// was kept there before XDG_CONFIG_HOME was honored.
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	platform := filepath.Join(dir, "go-raph")
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		xdg := filepath.Join(dir, "go-raph")
+		if _, err := os.Stat(xdg); err != nil && isDir(platform) {
+			return platform
+		}
+		return xdg
+	}
+	return platform
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// stateDir holds go-raph's persisted server-side state such as layouts
+// and saved views, next to the configuration.
+func stateDir() string {
+	return configDir()
+}
+
+// cacheDir holds clones and analyses that can be recreated at any time:
+// the cache-dir of config.yaml, else $XDG_CACHE_HOME/go-raph when it is
+// set, else the platform's cache directory.
+func cacheDir() string {
+	if cfg, _ := loadUserConfig(); cfg.CacheDir != "" {
+		return expandHome(cfg.CacheDir)
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "go-raph")
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "go-raph")
+}
+
+// readState decodes a state file into v. A missing file leaves v untouched.
+func readState(name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(stateDir(), name))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeState encodes v into a state file, going through a temp file so a
+// crash never leaves a truncated file behind.
+func writeState(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(stateDir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// projectKey identifies the analyzed project across runs.
+func projectKey() string {
+	if abs, err := filepath.Abs(targetPath); err == nil {
+		return abs
+	}
+	return targetPath
+}
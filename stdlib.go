@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"go/version"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"go-raph/depgraph"
+)
+
+// stdlibReplacement is standard library functionality that took over from
+// a module or package.
+type stdlibReplacement struct {
+	since string // Go version, e.g. go1.21
+	by    string
+}
+
+// stdlibReplacements lists modules and packages the standard library made
+// unnecessary, by module or package path.
+var stdlibReplacements = map[string]stdlibReplacement{
+	"github.com/kardianos/osext":          {"go1.8", "os.Executable"},
+	"github.com/mitchellh/go-homedir":     {"go1.12", "os.UserHomeDir"},
+	"github.com/pkg/errors":               {"go1.13", "errors and fmt.Errorf with %w"},
+	"golang.org/x/xerrors":                {"go1.13", "errors and fmt.Errorf with %w"},
+	"golang.org/x/crypto/ed25519":         {"go1.13", "crypto/ed25519"},
+	"golang.org/x/net/context":            {"go1.7", "context"},
+	"go.uber.org/atomic":                  {"go1.19", "sync/atomic types"},
+	"golang.org/x/sys/execabs":            {"go1.19", "os/exec"},
+	"github.com/hashicorp/go-multierror":  {"go1.20", "errors.Join"},
+	"go.uber.org/multierr":                {"go1.20", "errors.Join"},
+	"golang.org/x/exp/slices":             {"go1.21", "slices"},
+	"golang.org/x/exp/maps":               {"go1.21", "maps"},
+	"golang.org/x/exp/slog":               {"go1.21", "log/slog"},
+	"golang.org/x/exp/constraints":        {"go1.21", "cmp.Ordered"},
+	"golang.org/x/exp/rand":               {"go1.22", "math/rand/v2"},
+	"github.com/gorilla/mux":              {"go1.22", "net/http.ServeMux method and wildcard patterns"},
+	"github.com/julienschmidt/httprouter": {"go1.22", "net/http.ServeMux method and wildcard patterns"},
+	"golang.org/x/crypto/sha3":            {"go1.24", "crypto/sha3"},
+	"golang.org/x/crypto/hkdf":            {"go1.24", "crypto/hkdf"},
+	"golang.org/x/crypto/pbkdf2":          {"go1.24", "crypto/pbkdf2"},
+}
+
+func init() {
+	depgraph.Register(stdlibAnalyzer{})
+}
+
+// stdlibAnalyzer annotates dependencies the standard library replaces with
+// "replaceable-by", the standard library functionality to use instead. When
+// go.mod declares an older Go version, the version needed is added.
+type stdlibAnalyzer struct{}
+
+func (stdlibAnalyzer) Name() string { return "stdlib" }
+
+func (stdlibAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	goVersion := moduleGoVersion(targetPath)
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if !isExternal(node) {
+			continue
+		}
+		r, ok := stdlibReplacements[strings.TrimPrefix(node.ID, "import:")]
+		if !ok {
+			continue
+		}
+		hint := r.by
+		if goVersion == "" || version.Compare(goVersion, r.since) < 0 {
+			hint += " from " + strings.Replace(r.since, "go", "go ", 1)
+		}
+		graph.Annotate(node.ID, "replaceable-by", hint)
+	}
+	return nil
+}
+
+// moduleGoVersion returns the go directive of the go.mod in dir as a Go
+// version such as go1.21, or "" when there is none.
+func moduleGoVersion(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil || f.Go == nil {
+		return ""
+	}
+	return "go" + f.Go.Version
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(sumdbAnalyzer{})
+}
+
+// sumdbName is the checksum database the go command verifies downloads
+// against, as named by GOSUMDB, or "" when verification is off.
+var sumdbName = sync.OnceValue(func() string {
+	db := goEnv("GOSUMDB")
+	if db == "" {
+		db = "sum.golang.org"
+	}
+	if db == "off" {
+		return ""
+	}
+	name, _, _ := strings.Cut(strings.Fields(db)[0], "+")
+	return name
+})
+
+// sumdbExclusion returns the module path globs that skip the checksum
+// database and the setting they come from. Like the go command, it takes
+// GONOSUMDB, which defaults to GOPRIVATE: once GONOSUMDB is set, GOPRIVATE
+// no longer matters.
+func sumdbExclusion() (patterns, setting string) {
+	patterns = goEnv("GONOSUMDB") // go env answers with the effective value
+	if patterns == "" {
+		patterns = goEnv("GOPRIVATE")
+	}
+	if os.Getenv("GONOSUMDB") == "" && patterns == goEnv("GOPRIVATE") {
+		return patterns, "GOPRIVATE"
+	}
+	return patterns, "GONOSUMDB"
+}
+
+var sumdbExcluded = sync.OnceValues(sumdbExclusion)
+
+// sumdbStatus describes how a module's checksums were verified: the name
+// of the checksum database covering it, or "unverified" with the setting
+// that excluded it.
+func sumdbStatus(path string) string {
+	if sumdbName() == "" {
+		return "unverified: GOSUMDB=off"
+	}
+	if patterns, setting := sumdbExcluded(); module.MatchPrefixPatterns(patterns, path) {
+		return "unverified: " + setting
+	}
+	return sumdbName()
+}
+
+// sumdbAnalyzer annotates external modules with "sumdb": the checksum
+// database the go command checks their go.sum lines against, or why it
+// does not, for supply-chain reviews.
+type sumdbAnalyzer struct{}
+
+func (sumdbAnalyzer) Name() string { return "sumdb" }
+
+func (sumdbAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+		if isExternal(node) && !strings.HasPrefix(node.ID, "import:") && node.Version != "" {
+			graph.Annotate(node.ID, "sumdb", sumdbStatus(node.ID))
+		}
+	}
+	return nil
+}
+
+// unverified reports whether a node's checksums are not covered by a
+// checksum database.
+func unverified(node *depgraph.Node) bool {
+	return strings.HasPrefix(node.Annotations["sumdb"], "unverified")
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+// setSumdb makes sumdbStatus see a checksum database and the globs it
+// skips for the rest of a test.
+func setSumdb(t *testing.T, name, patterns, setting string) {
+	savedName, savedExcluded := sumdbName, sumdbExcluded
+	sumdbName = func() string { return name }
+	sumdbExcluded = func() (string, string) { return patterns, setting }
+	t.Cleanup(func() { sumdbName, sumdbExcluded = savedName, savedExcluded })
+}
+
+func TestSumdbExclusion(t *testing.T) {
+	tests := []struct {
+		name                 string
+		goprivate, gonosumdb string
+		patterns, setting    string
+	}{
+		{"none", "", "", "", "GOPRIVATE"},
+		{"GOPRIVATE", "git.corp.example", "", "git.corp.example", "GOPRIVATE"},
+		{"GONOSUMDB", "", "git.corp.example", "git.corp.example", "GONOSUMDB"},
+		// cmd/go: GONOSUMDB = envOr("GONOSUMDB", GOPRIVATE)
+		{"GONOSUMDB replaces GOPRIVATE", "git.corp.example", "github.com/acme", "github.com/acme", "GONOSUMDB"},
+		{"GONOSUMDB like GOPRIVATE", "git.corp.example", "git.corp.example", "git.corp.example", "GONOSUMDB"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GOPRIVATE", test.goprivate)
+			t.Setenv("GONOSUMDB", test.gonosumdb)
+			patterns, setting := sumdbExclusion()
+			if patterns != test.patterns || patterns != "" && setting != test.setting {
+				t.Errorf("sumdbExclusion = %q, %s, want %q, %s", patterns, setting, test.patterns, test.setting)
+			}
+		})
+	}
+}
+
+func TestSumdbStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		db       string
+		patterns string
+		setting  string
+		path     string
+		want     string
+	}{
+		{"default", "sum.golang.org", "", "", "github.com/pkg/errors", "sum.golang.org"},
+		{"mirror", "sum.example.com", "", "", "github.com/pkg/errors", "sum.example.com"},
+		{"off", "", "", "", "github.com/pkg/errors", "unverified: GOSUMDB=off"},
+		{"off and excluded", "", "git.corp.example", "GONOSUMDB", "git.corp.example/lib", "unverified: GOSUMDB=off"},
+		{"GOPRIVATE", "sum.golang.org", "git.corp.example", "GOPRIVATE", "git.corp.example/lib", "unverified: GOPRIVATE"},
+		{"GONOSUMDB", "sum.golang.org", "git.corp.example", "GONOSUMDB", "git.corp.example/lib", "unverified: GONOSUMDB"},
+		{"not excluded", "sum.golang.org", "git.corp.example", "GONOSUMDB", "github.com/pkg/errors", "sum.golang.org"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setSumdb(t, test.db, test.patterns, test.setting)
+			if got := sumdbStatus(test.path); got != test.want {
+				t.Errorf("sumdbStatus(%s) = %q, want %q", test.path, got, test.want)
+			}
+		})
+	}
+	t.Run("GONOSUMCHECK is not a go setting", func(t *testing.T) {
+		setSumdb(t, "sum.golang.org", "", "")
+		t.Setenv("GONOSUMCHECK", "1")
+		if got := sumdbStatus("github.com/pkg/errors"); got != "sum.golang.org" {
+			t.Errorf("sumdbStatus = %q with GONOSUMCHECK=1", got)
+		}
+	})
+}
+
+func TestSumdbAnalyzer(t *testing.T) {
+	setSumdb(t, "sum.golang.org", "git.corp.example", "GONOSUMDB")
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{
+		{ID: "example.com/app", Type: "main"},
+		{ID: "github.com/pkg/errors", Type: "external", Version: "v0.9.1"},
+		{ID: "import:github.com/pkg/errors", Type: "external"},
+		{ID: "git.corp.example/lib", Type: privateType, Version: "v1.2.0"},
+		{ID: "example.com/app/replaced", Type: "external"}, // replaced by a directory
+	}}
+	if err := (sumdbAnalyzer{}).Enrich(context.Background(), graph); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", "sum.golang.org", "", "unverified: GONOSUMDB", ""}
+	for i, node := range graph.Nodes {
+		if got := node.Annotations["sumdb"]; got != want[i] {
+			t.Errorf("%s: sumdb %q, want %q", node.ID, got, want[i])
+		}
+	}
+	if !unverified(&graph.Nodes[3]) || unverified(&graph.Nodes[1]) {
+		t.Error("unverified does not follow the annotation")
+	}
+}
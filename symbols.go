@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+var symbolModules []string // -symbols, may be repeated
+
+func init() {
+	depgraph.Register(symbolsAnalyzer{})
+}
+
+// symbolsAnalyzer lists on each import edge into the modules chosen with
+// -symbols the exported identifiers the importer uses, resolved by type
+// checking the project: functions, types, variables and constants, and
+// methods and fields as Type.Name. The module nodes get the number of
+// distinct identifiers used as "symbols-used".
+type symbolsAnalyzer struct{}
+
+func (symbolsAnalyzer) Name() string { return "symbols" }
+
+func (symbolsAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if len(symbolModules) == 0 {
+		return nil
+	}
+	uses, err := symbolUses(ctx, targetPath, symbolModules)
+	if err != nil {
+		return err
+	}
+
+	edges := make(map[[2]string]*depgraph.Edge)
+	for i := range graph.Edges {
+		if edge := &graph.Edges[i]; edge.Kind == "" {
+			edges[[2]string{edge.Source, edge.Target}] = edge
+		}
+	}
+	used := make(map[string]map[string]bool) // module -> qualified symbols
+	for _, use := range uses {
+		if used[use.module] == nil {
+			used[use.module] = make(map[string]bool)
+		}
+		used[use.module][use.pkgPath+"."+use.symbol] = true
+
+		// Types of packages that are not imported directly, such as
+		// results of the imported API, go on the edge to the module
+		source := use.packageID
+		if fileNodes {
+			source = "file:" + use.file
+		}
+		edge := edges[[2]string{source, "import:" + use.pkgPath}]
+		if edge == nil {
+			edge = edges[[2]string{source, use.pkgPath}]
+		}
+		symbol := use.symbol
+		if edge == nil {
+			edge = edges[[2]string{source, use.module}]
+			symbol = path.Base(use.pkgPath) + "." + symbol
+		}
+		if edge != nil && !slices.Contains(edge.Symbols, symbol) {
+			edge.Symbols = append(edge.Symbols, symbol)
+		}
+	}
+	for i := range graph.Edges {
+		slices.Sort(graph.Edges[i].Symbols)
+	}
+	for module, symbols := range used {
+		graph.Annotate(module, "symbols-used", strconv.Itoa(len(symbols)))
+	}
+	return nil
+}
+
+// symbolUse is a use of an exported identifier of a dependency in the
+// project.
+type symbolUse struct {
+	packageID string // package node of the use
+	file      string // slash separated and relative to the module root
+	line      int
+	module    string
+	pkgPath   string
+	symbol    string // Name, or Type.Name for methods and fields
+}
+
+// listedPackage is the part of `go list -json` output symbolUses needs.
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	ImportMap  map[string]string
+	Module     *struct {
+		Path string
+		Dir  string
+		Main bool
+	}
+}
+
+// symbolUses type-checks the non-test packages of the main module in dir
+// against the export data `go list -export` compiles, and reports the
+// exported identifiers they use from the given modules.
+func symbolUses(ctx context.Context, dir string, modules []string) ([]symbolUse, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-export", "-deps", "-json=ImportPath,Dir,GoFiles,Export,ImportMap,Module", "./...")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		// Packages that fail to compile still list, without export data
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	var pkgs []listedPackage
+	exports := make(map[string]string) // import path -> export data file
+	for dec := json.NewDecoder(bytes.NewReader(out)); ; {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		exports[pkg.ImportPath] = pkg.Export
+		if pkg.Module != nil && pkg.Module.Main {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	fset := token.NewFileSet()
+	gc := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		if exports[path] == "" {
+			return nil, errors.New("no export data for " + path)
+		}
+		return os.Open(exports[path])
+	})
+
+	var uses []symbolUse
+	for _, pkg := range pkgs {
+		var files []*ast.File
+		for _, name := range pkg.GoFiles {
+			if file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.SkipObjectResolution); err == nil {
+				files = append(files, file)
+			}
+		}
+		info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+		conf := types.Config{
+			Importer: importerFunc(func(path string) (*types.Package, error) {
+				if vendored, ok := pkg.ImportMap[path]; ok {
+					path = vendored
+				}
+				return gc.Import(path)
+			}),
+			Error: func(error) {}, // use what type checks
+		}
+		conf.Check(pkg.ImportPath, fset, files, info)
+
+		rel, _ := filepath.Rel(pkg.Module.Dir, pkg.Dir)
+		rel = filepath.ToSlash(rel)
+		for ident, obj := range info.Uses {
+			if obj.Pkg() == nil || !obj.Exported() {
+				continue
+			}
+			module := symbolModule(obj.Pkg().Path(), modules)
+			if module == "" {
+				continue
+			}
+			packageID := depgraph.PackageID(rel)
+			pos := fset.Position(ident.Pos())
+			file := path.Join(rel, filepath.Base(pos.Filename))
+			uses = append(uses, symbolUse{packageID, file, pos.Line, module, obj.Pkg().Path(), qualifiedSymbol(obj)})
+		}
+	}
+	return uses, nil
+}
+
+// symbolModule returns the module among modules that pkgPath belongs to.
+func symbolModule(pkgPath string, modules []string) string {
+	for _, module := range modules {
+		if pkgPath == module || strings.HasPrefix(pkgPath, module+"/") {
+			return module
+		}
+	}
+	return ""
+}
+
+// qualifiedSymbol names an object as its package would: methods and fields
+// are qualified by the type declaring them.
+func qualifiedSymbol(obj types.Object) string {
+	var recv types.Type
+	switch obj := obj.(type) {
+	case *types.Func:
+		if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+			recv = sig.Recv().Type()
+		}
+	case *types.Var:
+		if obj.IsField() {
+			recv = fieldOwner(obj)
+		}
+	}
+	if recv == nil {
+		return obj.Name()
+	}
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	if named, ok := recv.(*types.Named); ok {
+		return named.Obj().Name() + "." + obj.Name()
+	}
+	return obj.Name() // interface methods and fields of unnamed structs
+}
+
+// fieldOwner finds the named type of its package that declares a field.
+func fieldOwner(field *types.Var) types.Type {
+	scope := field.Pkg().Scope()
+	for _, name := range scope.Names() {
+		named, ok := scope.Lookup(name).Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		if st, ok := named.Underlying().(*types.Struct); ok {
+			for i := range st.NumFields() {
+				if st.Field(i) == field {
+					return named
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
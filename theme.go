@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configPath is the project configuration file; empty uses goraph.yaml in
+// the analyzed directory.
+var configPath string
+
+// config is goraph.yaml. Everything in it is optional.
+type config struct {
+	Theme  theme       `yaml:"theme"`
+	Labels labelConfig `yaml:"labels"`
+}
+
+// theme styles the web UI and the HTML exports alike, so both follow an
+// organization's branding: page colors and font, node colors per type and
+// edge styles per kind. Fields left empty keep the base of the mode.
+type theme struct {
+	Mode            string               `json:"mode" yaml:"mode"` // dark (default) or light
+	Background      string               `json:"background" yaml:"background"`
+	Text            string               `json:"text" yaml:"text"`
+	Link            string               `json:"link" yaml:"link"`
+	LabelBackground string               `json:"labelBackground" yaml:"label-background"`
+	LabelText       string               `json:"labelText" yaml:"label-text"`
+	Font            string               `json:"font" yaml:"font"`
+	NodeColors      map[string]string    `json:"nodeColors" yaml:"node-colors"` // by node type
+	Edges           map[string]edgeStyle `json:"edges" yaml:"edges"`            // by edge kind, "default" for the rest
+}
+
+type edgeStyle struct {
+	Color string    `json:"color,omitempty" yaml:"color"`
+	Width float64   `json:"width,omitempty" yaml:"width"` // pixels at zoom 1
+	Dash  []float64 `json:"dash,omitempty" yaml:"dash"`   // canvas line dash, empty for solid
+}
+
+// baseTheme returns the built-in theme of a mode.
+func baseTheme(mode string) (theme, error) {
+	t := theme{
+		Mode:            "dark",
+		Background:      "#0a0a0a",
+		Text:            "rgba(255, 255, 255, 0.6)",
+		Link:            "#8af",
+		LabelBackground: "rgba(0, 0, 0, 0.95)",
+		LabelText:       "rgba(255, 255, 255, 1)",
+		Font:            "'SF Mono', Monaco, 'Cascadia Code', monospace",
+		NodeColors:      maps.Clone(typeColors),
+		Edges: map[string]edgeStyle{
+			"default": {Color: "rgba(100, 100, 100, 0.4)", Width: 1},
+			"skew":    {Color: "rgba(255, 100, 100, 0.8)", Width: 1, Dash: []float64{4, 4}},
+		},
+	}
+	switch mode {
+	case "", "dark":
+	case "light":
+		t.Mode = mode
+		t.Background = "#fafafa"
+		t.Text = "rgba(0, 0, 0, 0.65)"
+		t.Link = "#2456c8"
+		t.LabelBackground = "rgba(255, 255, 255, 0.95)"
+		t.LabelText = "rgba(0, 0, 0, 1)"
+		t.Edges["default"] = edgeStyle{Color: "rgba(80, 80, 80, 0.35)", Width: 1}
+	default:
+		return t, fmt.Errorf("unknown theme mode %q", mode)
+	}
+	return t, nil
+}
+
+// merge returns t with the fields set in override replacing its own.
+func (t theme) merge(override theme) theme {
+	for _, f := range []struct{ dst, src *string }{
+		{&t.Background, &override.Background},
+		{&t.Text, &override.Text},
+		{&t.Link, &override.Link},
+		{&t.LabelBackground, &override.LabelBackground},
+		{&t.LabelText, &override.LabelText},
+		{&t.Font, &override.Font},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	maps.Copy(t.NodeColors, override.NodeColors)
+	for kind, style := range override.Edges {
+		base := t.Edges[kind]
+		if style.Color != "" {
+			base.Color = style.Color
+		}
+		if style.Width > 0 {
+			base.Width = style.Width
+		}
+		if style.Dash != nil {
+			base.Dash = style.Dash
+		}
+		t.Edges[kind] = base
+	}
+	return t
+}
+
+// readConfig reads the project configuration, which need not exist.
+func readConfig() (config, error) {
+	var cfg config
+	name := configPath
+	if name == "" {
+		name = filepath.Join(targetPath, "goraph.yaml")
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) && configPath == "" {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// currentTheme returns the configured theme, read once, or the dark theme
+// when the configuration is invalid.
+var currentTheme = sync.OnceValue(func() theme {
+	cfg, err := readConfig()
+	if err == nil {
+		var base theme
+		if base, err = baseTheme(cfg.Theme.Mode); err == nil {
+			t := base.merge(cfg.Theme)
+			if err = t.validate(); err == nil {
+				return t
+			}
+		}
+	}
+	log.Printf(tr("⚠️ Reading the theme failed: %v"), err)
+	base, _ := baseTheme("dark")
+	return base
+})
+
+var (
+	cssColor = regexp.MustCompile(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|(rgb|rgba|hsl|hsla)\([0-9.,%/ ]+\))$`)
+	cssFont  = regexp.MustCompile(`^[a-zA-Z0-9 ,'"-]+$`)
+)
+
+// validate checks that the colors and font of a theme are plain CSS values,
+// as goraph.yaml comes with the repository analyzed and its values end up
+// in style sheets: anything else could close the style element.
+func (t theme) validate() error {
+	colors := map[string]string{
+		"background":       t.Background,
+		"text":             t.Text,
+		"link":             t.Link,
+		"label-background": t.LabelBackground,
+		"label-text":       t.LabelText,
+	}
+	for typ, color := range t.NodeColors {
+		colors["node-colors."+typ] = color
+	}
+	for kind, style := range t.Edges {
+		if style.Color != "" {
+			colors["edges."+kind+".color"] = style.Color
+		}
+	}
+	for field, color := range colors {
+		if !cssColor.MatchString(color) {
+			return fmt.Errorf("theme %s: invalid color %q", field, color)
+		}
+	}
+	if !cssFont.MatchString(t.Font) {
+		return fmt.Errorf("theme font: invalid font %q", t.Font)
+	}
+	return nil
+}
+
+// pageCSS styles the body and links of the HTML exports. The theme was
+// validated, so its values can go into the style sheet as they are.
+func (t theme) pageCSS() template.CSS {
+	return template.CSS(fmt.Sprintf("body { background: %s; color: %s; font-family: %s; }\na { color: %s; }",
+		t.Background, t.Text, t.Font, t.Link))
+}
+
+// themeHandler serves /api/theme, the theme the web UI draws with.
+func themeHandler(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, currentTheme())
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestThemeValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		theme theme
+		ok    bool
+	}{
+		{"hex", theme{Background: "#fafafa", Link: "#B5121B80"}, true},
+		{"named", theme{Text: "white"}, true},
+		{"rgba", theme{Text: "rgba(0, 0, 0, 0.65)"}, true},
+		{"hsl", theme{Text: "hsl(210 40% 50% / 0.5)"}, true},
+		{"font list", theme{Font: `Inter, "Helvetica Neue", sans-serif`}, true},
+		{"style injection", theme{Background: "red;}</style><script>alert(1)</script>"}, false},
+		{"url", theme{Background: "url(https://evil.example/x.png)"}, false},
+		{"expression", theme{Text: "red; background: url(x)"}, false},
+		{"font injection", theme{Font: "x;}</style><script>"}, false},
+		{"node color", theme{NodeColors: map[string]string{"main": "red</style>"}}, false},
+		{"edge color", theme{Edges: map[string]edgeStyle{"skew": {Color: "blue}"}}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, err := baseTheme("dark")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := base.merge(test.theme).validate(); (err == nil) != test.ok {
+				t.Errorf("validate = %v, want ok %v", err, test.ok)
+			}
+		})
+	}
+	for _, mode := range []string{"dark", "light"} {
+		base, _ := baseTheme(mode)
+		if err := base.validate(); err != nil {
+			t.Errorf("%s theme: %v", mode, err)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"go-raph/depgraph"
+)
+
+var previewTidy bool // -tidy
+
+func init() {
+	depgraph.Register(tidyAnalyzer{})
+}
+
+// tidyAnalyzer predicts what `go mod tidy` would change in go.mod, from the
+// packages the main module and its tests import against the requirements,
+// without touching go.mod. Requirements present in the graph get "tidy",
+// the comment change tidy would make. The main module lists every change as
+// "tidy-changes", and the requirements to add or remove as "tidy-add" and
+// "tidy-remove". It only runs with -tidy.
+type tidyAnalyzer struct{}
+
+func (tidyAnalyzer) Name() string { return "tidy" }
+
+func (tidyAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if !previewTidy {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(targetPath, "go.mod"))
+	if err != nil {
+		return nil // not a module, or a workspace
+	}
+	modFile, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return err
+	}
+	p, err := predictTidy(ctx, targetPath, modFile)
+	if err != nil {
+		return err
+	}
+
+	var changes []string
+	for _, module := range sortedSet(p.changes) {
+		changes = append(changes, module+": "+p.changes[module])
+		if graph.Node(module) != nil {
+			graph.Annotate(module, "tidy", p.changes[module])
+		}
+	}
+	if main := modFile.Module.Mod.Path; graph.Node(main) != nil {
+		if len(changes) > 0 {
+			graph.Annotate(main, "tidy-changes", strings.Join(changes, ", "))
+		}
+		if len(p.add) > 0 {
+			graph.Annotate(main, "tidy-add", strings.Join(p.add, ", "))
+		}
+		if len(p.remove) > 0 {
+			graph.Annotate(main, "tidy-remove", strings.Join(p.remove, ", "))
+		}
+	}
+	return nil
+}
+
+// tidyPrediction is what `go mod tidy` would change in go.mod.
+type tidyPrediction struct {
+	add     []string          // modules to require, "(indirect)" when not imported directly, or imports "(unresolved)"
+	remove  []string          // requirements nothing needs
+	changes map[string]string // requirement -> "drop // indirect" or "mark // indirect"
+}
+
+// predictTidy compares the requirements of go.mod with the modules
+// providing the packages `go list -deps -test ./...` loads: the packages of
+// the main module, their tests and everything they import, which is what
+// tidy keeps since Go 1.17. Imports no requirement provides are the modules
+// tidy would download and add. Build tags other than the host's are not
+// considered, so requirements only other platforms need show as removable.
+func predictTidy(ctx context.Context, dir string, modFile *modfile.File) (*tidyPrediction, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-mod=readonly", "-e", "-deps", "-test", "-json=ImportPath,Standard,Imports,Module", "./...")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	return tidyFromPackages(out, modFile)
+}
+
+// tidyFromPackages predicts the changes from the output of go list.
+func tidyFromPackages(out []byte, modFile *modfile.File) (*tidyPrediction, error) {
+	needed := make(map[string]bool)  // modules providing loaded packages
+	direct := make(map[string]bool)  // modules the main module imports
+	missing := make(map[string]bool) // imports no module provides
+	owner := make(map[string]string) // import path -> module
+	var mainImports []string
+	for dec := json.NewDecoder(bytes.NewReader(out)); ; {
+		var pkg struct {
+			ImportPath string
+			Standard   bool
+			Imports    []string
+			Module     *struct {
+				Path string
+				Main bool
+			}
+		}
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		switch {
+		case pkg.Standard || strings.HasSuffix(pkg.ImportPath, ".test"):
+		case pkg.Module == nil:
+			missing[pkg.ImportPath] = true
+		case pkg.Module.Main:
+			mainImports = append(mainImports, pkg.Imports...)
+		default:
+			// Test variants list as "path [pkg.test]"
+			importPath, _, _ := strings.Cut(pkg.ImportPath, " ")
+			needed[pkg.Module.Path] = true
+			owner[importPath] = pkg.Module.Path
+		}
+	}
+	for _, imp := range mainImports {
+		imp, _, _ = strings.Cut(imp, " ")
+		if module := owner[imp]; module != "" {
+			direct[module] = true
+		}
+	}
+	// Tools are kept like imports
+	required := make(map[string]bool)
+	for _, req := range modFile.Require {
+		required[req.Mod.Path] = true
+	}
+	for _, tool := range modFile.Tool {
+		if module := requiredModule(required, tool.Path); module != "" {
+			needed[module], direct[module] = true, true
+		}
+	}
+
+	p := &tidyPrediction{changes: make(map[string]string)}
+	for _, req := range modFile.Require {
+		switch {
+		case !needed[req.Mod.Path]:
+			p.remove = append(p.remove, req.Mod.Path)
+		case req.Indirect && direct[req.Mod.Path]:
+			p.changes[req.Mod.Path] = "drop // indirect"
+		case !req.Indirect && !direct[req.Mod.Path]:
+			p.changes[req.Mod.Path] = "mark // indirect"
+		}
+	}
+	for _, module := range sortedSet(needed) {
+		switch {
+		case required[module]:
+		case direct[module]:
+			p.add = append(p.add, module)
+		default:
+			p.add = append(p.add, module+" (indirect)")
+		}
+	}
+	for _, imp := range sortedSet(missing) {
+		p.add = append(p.add, imp+" (unresolved)")
+	}
+	return p, nil
+}
+
+// requiredModule returns the longest module of required that provides an
+// import path.
+func requiredModule(required map[string]bool, importPath string) string {
+	best := ""
+	for module := range required {
+		if (importPath == module || strings.HasPrefix(importPath, module+"/")) && len(module) > len(best) {
+			best = module
+		}
+	}
+	return best
+}
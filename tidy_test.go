@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/modfile"
+)
+
+func TestTidyFromPackages(t *testing.T) {
+	// Packages as go list -deps -test -json prints them
+	const (
+		mainPkg   = `{"ImportPath": "example.com/app", "Imports": ["fmt", "github.com/a/http"], "Module": {"Path": "example.com/app", "Main": true}}`
+		mainTest  = `{"ImportPath": "example.com/app [example.com/app.test]", "Imports": ["github.com/t/assert"], "Module": {"Path": "example.com/app", "Main": true}}`
+		testMain  = `{"ImportPath": "example.com/app.test", "Imports": ["example.com/app [example.com/app.test]"], "Module": {"Path": "example.com/app", "Main": true}}`
+		fmtPkg    = `{"ImportPath": "fmt", "Standard": true}`
+		httpPkg   = `{"ImportPath": "github.com/a/http", "Imports": ["github.com/x/log"], "Module": {"Path": "github.com/a/http"}}`
+		logPkg    = `{"ImportPath": "github.com/x/log", "Module": {"Path": "github.com/x/log"}}`
+		assertPkg = `{"ImportPath": "github.com/t/assert", "Module": {"Path": "github.com/t/assert"}}`
+		missing   = `{"ImportPath": "github.com/gone/pkg"}`
+	)
+	for _, tt := range []struct {
+		name     string
+		requires string
+		tool     string
+		packages []string
+		want     tidyPrediction
+	}{
+		{
+			name:     "tidy",
+			requires: "github.com/a/http v1.0.0\n\tgithub.com/x/log v1.0.0 // indirect",
+			packages: []string{mainPkg, fmtPkg, httpPkg, logPkg},
+			want:     tidyPrediction{changes: map[string]string{}},
+		},
+		{
+			name:     "direct import marked indirect",
+			requires: "github.com/a/http v1.0.0 // indirect\n\tgithub.com/x/log v1.0.0 // indirect",
+			packages: []string{mainPkg, httpPkg, logPkg},
+			want:     tidyPrediction{changes: map[string]string{"github.com/a/http": "drop // indirect"}},
+		},
+		{
+			name:     "indirect dependency not marked",
+			requires: "github.com/a/http v1.0.0\n\tgithub.com/x/log v1.0.0",
+			packages: []string{mainPkg, httpPkg, logPkg},
+			want:     tidyPrediction{changes: map[string]string{"github.com/x/log": "mark // indirect"}},
+		},
+		{
+			name:     "unused requirement",
+			requires: "github.com/a/http v1.0.0\n\tgithub.com/x/log v1.0.0 // indirect\n\tgithub.com/old/lib v0.1.0",
+			packages: []string{mainPkg, httpPkg, logPkg},
+			want:     tidyPrediction{remove: []string{"github.com/old/lib"}, changes: map[string]string{}},
+		},
+		{
+			name:     "missing requirements",
+			requires: "",
+			packages: []string{mainPkg, httpPkg, logPkg},
+			want:     tidyPrediction{add: []string{"github.com/a/http", "github.com/x/log (indirect)"}, changes: map[string]string{}},
+		},
+		{
+			name:     "test imports",
+			requires: "github.com/a/http v1.0.0\n\tgithub.com/x/log v1.0.0 // indirect",
+			packages: []string{mainPkg, mainTest, testMain, httpPkg, logPkg, assertPkg},
+			want:     tidyPrediction{add: []string{"github.com/t/assert"}, changes: map[string]string{}},
+		},
+		{
+			name:     "unresolved import",
+			requires: "github.com/a/http v1.0.0\n\tgithub.com/x/log v1.0.0 // indirect",
+			packages: []string{mainPkg, httpPkg, logPkg, missing},
+			want:     tidyPrediction{add: []string{"github.com/gone/pkg (unresolved)"}, changes: map[string]string{}},
+		},
+		{
+			name:     "tool",
+			requires: "github.com/a/http v1.0.0\n\tgithub.com/x/log v1.0.0 // indirect\n\tgolang.org/x/tools v0.30.0 // indirect",
+			tool:     "golang.org/x/tools/cmd/stringer",
+			packages: []string{mainPkg, httpPkg, logPkg},
+			want:     tidyPrediction{changes: map[string]string{"golang.org/x/tools": "drop // indirect"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gomod := "module example.com/app\n\ngo 1.24\n\nrequire (\n\t" + tt.requires + "\n)\n"
+			if tt.tool != "" {
+				gomod += "\ntool " + tt.tool + "\n"
+			}
+			modFile, err := modfile.Parse("go.mod", []byte(gomod), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := tidyFromPackages([]byte(strings.Join(tt.packages, "\n")), modFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("tidyFromPackages() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	modFile, _ := modfile.Parse("go.mod", []byte("module example.com/app\n"), nil)
+	if _, err := tidyFromPackages([]byte(mainPkg+"\n{"), modFile); err == nil {
+		t.Error("tidyFromPackages accepted truncated output")
+	}
+}
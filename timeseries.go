@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-raph/depgraph"
+)
+
+// maxMetricsPoints bounds the history kept per project.
+const maxMetricsPoints = 5000
+
+// metricsPoint records the dependency health of one analysis.
+type metricsPoint struct {
+	Time       time.Time `json:"time"`
+	Deps       int       `json:"deps"` // required modules that are imported
+	DirectDeps int       `json:"directDeps"`
+	Cycles     int       `json:"cycles"`
+	Outdated   int       `json:"outdated"` // modules with a newer version on the proxy
+}
+
+// timeseries holds the metrics history per project, persisted so teams can
+// chart dependency health over time.
+var timeseries = struct {
+	sync.Mutex
+	loaded   bool
+	projects map[string][]metricsPoint
+}{projects: make(map[string][]metricsPoint)}
+
+// loadTimeseries reads the history file once; callers hold the lock.
+func loadTimeseries() {
+	if timeseries.loaded {
+		return
+	}
+	timeseries.loaded = true
+	if err := readState("timeseries.json", &timeseries.projects); err != nil {
+		log.Printf(tr("⚠️ Reading %s failed: %v"), "timeseries.json", err)
+	}
+	if timeseries.projects == nil {
+		timeseries.projects = make(map[string][]metricsPoint)
+	}
+}
+
+// recordMetrics appends the metrics of an analysis to the project's history.
+// Looking up outdated modules goes to the network, so it is meant to run in
+// the background.
+func recordMetrics(ctx context.Context, graph *depgraph.Graph) {
+	m := computeMetrics(graph)
+	point := metricsPoint{
+		Time:       time.Now().UTC(),
+		Deps:       m.Modules,
+		DirectDeps: m.DirectDeps,
+		Cycles:     len(graph.Cycles()),
+		Outdated:   countOutdated(ctx, graph),
+	}
+
+	timeseries.Lock()
+	defer timeseries.Unlock()
+	loadTimeseries()
+	key := projectKey()
+	points := append(timeseries.projects[key], point)
+	if len(points) > maxMetricsPoints {
+		points = points[len(points)-maxMetricsPoints:]
+	}
+	timeseries.projects[key] = points
+	if err := writeState("timeseries.json", timeseries.projects); err != nil {
+		log.Printf(tr("⚠️ Saving metrics history failed: %v"), err)
+	}
+}
+
+// timeseriesHandler serves the project's metrics history, optionally only
+// points after ?since= (RFC 3339).
+func timeseriesHandler(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	timeseries.Lock()
+	defer timeseries.Unlock()
+	loadTimeseries()
+	points := []metricsPoint{}
+	for _, p := range timeseries.projects[projectKey()] {
+		if p.Time.After(since) {
+			points = append(points, p)
+		}
+	}
+	respondJSON(w, points)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// writeTextTree writes the graph as an indented plain ASCII tree, readable
+// over SSH and by screen readers. Each node's dependencies are listed once;
+// later occurrences are marked "(listed above)" and import cycles "(cycle)".
+func writeTextTree(w io.Writer, graph *depgraph.Graph) error {
+	nodes := make(map[string]*depgraph.Node)
+	for i := range graph.Nodes {
+		nodes[graph.Nodes[i].ID] = &graph.Nodes[i]
+	}
+	children := make(map[string][]depgraph.Edge)
+	imported := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		if edge.Kind == "skew" || nodes[edge.Source] == nil || nodes[edge.Target] == nil {
+			continue // skew edges are warnings, not dependencies
+		}
+		children[edge.Source] = append(children[edge.Source], edge)
+		imported[edge.Target] = true
+	}
+	for id := range children {
+		sort.Slice(children[id], func(i, j int) bool {
+			return treeName(nodes[children[id][i].Target]) < treeName(nodes[children[id][j].Target])
+		})
+	}
+
+	expanded := make(map[string]bool)
+	onPath := make(map[string]bool)
+	var b strings.Builder
+	var walk func(id, prefix string)
+	walk = func(id, prefix string) {
+		onPath[id] = true
+		expanded[id] = true
+		for i, edge := range children[id] {
+			branch, indent := "|-- ", "|   "
+			if i == len(children[id])-1 {
+				branch, indent = "`-- ", "    "
+			}
+			b.WriteString(prefix + branch + treeLine(nodes[edge.Target], edge.Kind))
+			switch {
+			case onPath[edge.Target]:
+				b.WriteString(" (cycle)\n")
+			case expanded[edge.Target] && len(children[edge.Target]) > 0:
+				b.WriteString(" (listed above)\n")
+			default:
+				b.WriteString("\n")
+				walk(edge.Target, prefix+indent)
+			}
+		}
+		onPath[id] = false
+	}
+
+	// Roots are the nodes nothing depends on, the main module first; nodes
+	// only reachable through cycles follow
+	var roots []*depgraph.Node
+	for i := range graph.Nodes {
+		if !imported[graph.Nodes[i].ID] {
+			roots = append(roots, &graph.Nodes[i])
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		if (roots[i].Type == "main") != (roots[j].Type == "main") {
+			return roots[i].Type == "main"
+		}
+		return treeName(roots[i]) < treeName(roots[j])
+	})
+	for i := range graph.Nodes {
+		if imported[graph.Nodes[i].ID] {
+			roots = append(roots, &graph.Nodes[i])
+		}
+	}
+	for _, root := range roots {
+		if expanded[root.ID] {
+			continue
+		}
+		b.WriteString(treeLine(root, "") + "\n")
+		walk(root.ID, "")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// treeName names a node in the tree: packages by their directory, everything
+// else by label.
+func treeName(node *depgraph.Node) string {
+	if node.Type == "package" {
+		if dir := strings.TrimPrefix(node.ID, "pkg:"); dir != "root" {
+			return "./" + dir
+		}
+		return "."
+	}
+	return node.Label
+}
+
+// treeLine describes a node with its version, type and the kind of edge
+// leading to it.
+func treeLine(node *depgraph.Node, kind string) string {
+	line := treeName(node)
+	if node.Version != "" {
+		line += " " + node.Version
+	}
+	line += fmt.Sprintf(" (%s)", node.Type)
+	if kind != "" {
+		line += " [" + kind + "]"
+	}
+	return line
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"go-raph/depgraph"
+)
+
+// tuiCommand implements `go-raph tui`, browsing the graph in the terminal:
+// a dependency tree expanded on demand, the selected node's details and the
+// nodes depending on it, and search.
+func tuiCommand(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	addAnalysisFlags(fs)
+	parseFlags(fs, args)
+
+	goOffline()
+	loadPlugins()
+	resolveTarget(fs)
+	loadSavedGraph()
+
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		fmt.Printf(tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+	if err := newBrowser(graph).app.Run(); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// browser is the state of the terminal UI.
+type browser struct {
+	graph     *depgraph.Graph
+	nodes     map[string]*depgraph.Node
+	children  map[string][]depgraph.Edge
+	importers map[string][]depgraph.Edge
+
+	app     *tview.Application
+	tree    *tview.TreeView
+	details *tview.TextView
+	list    *tview.List // reverse dependencies, or search results
+	search  *tview.InputField
+}
+
+func newBrowser(graph *depgraph.Graph) *browser {
+	b := &browser{
+		graph:     graph,
+		nodes:     make(map[string]*depgraph.Node),
+		children:  make(map[string][]depgraph.Edge),
+		importers: make(map[string][]depgraph.Edge),
+		app:       tview.NewApplication(),
+		tree:      tview.NewTreeView(),
+		details:   tview.NewTextView(),
+		list:      tview.NewList().ShowSecondaryText(false),
+		search:    tview.NewInputField().SetLabel("/"),
+	}
+	for i := range graph.Nodes {
+		b.nodes[graph.Nodes[i].ID] = &graph.Nodes[i]
+	}
+	for _, edge := range graph.Edges {
+		if edge.Kind == "skew" || b.nodes[edge.Source] == nil || b.nodes[edge.Target] == nil {
+			continue
+		}
+		b.children[edge.Source] = append(b.children[edge.Source], edge)
+		b.importers[edge.Target] = append(b.importers[edge.Target], edge)
+	}
+	byName := func(edges []depgraph.Edge, end func(depgraph.Edge) string) {
+		sort.Slice(edges, func(i, j int) bool {
+			return treeName(b.nodes[end(edges[i])]) < treeName(b.nodes[end(edges[j])])
+		})
+	}
+	for _, edges := range b.children {
+		byName(edges, func(e depgraph.Edge) string { return e.Target })
+	}
+	for _, edges := range b.importers {
+		byName(edges, func(e depgraph.Edge) string { return e.Source })
+	}
+
+	b.tree.SetBorder(true).SetTitle(" dependencies ")
+	b.details.SetBorder(true).SetTitle(" node ")
+	b.list.SetBorder(true)
+	b.tree.SetChangedFunc(func(n *tview.TreeNode) { b.show(n.GetReference()) })
+	b.tree.SetSelectedFunc(func(n *tview.TreeNode) {
+		if id, ok := n.GetReference().(string); ok && len(n.GetChildren()) == 0 {
+			b.expand(n, id)
+		} else {
+			n.SetExpanded(!n.IsExpanded())
+		}
+	})
+	b.search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			b.find(b.search.GetText())
+		} else {
+			b.app.SetFocus(b.tree)
+		}
+	})
+
+	b.showAll()
+	b.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if b.app.GetFocus() == b.search {
+			return event
+		}
+		switch {
+		case event.Key() == tcell.KeyTab:
+			if b.app.GetFocus() == b.tree {
+				b.app.SetFocus(b.list)
+			} else {
+				b.app.SetFocus(b.tree)
+			}
+			return nil
+		case event.Rune() == '/':
+			b.app.SetFocus(b.search)
+			return nil
+		case event.Rune() == 'r':
+			b.showAll()
+			return nil
+		case event.Rune() == 'q':
+			b.app.Stop()
+			return nil
+		}
+		return event
+	})
+
+	help := tview.NewTextView().SetText("enter: expand · tab: switch pane · /: search · r: whole graph · q: quit")
+	right := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(b.details, 0, 1, false).
+		AddItem(b.list, 0, 1, false)
+	panes := tview.NewFlex().
+		AddItem(b.tree, 0, 3, true).
+		AddItem(right, 0, 2, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(panes, 0, 1, true).
+		AddItem(b.search, 1, 0, false).
+		AddItem(help, 1, 0, false)
+	b.app.SetRoot(layout, true).SetFocus(b.tree)
+	return b
+}
+
+// treeNode returns a collapsed tree node for a node reached through an edge
+// of the kind.
+func (b *browser) treeNode(id, kind string) *tview.TreeNode {
+	n := tview.NewTreeNode(tview.Escape(treeLine(b.nodes[id], kind))).SetReference(id)
+	if len(b.children[id]) > 0 {
+		n.SetColor(tcell.ColorLightSkyBlue)
+	}
+	return n
+}
+
+// expand adds a node's dependencies to the tree; cycles just repeat nodes,
+// as children are only added when the user opens them.
+func (b *browser) expand(n *tview.TreeNode, id string) {
+	for _, edge := range b.children[id] {
+		n.AddChild(b.treeNode(edge.Target, edge.Kind))
+	}
+	n.SetExpanded(true)
+}
+
+// showAll roots the tree at the nodes nothing depends on, the main module
+// first.
+func (b *browser) showAll() {
+	root := tview.NewTreeNode("").SetSelectable(false)
+	var roots []*depgraph.Node
+	for i := range b.graph.Nodes {
+		if len(b.importers[b.graph.Nodes[i].ID]) == 0 {
+			roots = append(roots, &b.graph.Nodes[i])
+		}
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		if (roots[i].Type == "main") != (roots[j].Type == "main") {
+			return roots[i].Type == "main"
+		}
+		return treeName(roots[i]) < treeName(roots[j])
+	})
+	for _, node := range roots {
+		root.AddChild(b.treeNode(node.ID, ""))
+	}
+	b.setRoot(root)
+}
+
+// showNode roots the tree at one node, opened.
+func (b *browser) showNode(id string) {
+	root := b.treeNode(id, "")
+	b.expand(root, id)
+	b.setRoot(root)
+	b.app.SetFocus(b.tree)
+}
+
+// setRoot shows a tree; a root without a node only holds the top level.
+func (b *browser) setRoot(root *tview.TreeNode) {
+	b.tree.SetRoot(root).SetTopLevel(0)
+	current := root
+	if _, ok := root.GetReference().(string); !ok {
+		b.tree.SetTopLevel(1)
+		if len(root.GetChildren()) > 0 {
+			current = root.GetChildren()[0]
+		}
+	}
+	b.tree.SetCurrentNode(current)
+	b.show(current.GetReference())
+}
+
+// show describes the node and lists the nodes depending on it.
+func (b *browser) show(ref interface{}) {
+	id, ok := ref.(string)
+	node := b.nodes[id]
+	if !ok || node == nil {
+		return
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\n\ntype: %s\n", node.ID, node.Type)
+	if node.Version != "" {
+		fmt.Fprintf(&text, "version: %s\n", node.Version)
+	}
+	if node.License != "" {
+		fmt.Fprintf(&text, "license: %s\n", node.License)
+	}
+	if node.Size > 0 {
+		fmt.Fprintf(&text, "size: %s\n", depgraph.FormatBytes(node.Size))
+	}
+	fmt.Fprintf(&text, "dependencies: %d\n", len(b.children[id]))
+	keys := make([]string, 0, len(node.Annotations))
+	for key := range node.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&text, "%s: %s\n", key, node.Annotations[key])
+	}
+	b.details.SetText(text.String()).ScrollToBeginning()
+
+	b.list.Clear()
+	b.list.SetTitle(fmt.Sprintf(" depended on by (%d) ", len(b.importers[id])))
+	for _, edge := range b.importers[id] {
+		source := edge.Source
+		b.list.AddItem(tview.Escape(treeLine(b.nodes[source], edge.Kind)), "", 0, func() { b.showNode(source) })
+	}
+}
+
+// find lists the nodes whose ID or label contains the query.
+func (b *browser) find(query string) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return
+	}
+	b.list.Clear()
+	for i := range b.graph.Nodes {
+		node := &b.graph.Nodes[i]
+		if strings.Contains(strings.ToLower(node.ID), query) || strings.Contains(strings.ToLower(node.Label), query) {
+			id := node.ID
+			b.list.AddItem(tview.Escape(treeLine(node, "")), "", 0, func() { b.showNode(id) })
+		}
+	}
+	b.list.SetTitle(fmt.Sprintf(" matches for %q (%d) ", query, b.list.GetItemCount()))
+	b.app.SetFocus(b.list)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// userConfig is the user's config.yaml in configDir(), which packaged
+// installs read instead of wrapper scripts passing flags:
+//
+//	flags:                # defaults of every command's flags
+//	  offline: true
+//	  port: 9090
+//	  plugin: [~/plugins/owners.so]
+//	cache-dir: ~/.cache/go-raph
+//
+// Flags a command does not have are skipped, and flags given on the
+// command line take precedence. Per-project settings stay in goraph.yaml.
+type userConfig struct {
+	Flags    map[string]interface{} `yaml:"flags"`
+	CacheDir string                 `yaml:"cache-dir"`
+}
+
+// userConfigPath is where config.yaml is read from.
+func userConfigPath() string {
+	return filepath.Join(configDir(), "config.yaml")
+}
+
+// loadUserConfig reads config.yaml once; a missing file is an empty
+// configuration.
+var loadUserConfig = sync.OnceValues(func() (userConfig, error) {
+	var cfg userConfig
+	data, err := os.ReadFile(userConfigPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return userConfig{}, err
+	}
+	return cfg, nil
+})
+
+// parseFlags parses the command line of a command, then sets the flags
+// config.yaml gives defaults for, exiting like flag.ExitOnError when
+// either is invalid.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+	if err := applyUserConfig(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", userConfigPath(), err)
+		os.Exit(2)
+	}
+}
+
+// applyUserConfig sets the flags of fs that config.yaml has values for and
+// the command line did not set: a repeatable flag like -plugin given on the
+// command line replaces the configured list rather than adding to it.
+// Lists set repeatable flags once per item.
+func applyUserConfig(fs *flag.FlagSet) error {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range sortedKeys(cfg.Flags) {
+		if fs.Lookup(name) == nil || set[name] {
+			continue
+		}
+		values, ok := cfg.Flags[name].([]interface{})
+		if !ok {
+			values = []interface{}{cfg.Flags[name]}
+		}
+		for _, v := range values {
+			if err := fs.Set(name, expandHome(fmt.Sprint(v))); err != nil {
+				return fmt.Errorf("flag %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expandHome expands a leading ~/ to the home directory, as shells do.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// configCommand implements `go-raph config`, showing where go-raph keeps
+// its configuration, state and caches, and the flag defaults config.yaml
+// sets.
+func configCommand(args []string) {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	addLangFlag(fs)
+	parseFlags(fs, args)
+
+	name := userConfigPath()
+	if _, err := os.Stat(name); err != nil {
+		name += tr(" (not created)")
+	}
+	fmt.Printf("config:       %s\n", name)
+	fmt.Printf("state:        %s\n", stateDir())
+	fmt.Printf("cache:        %s\n", cacheDir())
+	fmt.Printf("module cache: %s\n", modCacheDir())
+	cfg, err := loadUserConfig()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	for _, key := range sortedKeys(cfg.Flags) {
+		fmt.Printf("  -%s=%v\n", key, cfg.Flags[key])
+	}
+}
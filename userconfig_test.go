@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestApplyUserConfig(t *testing.T) {
+	saved := loadUserConfig
+	t.Cleanup(func() { loadUserConfig = saved })
+	loadUserConfig = func() (userConfig, error) {
+		return userConfig{Flags: map[string]interface{}{
+			"plugin":  []interface{}{"a.so", "b.so"},
+			"webhook": "https://hooks.example.com/config",
+			"port":    9090,
+			"missing": true,
+		}}, nil
+	}
+	tests := []struct {
+		args     []string
+		plugins  []string
+		webhooks []string
+		port     string
+	}{
+		{nil, []string{"a.so", "b.so"}, []string{"https://hooks.example.com/config"}, "9090"},
+		{[]string{"-plugin", "c.so"}, []string{"c.so"}, []string{"https://hooks.example.com/config"}, "9090"},
+		{[]string{"-webhook", "https://hooks.example.com/cli", "-port", "8080"}, []string{"a.so", "b.so"}, []string{"https://hooks.example.com/cli"}, "8080"},
+	}
+	for _, test := range tests {
+		var plugins, webhooks []string
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.Func("plugin", "", func(s string) error { plugins = append(plugins, s); return nil })
+		fs.Func("webhook", "", func(s string) error { webhooks = append(webhooks, s); return nil })
+		port := fs.String("port", "8080", "")
+		if err := fs.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+		if err := applyUserConfig(fs); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(plugins, test.plugins) || !slices.Equal(webhooks, test.webhooks) || *port != test.port {
+			t.Errorf("%q: plugins %q, webhooks %q, port %s, want %q, %q, %s", test.args, plugins, webhooks, *port, test.plugins, test.webhooks, test.port)
+		}
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	if runtime.GOOS == "linux" {
+		t.Skip("the platform directory is the XDG one on linux")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)    // macOS
+	t.Setenv("AppData", home) // windows
+	platform, err := os.UserConfigDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := os.MkdirAll(filepath.Join(platform, "go-raph"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if got := configDir(); got != filepath.Join(platform, "go-raph") {
+		t.Errorf("configDir() = %s without the XDG directory, want the existing %s", got, filepath.Join(platform, "go-raph"))
+	}
+	if err := os.Mkdir(filepath.Join(xdg, "go-raph"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := configDir(); got != filepath.Join(xdg, "go-raph") {
+		t.Errorf("configDir() = %s, want the XDG directory", got)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-raph/depgraph"
+)
+
+// validation is one line of the capability report of `go-raph validate`.
+type validation struct {
+	check  string
+	ok     bool
+	needed bool // by the requested features, so failing fails the validation
+	detail string
+}
+
+// validateCommand implements `go-raph validate`, checking that the target
+// can be analyzed with the requested features before a long analysis or in
+// a CI preflight: go.mod parses, files are readable and what the features
+// rely on, the go command, the module cache or services, is there. It
+// prints a capability report and fails when a requested feature cannot
+// work.
+func validateCommand(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	addAnalysisFlags(fs)
+	parseFlags(fs, args)
+
+	goOffline()
+	loadPlugins()
+	resolveTarget(fs)
+
+	fmt.Printf(tr("🔎 Validating: %s\n"), targetPath)
+	failed := false
+	for _, v := range validateTarget(context.Background()) {
+		icon := "✅"
+		if !v.ok && v.needed {
+			icon, failed = "❌", true
+		} else if !v.ok {
+			icon = "⚠️"
+		}
+		fmt.Printf("%s %s: %s\n", icon, v.check, v.detail)
+	}
+	if failed {
+		fmt.Println(tr("❌ The target cannot be analyzed with the requested features"))
+		os.Exit(1)
+	}
+	fmt.Println(tr("✅ Ready to analyze"))
+}
+
+// validateTarget checks the modules of the target, then what the requested
+// features need. The modules are analyzed for imports only, which is quick.
+func validateTarget(ctx context.Context) []validation {
+	var dirs []string
+	if workspaceMode {
+		dirs = workspaceModules(targetPath)
+	}
+	if dirs == nil {
+		dirs = []string{targetPath}
+	}
+	var checks []validation
+	var graphs []*depgraph.Graph
+	for _, dir := range dirs {
+		graph, moduleChecks := validateModule(dir)
+		checks = append(checks, moduleChecks...)
+		if graph != nil {
+			graphs = append(graphs, graph)
+		}
+	}
+	if len(graphs) < len(dirs) {
+		return checks // nothing else matters until the modules can be analyzed
+	}
+	checks = append(checks, validateToolchain(), validateModCache(graphs))
+	return append(checks, validateServices(ctx)...)
+}
+
+// validateModule analyzes the imports of one module, reporting whether its
+// go.mod parses and its files are readable.
+func validateModule(dir string) (*depgraph.Graph, []validation) {
+	// Checks are named after go.mod and the package pattern of the module
+	name, files := "go.mod", "./..."
+	if rel, err := filepath.Rel(targetPath, dir); err == nil && rel != "." {
+		name, files = filepath.Join(rel, "go.mod"), "./"+filepath.ToSlash(rel)+"/..."
+	}
+	graph, err := depgraph.Analyze(os.DirFS(dir), depgraph.Options{Excludes: gitExcludes()})
+	switch {
+	case errors.Is(err, depgraph.ErrNoGoMod):
+		return nil, []validation{{name, false, true, tr("missing, not a Go module")}}
+	case errors.Is(err, depgraph.ErrParse):
+		return nil, []validation{{name, false, true, strings.TrimPrefix(err.Error(), "go.mod: ")}}
+	case err != nil:
+		return nil, []validation{{files, false, true, err.Error()}}
+	}
+
+	packages := 0
+	for _, node := range graph.Nodes {
+		if node.Type == "package" {
+			packages++
+		}
+	}
+	checks := []validation{{name, true, true, fmt.Sprintf(tr("module %s, %d packages"), mainModulePath(graph), packages)}}
+	var warnings []string
+	for _, d := range graph.Diagnostics() {
+		warnings = append(warnings, d.Warnings...)
+	}
+	if len(warnings) > 0 {
+		checks = append(checks, validation{files, false, false,
+			fmt.Sprintf(tr("the graph will be incomplete, %d problems, the first: %s"), len(warnings), warnings[0])})
+	}
+	return graph, checks
+}
+
+// validateToolchain checks the go command -symbols, -tidy and the module
+// graph endpoints run.
+func validateToolchain() validation {
+	needed := len(symbolModules) > 0 || previewTidy
+	if _, err := exec.LookPath("go"); err != nil {
+		return validation{"go", false, needed, tr("not found, -symbols, -tidy and /api/mvs need it")}
+	}
+	return validation{"go", true, needed, goEnv("GOVERSION")}
+}
+
+// validateModCache checks how many of the external modules are in the
+// module cache, where licenses are read from and, with -offline, everything
+// else about modules.
+func validateModCache(graphs []*depgraph.Graph) validation {
+	total, cached := 0, 0
+	for _, graph := range graphs {
+		for i := range graph.Nodes {
+			node := &graph.Nodes[i]
+			if !isExternal(node) || strings.HasPrefix(node.ID, "import:") || node.Version == "" {
+				continue
+			}
+			total++
+			if _, ok := moduleDir(node.ID, node.Version); ok {
+				cached++
+			}
+		}
+	}
+	detail := fmt.Sprintf(tr("%d of %d modules downloaded to %s"), cached, total, modCacheDir())
+	if cached < total {
+		detail += tr(", run `go mod download` for the licenses of the others")
+	}
+	return validation{tr("module cache"), cached == total, offline, detail}
+}
+
+// validateServices checks that the services the requested -releases,
+// -scorecard and -maintainers lookups query answer.
+func validateServices(ctx context.Context) []validation {
+	var checks []validation
+	check := func(feature string, urls ...string) {
+		if offline && !strings.HasPrefix(urls[0], "file://") {
+			checks = append(checks, validation{feature, false, false, tr("skipped with -offline")})
+			return
+		}
+		for _, url := range urls {
+			if err := reachable(ctx, url); err != nil {
+				checks = append(checks, validation{feature, false, true, err.Error()})
+				return
+			}
+		}
+		checks = append(checks, validation{feature, true, true, strings.Join(urls, ", ")})
+	}
+	if trackReleases {
+		if moduleProxy() == "" {
+			checks = append(checks, validation{"-releases", false, true, tr("GOPROXY names no proxy")})
+		} else {
+			check("-releases", moduleProxy())
+		}
+	}
+	if trackScorecard {
+		check("-scorecard", scorecardAPI)
+	}
+	if trackMaintainers {
+		check("-maintainers", depsDevAPI, githubAPI)
+	}
+	return checks
+}
+
+// reachable reports whether a service answers at all, whatever the status.
+func reachable(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
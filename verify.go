@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"go-raph/depgraph"
+)
+
+func init() {
+	depgraph.Register(verifyAnalyzer{})
+}
+
+// verifySums makes the analysis check the modules in the module cache
+// against go.sum, as `go mod verify` does (-verify).
+var verifySums bool
+
+// alertType is the node type of security alerts, linked to the module they
+// concern by an "alerts" edge.
+const alertType = "security-alert"
+
+// verifiedHashes memoizes the hashes of module cache contents by file or
+// directory, along with the stamp of their files they were computed for,
+// so watch mode does not hash every module on each analysis yet hashes a
+// module again once its files change.
+var verifiedHashes sync.Map // name -> stampedHash
+
+type stampedHash struct {
+	stamp, hash string
+}
+
+// verifyAnalyzer hashes the extracted source and go.mod of every external
+// module in the module cache and adds a security-alert node for each that
+// differs from its go.sum line: the cache was corrupted or tampered with.
+type verifyAnalyzer struct{}
+
+func (verifyAnalyzer) Name() string { return "verify" }
+
+func (verifyAnalyzer) Enrich(ctx context.Context, graph *depgraph.Graph) error {
+	if !verifySums || graphFrom != "" {
+		return nil
+	}
+	sums, err := readGoSum(filepath.Join(targetPath, "go.sum"))
+	if err != nil {
+		return err
+	}
+	var alerts []depgraph.Node
+	for _, node := range graph.Nodes {
+		if !isExternal(&node) || strings.HasPrefix(node.ID, "import:") || node.Version == "" {
+			continue
+		}
+		for _, problem := range verifyModule(node.ID, node.Version, sums) {
+			id := "alert:checksum:" + node.ID
+			if len(alerts) > 0 && alerts[len(alerts)-1].ID == id {
+				alerts[len(alerts)-1].Warnings = append(alerts[len(alerts)-1].Warnings, problem)
+				continue
+			}
+			alerts = append(alerts, depgraph.Node{
+				ID:       id,
+				Label:    "checksum mismatch",
+				FullPath: node.ID + "@" + node.Version,
+				Type:     alertType,
+				Warnings: []string{problem},
+			})
+		}
+	}
+	for _, alert := range alerts {
+		log.Printf(tr("🚨 %s does not match go.sum: %s"), alert.FullPath, strings.Join(alert.Warnings, "; "))
+		graph.Nodes = append(graph.Nodes, alert)
+		module := strings.TrimPrefix(alert.ID, "alert:checksum:")
+		graph.Edges = append(graph.Edges, depgraph.Edge{Source: alert.ID, Target: module, Kind: "alerts"})
+		graph.Annotate(module, "checksum", "mismatch")
+	}
+	return nil
+}
+
+// readGoSum maps "path version" and "path version/go.mod" to their h1:
+// hashes in go.sum. A missing go.sum has no hashes to check.
+func readGoSum(name string) (map[string]string, error) {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && strings.HasPrefix(fields[2], "h1:") {
+			sums[fields[0]+" "+fields[1]] = fields[2]
+		}
+	}
+	return sums, scanner.Err()
+}
+
+// verifyModule compares the module cache's copy of a module version with
+// go.sum, returning a description of each mismatch. Versions go.sum has no
+// line for, or the cache does not hold, are not checked.
+func verifyModule(path, version string, sums map[string]string) []string {
+	var problems []string
+	if want, ok := sums[path+" "+version]; ok {
+		if dir, ok := moduleDir(path, version); ok {
+			got, err := cachedHash(dir, func() (string, error) {
+				return dirhash.HashDir(dir, path+"@"+version, dirhash.Hash1)
+			})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("hashing %s: %v", dir, err))
+			} else if got != want {
+				problems = append(problems, fmt.Sprintf("source hashes to %s, go.sum has %s", got, want))
+			}
+		}
+	}
+	if want, ok := sums[path+" "+version+"/go.mod"]; ok {
+		escPath, err1 := module.EscapePath(path)
+		escVersion, err2 := module.EscapeVersion(version)
+		if err1 != nil || err2 != nil {
+			return problems
+		}
+		mod := filepath.Join(modCacheDir(), "cache", "download", filepath.FromSlash(escPath), "@v", escVersion+".mod")
+		if _, err := os.Stat(mod); err != nil {
+			return problems
+		}
+		got, err := cachedHash(mod, func() (string, error) {
+			return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) { return os.Open(mod) })
+		})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("hashing %s: %v", mod, err))
+		} else if got != want {
+			problems = append(problems, fmt.Sprintf("go.mod hashes to %s, go.sum has %s", got, want))
+		}
+	}
+	return problems
+}
+
+// cachedHash returns the memoized hash of a file or directory, computing
+// it with hash the first time and whenever its files changed since.
+func cachedHash(name string, hash func() (string, error)) (string, error) {
+	stamp, err := fileStamp(name)
+	if err != nil {
+		return hash()
+	}
+	if h, ok := verifiedHashes.Load(name); ok && h.(stampedHash).stamp == stamp {
+		return h.(stampedHash).hash, nil
+	}
+	h, err := hash()
+	if err == nil {
+		verifiedHashes.Store(name, stampedHash{stamp, h})
+	}
+	return h, err
+}
+
+// fileStamp fingerprints a file, or the files under a directory, by path,
+// size, mode and modification time, which is much quicker than hashing
+// their contents.
+func fileStamp(name string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(name, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %d %v %d\n", p, info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil)), err
+}
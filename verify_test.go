@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"go-raph/depgraph"
+)
+
+// fakeModule puts a module version with the given files into a module
+// cache of its own for the rest of a test and returns its go.sum lines.
+func fakeModule(t *testing.T, path, version string, files map[string]string) map[string]string {
+	cache := t.TempDir()
+	saved := modCacheDir
+	modCacheDir = func() string { return cache }
+	t.Cleanup(func() { modCacheDir = saved })
+
+	dir := filepath.Join(cache, filepath.FromSlash(path)+"@"+version)
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	download := filepath.Join(cache, "cache", "download", filepath.FromSlash(path), "@v")
+	writeFile(t, filepath.Join(download, version+".mod"), files["go.mod"])
+
+	h, err := dirhash.HashDir(dir, path+"@"+version, dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(download, version+".mod"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]string{path + " " + version: h, path + " " + version + "/go.mod": mod}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyModule(t *testing.T) {
+	files := map[string]string{"go.mod": "module example.com/lib\n", "lib.go": "package lib\n"}
+	tests := []struct {
+		name     string
+		tamper   func(sums map[string]string)
+		problems []string
+	}{
+		{"clean", func(map[string]string) {}, nil},
+		{"source", func(sums map[string]string) { sums["example.com/lib v1.0.0"] = "h1:bad=" }, []string{"source hashes to"}},
+		{"go.mod", func(sums map[string]string) { sums["example.com/lib v1.0.0/go.mod"] = "h1:bad=" }, []string{"go.mod hashes to"}},
+		{"both", func(sums map[string]string) {
+			sums["example.com/lib v1.0.0"] = "h1:bad="
+			sums["example.com/lib v1.0.0/go.mod"] = "h1:bad="
+		}, []string{"source hashes to", "go.mod hashes to"}},
+		{"not in go.sum", func(sums map[string]string) { clear(sums) }, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sums := fakeModule(t, "example.com/lib", "v1.0.0", files)
+			test.tamper(sums)
+			problems := verifyModule("example.com/lib", "v1.0.0", sums)
+			if len(problems) != len(test.problems) {
+				t.Fatalf("verifyModule = %q, want %d problems", problems, len(test.problems))
+			}
+			for i, p := range problems {
+				if !strings.HasPrefix(p, test.problems[i]) {
+					t.Errorf("problem %q, want %s...", p, test.problems[i])
+				}
+			}
+		})
+	}
+	if problems := verifyModule("example.com/missing", "v1.0.0", map[string]string{"example.com/missing v1.0.0": "h1:x="}); problems != nil {
+		t.Errorf("a module missing from the cache: %q", problems)
+	}
+}
+
+func TestVerifyModuleTamperedLater(t *testing.T) {
+	// A module altered after it was verified once must not pass on a memo
+	sums := fakeModule(t, "example.com/later", "v1.0.0", map[string]string{"go.mod": "module example.com/later\n", "lib.go": "package lib\n"})
+	if problems := verifyModule("example.com/later", "v1.0.0", sums); problems != nil {
+		t.Fatalf("clean module: %q", problems)
+	}
+	dir, _ := moduleDir("example.com/later", "v1.0.0")
+	writeFile(t, filepath.Join(dir, "lib.go"), "package lib\n\nfunc init() { steal() }\n")
+	problems := verifyModule("example.com/later", "v1.0.0", sums)
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], "source hashes to") {
+		t.Errorf("tampered module: %q", problems)
+	}
+}
+
+func TestVerifyAnalyzer(t *testing.T) {
+	sums := fakeModule(t, "example.com/lib", "v1.0.0", map[string]string{"go.mod": "module example.com/lib\n"})
+	target := t.TempDir()
+	writeFile(t, filepath.Join(target, "go.sum"), "example.com/lib v1.0.0 h1:bad=\nexample.com/lib v1.0.0/go.mod "+sums["example.com/lib v1.0.0/go.mod"]+"\n")
+	savedTarget, savedVerify := targetPath, verifySums
+	targetPath, verifySums = target, true
+	t.Cleanup(func() { targetPath, verifySums = savedTarget, savedVerify })
+
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{
+		{ID: "example.com/app", Type: "main"},
+		{ID: "example.com/lib", Type: "external", Version: "v1.0.0"},
+	}}
+	if err := (verifyAnalyzer{}).Enrich(context.Background(), graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 3 || graph.Nodes[2].ID != "alert:checksum:example.com/lib" || graph.Nodes[2].Type != alertType {
+		t.Fatalf("nodes = %+v", graph.Nodes)
+	}
+	if len(graph.Edges) != 1 || graph.Edges[0].Source != "alert:checksum:example.com/lib" || graph.Edges[0].Target != "example.com/lib" || graph.Edges[0].Kind != "alerts" {
+		t.Errorf("edges = %+v", graph.Edges)
+	}
+	if graph.Nodes[1].Annotations["checksum"] != "mismatch" {
+		t.Errorf("annotations = %v", graph.Nodes[1].Annotations)
+	}
+}
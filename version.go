@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+// releaseVersion is the release of this binary, set by release builds with
+// -ldflags "-X main.releaseVersion=v1.2.3"; go install builds report their module
+// version instead.
+var releaseVersion = ""
+
+// currentVersion returns the release of this binary, or "" for development
+// builds.
+func currentVersion() string {
+	if releaseVersion != "" {
+		return releaseVersion
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && semver.IsValid(info.Main.Version) {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// buildSetting returns a setting the go command stamped into the binary,
+// such as vcs.revision, or "" when it is missing.
+func buildSetting(key string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, s := range info.Settings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// toolVersion names this binary for provenance: its release, or the
+// revision it was built from for development builds.
+func toolVersion() string {
+	if v := currentVersion(); v != "" {
+		return "go-raph " + v
+	}
+	version := "go-raph (devel)"
+	if revision := buildSetting("vcs.revision"); revision != "" {
+		version += " " + revision[:min(len(revision), 12)]
+		if buildSetting("vcs.modified") == "true" {
+			version += "+dirty"
+		}
+	}
+	return version
+}
+
+// versionCommand implements `go-raph version`.
+func versionCommand(args []string) {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	addLangFlag(fs)
+	parseFlags(fs, args)
+
+	fmt.Println(toolVersion())
+	fmt.Printf("  go:       %s\n", runtime.Version())
+	fmt.Printf("  platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	if revision := buildSetting("vcs.revision"); revision != "" {
+		if buildSetting("vcs.modified") == "true" {
+			revision += tr(" (modified)")
+		}
+		fmt.Printf("  revision: %s\n", revision)
+	}
+	if built := buildSetting("vcs.time"); built != "" {
+		fmt.Printf("  commit:   %s\n", built)
+	}
+}
+
+// analysisFlags are the analysis flags of the command line, registered by
+// addAnalysisFlags, which provenance lists the ones set of.
+var analysisFlags = map[string]*flag.Flag{}
+
+// repeatedValues holds every value given to the analysis flags that are
+// not of a built-in type, which may be repeated, like -plugin, and whose
+// String does not tell their values.
+var repeatedValues = map[string][]string{}
+
+// recordedValue records the values set on a flag in repeatedValues.
+type recordedValue struct {
+	flag.Value
+	name string
+}
+
+func (v recordedValue) Set(s string) error {
+	if err := v.Value.Set(s); err != nil {
+		return err
+	}
+	repeatedValues[v.name] = append(repeatedValues[v.name], s)
+	return nil
+}
+
+func (v recordedValue) String() string {
+	if v.Value == nil { // the zero value flag.PrintDefaults compares defaults with
+		return ""
+	}
+	return v.Value.String()
+}
+
+// recordFlags notes the flags of fs not in before as analysis flags,
+// wrapping those of other than the built-in types so their values are
+// recorded.
+func recordFlags(fs *flag.FlagSet, before map[string]bool) {
+	fs.VisitAll(func(f *flag.Flag) {
+		if before[f.Name] || f.Name == "lang" {
+			return
+		}
+		analysisFlags[f.Name] = f
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			return
+		}
+		if kind, _ := flag.UnquoteUsage(f); kind == "value" {
+			f.Value = recordedValue{f.Value, f.Name}
+		}
+	})
+}
+
+// setAnalysisFlags returns the analysis flags that were set, as
+// -name=value, sorted by name.
+func setAnalysisFlags() []string {
+	flags := []string{}
+	for _, name := range slices.Sorted(maps.Keys(analysisFlags)) {
+		f := analysisFlags[name]
+		if values, ok := repeatedValues[name]; ok {
+			for _, v := range values {
+				flags = append(flags, "-"+name+"="+v)
+			}
+		} else if f.Value.String() != f.DefValue {
+			flags = append(flags, "-"+name+"="+f.Value.String())
+		}
+	}
+	return flags
+}
+
+// stampProvenance records on the graph how it was produced. The module
+// version is what git describes the analyzed checkout as, when it is one.
+func stampProvenance(graph *depgraph.Graph) {
+	moduleVersion, _ := gitOutput(targetPath, "describe", "--tags", "--always", "--dirty")
+	graph.Provenance = &depgraph.Provenance{
+		Tool:          toolVersion(),
+		AnalyzedAt:    time.Now().UTC().Truncate(time.Second),
+		Flags:         setAnalysisFlags(),
+		Module:        graphProject(graph),
+		ModuleVersion: strings.TrimSpace(moduleVersion),
+	}
+}
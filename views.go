@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+
+	"go-raph/depgraph"
+)
+
+// view is a named, shared perspective on a project's graph.
+type view struct {
+	Name      string   `json:"name"`
+	Filter    string   `json:"filter"`              // filter expression, see matchFilter
+	Collapsed []string `json:"collapsed,omitempty"` // modules whose packages are folded in
+	ColorMode string   `json:"colorMode,omitempty"` // dimension nodes are colored by
+}
+
+// views holds saved views per project, persisted like layouts.
+var views = struct {
+	sync.Mutex
+	loaded   bool
+	projects map[string]map[string]view
+}{}
+
+// loadViews reads the views file once; callers hold the lock.
+func loadViews() {
+	if views.loaded {
+		return
+	}
+	views.loaded = true
+	if err := readState("views.json", &views.projects); err != nil {
+		log.Printf(tr("⚠️ Reading %s failed: %v"), "views.json", err)
+	}
+	if views.projects == nil {
+		views.projects = make(map[string]map[string]view)
+	}
+}
+
+func getView(name string) (view, bool) {
+	views.Lock()
+	defer views.Unlock()
+	loadViews()
+	v, ok := views.projects[projectKey()][name]
+	return v, ok
+}
+
+// applyView narrows a graph to what a view shows.
+func applyView(graph *depgraph.Graph, v view) *depgraph.Graph {
+	return filterGraph(collapseModules(graph, v.Collapsed), v.Filter)
+}
+
+// viewsHandler serves /api/views:
+//
+//	GET    /api/views         list the project's views
+//	GET    /api/views/{name}  get one view
+//	PUT    /api/views/{name}  create or replace a view
+//	DELETE /api/views/{name}  delete a view
+func viewsHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	views.Lock()
+	defer views.Unlock()
+	loadViews()
+	key := projectKey()
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		list := []view{}
+		for _, v := range views.projects[key] {
+			list = append(list, v)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		respondJSON(w, list)
+
+	case r.Method == http.MethodGet:
+		v, ok := views.projects[key][name]
+		if !ok {
+			http.Error(w, "view not found", http.StatusNotFound)
+			return
+		}
+		respondJSON(w, v)
+
+	case r.Method == http.MethodPut && name != "":
+		var v view
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			http.Error(w, "invalid view: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		v.Name = name
+		if views.projects[key] == nil {
+			views.projects[key] = make(map[string]view)
+		}
+		views.projects[key][name] = v
+		if err := writeState("views.json", views.projects); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, v)
+
+	case r.Method == http.MethodDelete && name != "":
+		delete(views.projects[key], name)
+		if err := writeState("views.json", views.projects); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"go-raph/depgraph"
+)
+
+// vulnDB is the Go vulnerability database, the same source govulncheck uses,
+// including its GOVULNDB override.
+var vulnDB = cmp.Or(os.Getenv("GOVULNDB"), "https://vuln.go.dev")
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+type vulnerability struct {
+	ID      string   `json:"id"`
+	Summary string   `json:"summary"`
+	Aliases []string `json:"aliases,omitempty"`
+	Fixed   string   `json:"fixed,omitempty"` // first version fixing the module's current version
+}
+
+// osvEntry is the subset of an OSV record go-raph needs.
+type osvEntry struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Affected []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Introduced string `json:"introduced"`
+				Fixed      string `json:"fixed"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+}
+
+// scanVulns looks up known vulnerabilities affecting the required version of
+// every external module in graph, keyed by module path.
+func scanVulns(ctx context.Context, graph *depgraph.Graph) (map[string][]vulnerability, error) {
+	var index []struct {
+		Path  string `json:"path"`
+		Vulns []struct {
+			ID    string `json:"id"`
+			Fixed string `json:"fixed"`
+		} `json:"vulns"`
+	}
+	if err := fetchJSON(ctx, vulnDB+"/index/modules.json", &index); err != nil {
+		return nil, err
+	}
+	candidates := make(map[string][]string)
+	for _, mod := range index {
+		for _, v := range mod.Vulns {
+			candidates[mod.Path] = append(candidates[mod.Path], v.ID)
+		}
+	}
+
+	found := make(map[string][]vulnerability)
+	for _, node := range graph.Nodes {
+		if !isPublic(&node) || node.Version == "" {
+			continue
+		}
+		for _, id := range candidates[node.ID] {
+			var entry osvEntry
+			if err := fetchJSON(ctx, vulnDB+"/ID/"+id+".json", &entry); err != nil {
+				return nil, err
+			}
+			if affected, fixed := entry.affects(node.ID, node.Version); affected {
+				summary := entry.Summary
+				if summary == "" {
+					summary, _, _ = strings.Cut(entry.Details, "\n")
+				}
+				found[node.ID] = append(found[node.ID], vulnerability{
+					ID:      entry.ID,
+					Summary: summary,
+					Aliases: entry.Aliases,
+					Fixed:   fixed,
+				})
+			}
+		}
+	}
+	return found, nil
+}
+
+// affects reports whether version of module falls in one of the entry's
+// ranges, and if so the version that fixes it.
+func (e *osvEntry) affects(module, version string) (bool, string) {
+	for _, aff := range e.Affected {
+		if aff.Package.Name != module {
+			continue
+		}
+		for _, r := range aff.Ranges {
+			if r.Type != "SEMVER" {
+				continue
+			}
+			affected := false
+			for _, ev := range r.Events {
+				if ev.Introduced != "" && (ev.Introduced == "0" || semver.Compare(version, "v"+ev.Introduced) >= 0) {
+					affected = true
+				}
+				if ev.Fixed != "" {
+					if semver.Compare(version, "v"+ev.Fixed) < 0 && affected {
+						return true, "v" + ev.Fixed
+					}
+					affected = false
+				}
+			}
+			if affected {
+				return true, ""
+			}
+		}
+	}
+	return false, ""
+}
+
+func fetchJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
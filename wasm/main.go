@@ -0,0 +1,98 @@
+//go:build js && wasm
+
+// Command wasm exposes the go-raph analyzer to the browser so a project can
+// be analyzed client-side without uploading any code.
+//
+//	GOOS=js GOARCH=wasm go build -o go-raph.wasm ./wasm
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strings"
+	"syscall/js"
+	"testing/fstest"
+
+	"go-raph/depgraph"
+)
+
+func main() {
+	js.Global().Set("goraphAnalyzeZip", js.FuncOf(analyzeZip))
+	js.Global().Set("goraphAnalyzeFiles", js.FuncOf(analyzeFiles))
+	js.Global().Call("dispatchEvent", js.Global().Get("Event").New("goraph-ready"))
+	select {}
+}
+
+// analyzeZip takes a Uint8Array holding a zip archive and returns the graph
+// as a JSON string.
+func analyzeZip(this js.Value, args []js.Value) any {
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return result(nil, err)
+	}
+	return result(analyze(zr, options(args)))
+}
+
+// analyzeFiles takes an object mapping slash-separated paths to file
+// contents, as collected from a dropped folder or the File System Access API.
+func analyzeFiles(this js.Value, args []js.Value) any {
+	fsys := fstest.MapFS{}
+	keys := js.Global().Get("Object").Call("keys", args[0])
+	for i := 0; i < keys.Length(); i++ {
+		name := keys.Index(i).String()
+		fsys[name] = &fstest.MapFile{Data: []byte(args[0].Get(name).String())}
+	}
+	return result(analyze(fsys, options(args)))
+}
+
+func options(args []js.Value) depgraph.Options {
+	var opts depgraph.Options
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		opts.Generics = args[1].Get("generics").Truthy()
+		opts.Embeds = args[1].Get("embeds").Truthy()
+	}
+	return opts
+}
+
+// analyze roots the analysis at the shallowest go.mod, since archives and
+// folders usually wrap the module in a top-level directory, and labels the
+// graph the way the server does by default.
+func analyze(fsys fs.FS, opts depgraph.Options) (*depgraph.Graph, error) {
+	root := "."
+	depth := -1
+	fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && name != "." && depgraph.SkipDir(d.Name()) {
+			return fs.SkipDir
+		}
+		if err == nil && path.Base(name) == "go.mod" {
+			if n := strings.Count(name, "/"); depth < 0 || n < depth {
+				root, depth = path.Dir(name), n
+			}
+		}
+		return nil
+	})
+	sub, err := fs.Sub(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	graph, err := depgraph.Analyze(sub, opts)
+	if err == nil {
+		graph.ApplyLabels(depgraph.DefaultLabelPolicy)
+	}
+	return graph, err
+}
+
+func result(graph *depgraph.Graph, err error) any {
+	if err != nil {
+		data, _ := json.Marshal(map[string]interface{}{"error": err.Error()})
+		return string(data)
+	}
+	data, _ := json.Marshal(map[string]interface{}{"graph": graph})
+	return string(data)
+}
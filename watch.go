@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"go-raph/depgraph"
+)
+
+var (
+	watchDebounce    = 250 * time.Millisecond // quiet period before re-analyzing
+	watchMinInterval = time.Second            // minimum time between re-analyses
+)
+
+// watchProject calls onChange with the directories, slash separated and
+// relative to root, in which analyzed files were written, created, removed or
+// renamed, or that were themselves added or removed. Bursts of changes, like
+// a branch switch, are coalesced into one call. New directories are watched
+// as they appear.
+func watchProject(root string, onChange func(dirs []string)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	changes := make(chan struct{}, 1)
+	var pending struct {
+		sync.Mutex
+		dirs map[string]bool
+	}
+	changed := func(name string) {
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return
+		}
+		pending.Lock()
+		if pending.dirs == nil {
+			pending.dirs = make(map[string]bool)
+		}
+		pending.dirs[filepath.ToSlash(rel)] = true
+		pending.Unlock()
+		select {
+		case changes <- struct{}{}:
+		default: // a change is already pending
+		}
+	}
+	go coalesce(changes, func() {
+		pending.Lock()
+		dirs := make([]string, 0, len(pending.dirs))
+		for dir := range pending.dirs {
+			dirs = append(dirs, dir)
+		}
+		pending.dirs = nil
+		pending.Unlock()
+		onChange(dirs)
+	})
+
+	watched := make(map[string]bool) // directories added to the watcher
+	addTree := func(dir string) {
+		ignore := depgraph.NewIgnore(os.DirFS(root), gitExcludes())
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return filepath.SkipDir
+			}
+			if path != dir && depgraph.SkipDir(d.Name()) || ignore.Ignored(filepath.ToSlash(rel), true) {
+				return filepath.SkipDir
+			}
+			if err := watcher.Add(path); err != nil {
+				log.Printf("⚠️ watch %s: %v", path, err)
+			}
+			watched[path] = true
+			return nil
+		})
+	}
+	addTree(root)
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						addTree(event.Name)
+						changed(event.Name)
+					}
+				}
+				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					if watched[event.Name] {
+						delete(watched, event.Name)
+						changed(event.Name)
+					}
+				}
+				if analyzedFile(event.Name) {
+					changed(filepath.Dir(event.Name))
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("⚠️ watch: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// coalesce runs fn once changes have been quiet for watchDebounce, and no
+// sooner than watchMinInterval after the previous run. Changes arriving
+// while fn runs cause exactly one more run.
+func coalesce(changes chan struct{}, fn func()) {
+	var last time.Time
+	for range changes {
+		quiet := time.NewTimer(watchDebounce)
+	debounce:
+		for {
+			select {
+			case <-changes:
+				quiet.Reset(watchDebounce)
+			case <-quiet.C:
+				break debounce
+			}
+		}
+		time.Sleep(time.Until(last.Add(watchMinInterval)))
+		// Changes made while waiting are covered by this run
+		select {
+		case <-changes:
+		default:
+		}
+		last = time.Now()
+		fn()
+	}
+}
+
+// analyzedFile reports whether changes to a file can change the graph.
+func analyzedFile(name string) bool {
+	base := filepath.Base(name)
+	return strings.HasSuffix(base, ".go") || strings.HasSuffix(base, ".proto") || base == "go.mod" || depgraph.IsDockerfile(base) ||
+		strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml") || base == ".gitignore"
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-raph/depgraph"
+)
+
+// whyCommand implements `go-raph why <dependency>`, printing every import
+// chain from the project's entry packages to a module, import or package
+// along with the files making each import, like a package-granular
+// `go mod why`.
+func whyCommand(args []string) {
+	fs := flag.NewFlagSet("why", flag.ExitOnError)
+	fs.StringVar(&targetPath, "path", defaultTarget, "Path to analyze")
+	format := fs.String("format", "text", "Output format: text, or json for the graph of the chains")
+	output := fs.String("o", "", "Output file (default stdout)")
+	limit := fs.Int("max", 100, "Stop after this many chains (0 for all)")
+	addAnalysisFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: go-raph why [flags] <module, import path or package>")
+		fs.PrintDefaults()
+	}
+	parseFlags(fs, args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	goOffline()
+	loadPlugins()
+	loadSavedGraph()
+	if _, err := os.Stat(targetPath); os.IsNotExist(err) {
+		fmt.Printf(tr("❌ Path '%s' does not exist\n"), targetPath)
+		os.Exit(1)
+	}
+
+	graph, err := currentGraph(context.Background())
+	if err != nil {
+		fmt.Printf(tr("❌ Analysis failed: %v\n"), err)
+		os.Exit(1)
+	}
+	id, ok := resolveNodeID(graph, fs.Arg(0))
+	if !ok {
+		fmt.Printf(tr("❌ '%s' is not imported by %s\n"), fs.Arg(0), targetPath)
+		os.Exit(1)
+	}
+	chains := graph.ImportChains(id, *limit)
+
+	w := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	switch *format {
+	case "json":
+		err = writeJSON(w, graph.ChainGraph(chains))
+	case "text":
+		writeChains(w, graph, id, chains, *limit)
+	default:
+		fmt.Printf(tr("❌ Unknown format '%s'\n"), *format)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// resolveNodeID finds the node a user means by a module path, import path,
+// package path or node ID.
+func resolveNodeID(graph *depgraph.Graph, name string) (string, bool) {
+	mainModule := mainModulePath(graph)
+	candidates := []string{name, "import:" + name, "pkg:" + name}
+	if rel, ok := strings.CutPrefix(name, mainModule+"/"); ok && mainModule != "" {
+		candidates = append(candidates, "pkg:"+rel)
+	}
+	for _, id := range candidates {
+		if graph.Node(id) != nil {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func mainModulePath(graph *depgraph.Graph) string {
+	for _, node := range graph.Nodes {
+		if node.Type == "main" {
+			return node.ID
+		}
+	}
+	return ""
+}
+
+// writeChains prints each chain one node per line, with the files of
+// packages that import the next node.
+func writeChains(w *os.File, graph *depgraph.Graph, target string, chains [][]string, limit int) {
+	if len(chains) == 0 {
+		fmt.Fprintf(w, "%s is not reachable from any entry package\n", target)
+		return
+	}
+	mainModule := mainModulePath(graph)
+	for i, chain := range chains {
+		fmt.Fprintf(w, "# chain %d\n", i+1)
+		for j, id := range chain {
+			fmt.Fprintln(w, id)
+			if j+1 < len(chain) && strings.HasPrefix(id, "pkg:") {
+				importPath := nodeImportPath(mainModule, chain[j+1])
+				for _, file := range importingFiles(id, importPath) {
+					fmt.Fprintf(w, "    %s imports %s\n", file, importPath)
+				}
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	if limit > 0 && len(chains) == limit {
+		fmt.Fprintf(w, "stopped after %d chains, raise -max to see more\n", limit)
+	}
+}
+
+// nodeImportPath returns the import path a package uses to import a node.
+func nodeImportPath(mainModule, id string) string {
+	if rel, ok := strings.CutPrefix(id, "pkg:"); ok {
+		return mainModule + "/" + rel
+	}
+	return strings.TrimPrefix(id, "import:")
+}
+
+// importingFiles returns the Go files of a package node that import
+// importPath, relative to the target.
+func importingFiles(packageID, importPath string) []string {
+	rel, _ := depgraph.PackageDir(packageID)
+	matches, _ := filepath.Glob(filepath.Join(targetPath, filepath.FromSlash(rel), "*.go"))
+	var files []string
+	for _, name := range matches {
+		file, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range file.Imports {
+			if p, _ := strconv.Unquote(imp.Path.Value); p == importPath {
+				relName, _ := filepath.Rel(targetPath, name)
+				files = append(files, filepath.ToSlash(relName))
+				break
+			}
+		}
+	}
+	return files
+}
+
+// maxWhyChains bounds ?max= of /api/why: there can be exponentially many
+// chains, and listing them all would keep the server busy for as long.
+const maxWhyChains = 1000
+
+// whyHandler serves the graph of the import chains leading to ?target=, a
+// module, import path, package or node ID, stopping after ?max= chains
+// (100, at most maxWhyChains). The /api/graph parameters apply as well. A
+// cut short graph comes with an X-Chains-Truncated header.
+func whyHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if s := r.URL.Query().Get("max"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			http.Error(w, "max must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxWhyChains)
+	}
+	graph, ok := requestedGraph(w, r)
+	if !ok {
+		return
+	}
+	id, ok := resolveNodeID(graph, r.URL.Query().Get("target"))
+	if !ok {
+		http.Error(w, "target not found", http.StatusNotFound)
+		return
+	}
+	chains := graph.ImportChains(id, limit)
+	if len(chains) == limit {
+		w.Header().Set("X-Chains-Truncated", fmt.Sprintf("stopped after %d chains, raise max to see more", limit))
+	}
+	respondJSON(w, graph.ChainGraph(chains))
+}
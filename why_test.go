@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"go-raph/depgraph"
+)
+
+// diamondGraph returns a chain of layers diamonds, with 2^layers import
+// chains from pkg:e to x.org/y.
+func diamondGraph(layers int) *depgraph.Graph {
+	graph := &depgraph.Graph{Nodes: []depgraph.Node{{ID: "pkg:e", Type: "package"}, {ID: "x.org/y", Type: "external"}}}
+	prev := []string{"pkg:e"}
+	for layer := 0; layer < layers; layer++ {
+		next := []string{fmt.Sprintf("pkg:%d/a", layer), fmt.Sprintf("pkg:%d/b", layer)}
+		for _, id := range next {
+			graph.Nodes = append(graph.Nodes, depgraph.Node{ID: id, Type: "package"})
+			for _, p := range prev {
+				graph.Edges = append(graph.Edges, depgraph.Edge{Source: p, Target: id})
+			}
+		}
+		prev = next
+	}
+	for _, p := range prev {
+		graph.Edges = append(graph.Edges, depgraph.Edge{Source: p, Target: "x.org/y"})
+	}
+	return graph
+}
+
+func TestWhyHandler(t *testing.T) {
+	setSnapshot(diamondGraph(40)) // far more chains than could ever be listed
+	t.Cleanup(func() { setSnapshot(nil) })
+	tests := []struct {
+		query     string
+		status    int
+		truncated string
+	}{
+		{"target=x.org/y", 200, "stopped after 100 chains, raise max to see more"},
+		{"target=x.org/y&max=5", 200, "stopped after 5 chains, raise max to see more"},
+		{"target=x.org/y&max=1000000", 200, "stopped after 1000 chains, raise max to see more"},
+		{"target=pkg:0/a", 200, ""}, // the only chain
+		{"target=x.org/y&max=0", 400, ""},
+		{"target=x.org/y&max=all", 400, ""},
+		{"target=x.org/z", 404, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		whyHandler(w, httptest.NewRequest("GET", "/api/why?"+test.query, nil))
+		if w.Code != test.status || w.Header().Get("X-Chains-Truncated") != test.truncated {
+			t.Errorf("%s: status %d, truncated %q, want %d, %q", test.query, w.Code, w.Header().Get("X-Chains-Truncated"), test.status, test.truncated)
+		}
+	}
+}
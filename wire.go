@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// msgpackProtocol is the WebSocket subprotocol a client requests to receive
+// MessagePack binary frames instead of JSON text frames. Messages from the
+// client are always JSON.
+const msgpackProtocol = "goraph.msgpack"
+
+// encodeMsgpack encodes v with the same field names and omissions as its
+// JSON encoding, so clients see identical objects in either format.
+func encodeMsgpack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true) // whole-number coordinates shrink to ints
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}